@@ -0,0 +1,188 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package anidbtest provides a fake AniDB HTTP API and titles dump
+// server for testing.
+package anidbtest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.felesatra.moe/anidb"
+)
+
+// A Handler is an http.Handler serving a fake AniDB HTTP API and
+// titles dump.
+//
+// The HTTP API is served at /httpapi and the titles dump at
+// /anime-titles.{xml,dat,json}.gz.
+// The methods can be called concurrently.
+type Handler struct {
+	mu        sync.Mutex
+	anime     map[int][]byte
+	responses map[string][]byte
+	err       string
+	titles    map[string][]byte
+}
+
+// NewHandler returns a new Handler without any data.
+func NewHandler() *Handler {
+	return &Handler{
+		anime:     make(map[int][]byte),
+		responses: make(map[string][]byte),
+		titles:    make(map[string][]byte),
+	}
+}
+
+// AddAnime sets the XML returned for an anime request.
+func (h *Handler) AddAnime(aid int, xml []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.anime[aid] = xml
+}
+
+// SetResponse sets the XML returned for an HTTP API request type
+// other than anime, such as "hotanime".
+func (h *Handler) SetResponse(request string, xml []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.responses[request] = xml
+}
+
+// SetError makes all HTTP API requests return an error with the
+// given message, such as "Banned".
+// Set to an empty string to clear.
+func (h *Handler) SetError(msg string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.err = msg
+}
+
+// SetTitles sets the uncompressed titles dump for a format.
+// The dump is served gzipped.
+func (h *Handler) SetTitles(f anidb.TitlesFormat, data []byte) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, _ = w.Write(data)
+	_ = w.Close()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.titles[f.String()] = buf.Bytes()
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/httpapi":
+		h.serveAPI(w, r)
+	case strings.HasPrefix(r.URL.Path, "/anime-titles."):
+		h.serveTitles(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (h *Handler) serveAPI(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	q := r.URL.Query()
+	if h.err != "" {
+		writeError(w, h.err)
+		return
+	}
+	if q.Get("client") == "" || q.Get("clientver") == "" || q.Get("protover") == "" {
+		writeError(w, "Client Values Missing or Invalid")
+		return
+	}
+	req := q.Get("request")
+	if req != "anime" {
+		d, ok := h.responses[req]
+		if !ok {
+			writeError(w, "Unknown Request")
+			return
+		}
+		writeXML(w, d)
+		return
+	}
+	aid, err := strconv.Atoi(q.Get("aid"))
+	if err != nil {
+		writeError(w, "Invalid aid")
+		return
+	}
+	d, ok := h.anime[aid]
+	if !ok {
+		writeError(w, "Anime not found")
+		return
+	}
+	writeXML(w, d)
+}
+
+func (h *Handler) serveTitles(w http.ResponseWriter, r *http.Request) {
+	f := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/anime-titles."), ".gz")
+	h.mu.Lock()
+	d, ok := h.titles[f]
+	h.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/gzip")
+	_, _ = w.Write(d)
+}
+
+func writeXML(w http.ResponseWriter, d []byte) {
+	w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+	_, _ = w.Write(d)
+}
+
+func writeError(w http.ResponseWriter, msg string) {
+	var buf bytes.Buffer
+	buf.WriteString("<error>")
+	_ = xml.EscapeText(&buf, []byte(msg))
+	buf.WriteString("</error>")
+	writeXML(w, buf.Bytes())
+}
+
+// A Server is an httptest.Server running a [Handler].
+type Server struct {
+	*httptest.Server
+	*Handler
+}
+
+// NewServer starts a new Server.
+// You must call Close after use.
+func NewServer() *Server {
+	h := NewHandler()
+	return &Server{
+		Server:  httptest.NewServer(h),
+		Handler: h,
+	}
+}
+
+// Client returns a new anidb.Client configured to use the server.
+func (s *Server) Client() *anidb.Client {
+	return &anidb.Client{
+		Name:          "anidbtest",
+		Version:       1,
+		HTTPClient:    s.Server.Client(),
+		BaseURL:       s.URL + "/httpapi",
+		TitlesBaseURL: s.URL + "/anime-titles",
+	}
+}
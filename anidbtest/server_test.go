@@ -0,0 +1,80 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package anidbtest
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"go.felesatra.moe/anidb"
+)
+
+func TestServer_anime(t *testing.T) {
+	t.Parallel()
+	s := NewServer()
+	t.Cleanup(s.Close)
+	d, err := os.ReadFile("../testdata/anime.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.AddAnime(22, d)
+	c := s.Client()
+	a, err := c.RequestAnimeContext(context.Background(), 22)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.AID != 22 {
+		t.Errorf("Got AID %d; want 22", a.AID)
+	}
+	if _, err := c.RequestAnimeContext(context.Background(), 1); err == nil {
+		t.Errorf("Expected error for missing anime")
+	}
+}
+
+func TestServer_error(t *testing.T) {
+	t.Parallel()
+	s := NewServer()
+	t.Cleanup(s.Close)
+	s.SetError("Banned")
+	_, err := s.Client().RequestAnimeContext(context.Background(), 22)
+	if err == nil || !strings.Contains(err.Error(), "Banned") {
+		t.Errorf("Got error %v; want Banned", err)
+	}
+}
+
+func TestServer_titles(t *testing.T) {
+	t.Parallel()
+	s := NewServer()
+	t.Cleanup(s.Close)
+	for _, f := range []anidb.TitlesFormat{anidb.TitlesXML, anidb.TitlesDat, anidb.TitlesJSON} {
+		f := f
+		t.Run(f.String(), func(t *testing.T) {
+			d, err := os.ReadFile("../testdata/titles." + f.String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			s.SetTitles(f, d)
+			got, err := s.Client().RequestTitles(context.Background(), f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) == 0 {
+				t.Errorf("Got no titles")
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package anidb
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// DefaultAnimeTTL is the default TTL for [AnimeCache].
+// AniDB asks that the same data not be requested more than once per
+// day.
+const DefaultAnimeTTL = 24 * time.Hour
+
+// An AnimeCache is an on-disk cache for anime data from the HTTP API,
+// keyed by aid.
+// The original XML is cached, so fields not decoded by [Anime] are
+// kept.
+//
+// See [Client.AnimeCache] for using the cache transparently.
+type AnimeCache struct {
+	// Dir is the directory for cache files.
+	Dir string
+	// TTL is how long cached anime are fresh.
+	// If zero, [DefaultAnimeTTL] is used.
+	TTL time.Duration
+}
+
+// DefaultAnimeCache returns an AnimeCache at a default location,
+// in [CacheDir].
+func DefaultAnimeCache() *AnimeCache {
+	return &AnimeCache{Dir: filepath.Join(cacheDir(), "anime")}
+}
+
+// Get gets anime from the cache.
+// ok is false if the anime is not cached or the cached anime is
+// stale.
+func (c *AnimeCache) Get(aid int) (a *Anime, raw []byte, ok bool, _ error) {
+	d, ok, err := c.getRaw(aid)
+	if err != nil || !ok {
+		return nil, nil, false, err
+	}
+	a, err = decodeAnime(d)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("anime cache get %d: %s", aid, err)
+	}
+	return a, d, true, nil
+}
+
+// getRaw returns the cached XML for an anime, without decoding it.
+func (c *AnimeCache) getRaw(aid int) ([]byte, bool, error) {
+	d, ok, err := readCacheFile(c.path(aid), c.ttl())
+	if err != nil {
+		return nil, false, fmt.Errorf("anime cache get %d: %s", aid, err)
+	}
+	return d, ok, nil
+}
+
+// Put stores the XML for an anime in the cache.
+func (c *AnimeCache) Put(aid int, raw []byte) error {
+	if err := writeCacheFile(c.Dir, c.path(aid), raw); err != nil {
+		return fmt.Errorf("anime cache put %d: %s", aid, err)
+	}
+	return nil
+}
+
+// writeCacheFile writes a cache file in dir.
+// The file is written to a temporary file first so readers never see
+// a partial file.
+func writeCacheFile(dir, path string, d []byte) error {
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, "tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(d); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// readCacheFile reads a cache file if it is fresh.
+func readCacheFile(path string, ttl time.Duration) ([]byte, bool, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	if time.Since(fi.ModTime()) > ttl {
+		return nil, false, nil
+	}
+	d, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false, err
+	}
+	return d, true, nil
+}
+
+func (c *AnimeCache) path(aid int) string {
+	return filepath.Join(c.Dir, strconv.Itoa(aid)+".xml")
+}
+
+func (c *AnimeCache) ttl() time.Duration {
+	if c.TTL > 0 {
+		return c.TTL
+	}
+	return DefaultAnimeTTL
+}
@@ -0,0 +1,80 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package anidb
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAnimeCache(t *testing.T) {
+	t.Parallel()
+	c := &AnimeCache{Dir: filepath.Join(t.TempDir(), "anime"), TTL: time.Hour}
+	if _, _, ok, err := c.Get(1); err != nil || ok {
+		t.Fatalf("Got ok %v, error %v; want not ok", ok, err)
+	}
+	const raw = `<anime id="1"></anime>`
+	if err := c.Put(1, []byte(raw)); err != nil {
+		t.Fatal(err)
+	}
+	a, d, ok, err := c.Get(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok || a.AID != 1 || string(d) != raw {
+		t.Errorf("Got %v, %q, %v; want cached anime", a, d, ok)
+	}
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(c.path(1), old, old); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok, err := c.Get(1); err != nil || ok {
+		t.Errorf("Got ok %v, error %v for stale anime; want not ok", ok, err)
+	}
+}
+
+func TestClient_AnimeCache(t *testing.T) {
+	t.Parallel()
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprintf(w, `<anime id="%s"></anime>`, r.URL.Query().Get("aid"))
+	}))
+	t.Cleanup(srv.Close)
+	c := Client{
+		Name:       "test",
+		Version:    1,
+		BaseURL:    srv.URL,
+		AnimeCache: &AnimeCache{Dir: t.TempDir()},
+	}
+	for i := 0; i < 2; i++ {
+		a, err := c.RequestAnimeContext(context.Background(), 22)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a.AID != 22 {
+			t.Errorf("Got aid %d; want 22", a.AID)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("Got %d requests; want 1", requests)
+	}
+}
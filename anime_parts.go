@@ -0,0 +1,116 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package anidb
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+)
+
+// An AnimeParts selects optional parts of an [Anime] to decode.
+// The other fields are always decoded.
+//
+// The HTTP API always returns the full anime, so this only saves
+// decoding time and memory for callers that need a subset, such as
+// only the episode list.
+type AnimeParts uint
+
+const (
+	// AnimeEpisodes selects [Anime.Episodes].
+	AnimeEpisodes AnimeParts = 1 << iota
+	// AnimeRelations selects [Anime.RelatedAnime],
+	// [Anime.SimilarAnime] and [Anime.Recommendations].
+	AnimeRelations
+	// AnimeCreators selects [Anime.Creators].
+	AnimeCreators
+	// AnimeResources selects [Anime.Resources].
+	AnimeResources
+	// AnimeTags selects [Anime.Tags].
+	AnimeTags
+	// AnimeCharacters selects [Anime.Characters].
+	AnimeCharacters
+
+	// AllAnimeParts selects all parts.
+	AllAnimeParts = AnimeEpisodes | AnimeRelations | AnimeCreators |
+		AnimeResources | AnimeTags | AnimeCharacters
+)
+
+// animePartElements maps the child elements of an anime to the parts
+// they belong to.
+var animePartElements = map[string]AnimeParts{
+	"episodes":        AnimeEpisodes,
+	"relatedanime":    AnimeRelations,
+	"similaranime":    AnimeRelations,
+	"recommendations": AnimeRelations,
+	"creators":        AnimeCreators,
+	"resources":       AnimeResources,
+	"tags":            AnimeTags,
+	"characters":      AnimeCharacters,
+}
+
+// DecodeAnimeParts decodes anime XML returned by the HTTP API,
+// decoding only the selected optional parts.
+// The elements of the other parts are skipped.
+func DecodeAnimeParts(d []byte, parts AnimeParts) (*Anime, error) {
+	if parts&AllAnimeParts == AllAnimeParts {
+		return decodeAnime(d)
+	}
+	dec := xml.NewDecoder(bytes.NewReader(d))
+	var a Anime
+	if err := xml.NewTokenDecoder(&partsFilter{dec: dec, parts: parts}).Decode(&a); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// A partsFilter is an [xml.TokenReader] that drops the child elements
+// of the root element for unselected anime parts.
+type partsFilter struct {
+	dec   *xml.Decoder
+	parts AnimeParts
+	depth int
+}
+
+func (f *partsFilter) Token() (xml.Token, error) {
+	for {
+		t, err := f.dec.Token()
+		if err != nil {
+			return t, err
+		}
+		switch t := t.(type) {
+		case xml.StartElement:
+			if f.depth == 1 && f.skip(t.Name.Local) {
+				if err := f.dec.Skip(); err != nil {
+					if err == io.EOF {
+						err = io.ErrUnexpectedEOF
+					}
+					return nil, err
+				}
+				continue
+			}
+			f.depth++
+		case xml.EndElement:
+			f.depth--
+		}
+		return t, nil
+	}
+}
+
+// skip returns whether to skip a child element of the root.
+func (f *partsFilter) skip(name string) bool {
+	p, ok := animePartElements[name]
+	return ok && f.parts&p == 0
+}
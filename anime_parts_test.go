@@ -0,0 +1,148 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package anidb
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeAnimeParts(t *testing.T) {
+	t.Parallel()
+	d, err := os.ReadFile("testdata/anime.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	full, err := decodeAnime(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := DecodeAnimeParts(d, AnimeEpisodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := *full
+	want.RelatedAnime = nil
+	want.SimilarAnime = nil
+	want.Recommendations = nil
+	want.Creators = nil
+	want.Resources = nil
+	want.Tags = nil
+	want.Characters = nil
+	if !reflect.DeepEqual(*got, want) {
+		t.Errorf("Got %#v; want %#v", *got, want)
+	}
+	if len(got.Episodes) == 0 {
+		t.Errorf("Got no episodes")
+	}
+}
+
+func TestDecodeAnimeParts_all(t *testing.T) {
+	t.Parallel()
+	d, err := os.ReadFile("testdata/anime.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := decodeAnime(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := DecodeAnimeParts(d, AllAnimeParts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %#v; want %#v", got, want)
+	}
+}
+
+func TestDecodeAnimeParts_truncated(t *testing.T) {
+	t.Parallel()
+	d, err := os.ReadFile("testdata/anime.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := bytes.Index(d, []byte("<characters>"))
+	if _, err := DecodeAnimeParts(d[:i+20], AnimeEpisodes); err == nil {
+		t.Errorf("Expected error for truncated XML")
+	}
+}
+
+func TestRequestAnimeParts(t *testing.T) {
+	t.Parallel()
+	d, err := os.ReadFile("testdata/anime.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(d)
+	}))
+	t.Cleanup(srv.Close)
+	c := Client{Name: "test", Version: 1, BaseURL: srv.URL}
+	a, err := c.RequestAnimeParts(context.Background(), 22, AnimeTags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a.Tags) == 0 {
+		t.Errorf("Got no tags")
+	}
+	if a.Episodes != nil || a.Characters != nil {
+		t.Errorf("Got unselected parts %#v", a)
+	}
+}
+
+// largeAnimeXML returns anime XML with many characters and tags, like
+// popular anime.
+func largeAnimeXML(b *testing.B) []byte {
+	b.Helper()
+	d, err := os.ReadFile("testdata/anime.xml")
+	if err != nil {
+		b.Fatal(err)
+	}
+	var chars, tags bytes.Buffer
+	for i := 0; i < 200; i++ {
+		fmt.Fprintf(&chars, `<character id="%d" type="secondary cast in" update="2020-01-01"><rating votes="10">8.00</rating><name>Character %d</name><gender>female</gender><charactertype id="1">Character</charactertype><description>A character.</description><picture>%d.jpg</picture><seiyuu id="%d" picture="%d.jpg">Seiyuu %d</seiyuu></character>`, i, i, i, i, i, i)
+		fmt.Fprintf(&tags, `<tag id="%d" parentid="1" weight="300" localspoiler="false" globalspoiler="false" verified="true" update="2020-01-01"><name>tag %d</name><description>A tag.</description></tag>`, i, i)
+	}
+	d = bytes.Replace(d, []byte("<characters>"), append([]byte("<characters>"), chars.Bytes()...), 1)
+	d = bytes.Replace(d, []byte("<tags>"), append([]byte("<tags>"), tags.Bytes()...), 1)
+	return d
+}
+
+func BenchmarkDecodeAnimeParts(b *testing.B) {
+	d := largeAnimeXML(b)
+	for _, c := range []struct {
+		name  string
+		parts AnimeParts
+	}{
+		{"all", AllAnimeParts},
+		{"episodes", AnimeEpisodes},
+	} {
+		b.Run(c.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := DecodeAnimeParts(d, c.parts); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package anidb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// A DailyBudget is a [Limiter] that limits the number of requests per
+// UTC day, as AniDB bans clients that make too many requests in a
+// day.
+// The count can be persisted to a file, so it survives restarts.
+// DailyBudget implements [go.felesatra.moe/anidb/udpapi.Limiter] too.
+//
+// Requests beyond the budget fail with a [*BudgetError].
+//
+// The methods can be called concurrently.
+type DailyBudget struct {
+	// Path is the file for persisting the count.
+	// If empty, the count is not persisted.
+	Path string
+	// Limit is the maximum number of requests per UTC day.
+	Limit int
+	// Limiter is an optional limiter to wait on after taking a
+	// request from the budget, such as a [RateLimiter].
+	Limiter Limiter
+
+	mu     sync.Mutex
+	loaded bool
+	state  budgetState
+	// now is for testing.
+	now func() time.Time
+}
+
+// budgetState is the persisted state of a DailyBudget.
+type budgetState struct {
+	// Day is the UTC day, formatted as YYYY-MM-DD.
+	Day   string `json:"day"`
+	Count int    `json:"count"`
+}
+
+// A BudgetError is returned for requests beyond the budget of a
+// [DailyBudget].
+// BudgetError is errors.Is with [ErrDailyLimit].
+type BudgetError struct {
+	// RetryAfter is when the budget resets.
+	RetryAfter time.Time
+}
+
+func (e *BudgetError) Error() string {
+	return fmt.Sprintf("daily request budget exhausted, retry after %s", e.RetryAfter.Format(time.RFC3339))
+}
+
+func (e *BudgetError) Is(target error) bool {
+	return target == ErrDailyLimit
+}
+
+// Wait takes a request from the budget, then waits on
+// [DailyBudget.Limiter] if set.
+// Returns a [*BudgetError] if the budget is exhausted.
+func (b *DailyBudget) Wait(ctx context.Context) error {
+	if err := b.take(); err != nil {
+		return err
+	}
+	if b.Limiter != nil {
+		return b.Limiter.Wait(ctx)
+	}
+	return nil
+}
+
+// Remaining returns the number of requests remaining today.
+func (b *DailyBudget) Remaining() (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.load(); err != nil {
+		return 0, err
+	}
+	b.roll()
+	return max(b.Limit-b.state.Count, 0), nil
+}
+
+func (b *DailyBudget) take() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.load(); err != nil {
+		return err
+	}
+	day := b.roll()
+	if b.state.Count >= b.Limit {
+		return &BudgetError{RetryAfter: day.AddDate(0, 0, 1)}
+	}
+	b.state.Count++
+	return b.save()
+}
+
+// roll resets the count if the day has changed.
+// Returns the start of the current day.
+func (b *DailyBudget) roll() time.Time {
+	now := time.Now
+	if b.now != nil {
+		now = b.now
+	}
+	t := now().UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if d := day.Format(time.DateOnly); d != b.state.Day {
+		b.state = budgetState{Day: d}
+	}
+	return day
+}
+
+// load loads the persisted state once.
+func (b *DailyBudget) load() error {
+	if b.loaded || b.Path == "" {
+		return nil
+	}
+	d, err := os.ReadFile(b.Path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("daily budget load: %s", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(d, &b.state); err != nil {
+			return fmt.Errorf("daily budget load: %s", err)
+		}
+	}
+	b.loaded = true
+	return nil
+}
+
+func (b *DailyBudget) save() error {
+	if b.Path == "" {
+		return nil
+	}
+	d, err := json.Marshal(b.state)
+	if err != nil {
+		return fmt.Errorf("daily budget save: %s", err)
+	}
+	if err := writeCacheFile(filepath.Dir(b.Path), b.Path, d); err != nil {
+		return fmt.Errorf("daily budget save: %s", err)
+	}
+	return nil
+}
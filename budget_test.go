@@ -0,0 +1,77 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package anidb
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDailyBudget(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	now := time.Date(2026, 1, 2, 23, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), "budget.json")
+	b := &DailyBudget{Path: path, Limit: 2, now: func() time.Time { return now }}
+	for i := 0; i < 2; i++ {
+		if err := b.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := b.Wait(ctx)
+	var be *BudgetError
+	if !errors.As(err, &be) {
+		t.Fatalf("Got error %v; want BudgetError", err)
+	}
+	if want := time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC); !be.RetryAfter.Equal(want) {
+		t.Errorf("Got RetryAfter %s; want %s", be.RetryAfter, want)
+	}
+	if !errors.Is(err, ErrDailyLimit) {
+		t.Errorf("Got error %v; want errors.Is %v", err, ErrDailyLimit)
+	}
+
+	// The count is persisted.
+	b2 := &DailyBudget{Path: path, Limit: 2, now: func() time.Time { return now }}
+	if n, err := b2.Remaining(); err != nil || n != 0 {
+		t.Errorf("Got remaining %d, error %v; want 0", n, err)
+	}
+
+	// The budget resets at UTC midnight.
+	now = now.Add(2 * time.Hour)
+	if err := b2.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := b2.Remaining(); err != nil || n != 1 {
+		t.Errorf("Got remaining %d, error %v; want 1", n, err)
+	}
+}
+
+func TestDailyBudget_Limiter(t *testing.T) {
+	t.Parallel()
+	var l countLimiter
+	b := &DailyBudget{Limit: 1, Limiter: &l}
+	if err := b.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Wait(context.Background()); err == nil {
+		t.Errorf("Expected error")
+	}
+	if got := l.count(); got != 1 {
+		t.Errorf("Got %d limiter waits; want 1", got)
+	}
+}
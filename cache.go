@@ -15,10 +15,13 @@
 package anidb
 
 import (
-	"encoding/gob"
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // A TitlesCache is a cache for AniDB titles data.
@@ -30,33 +33,71 @@ type TitlesCache struct {
 	// Updated indicates if the cached titles were updated.
 	// This is set to true when any method updates the cache.
 	Updated bool
+	// ETag and LastModified are the HTTP cache validators for the
+	// cached titles, used to avoid downloading unchanged titles.
+	ETag         string
+	LastModified string
+	// LoadError is set if the stored titles were corrupt when the
+	// cache was opened, in which case the cache is opened empty.
+	// Saving the cache replaces the corrupt titles.
+	LoadError error
+	// Store is the storage backend for the cache.
+	// If nil, the titles are stored in a gob file at Path.
+	Store CacheStore
+	// Logger is used for logging cache activity.
+	// If nil, nothing is logged.
+	Logger *slog.Logger
 }
 
 // DefaultTitlesCache opens a TitlesCache at a default location,
-// using XDG_CACHE_DIR.
+// in [CacheDir].
 func DefaultTitlesCache() (*TitlesCache, error) {
 	return OpenTitlesCache(defaultTitlesCacheFile())
 }
 
 // OpenTitlesCache opens a TitlesCache.
+// If the cache file is corrupt, an empty cache is returned with
+// LoadError set.
 func OpenTitlesCache(path string) (*TitlesCache, error) {
-	f, err := os.Open(path)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return &TitlesCache{Path: path}, nil
-		}
+	c := &TitlesCache{Path: path}
+	if err := c.load(); err != nil {
 		return nil, fmt.Errorf("open titles cache: %s", err)
 	}
-	defer f.Close()
-	c := &TitlesCache{
-		Path: path,
-	}
-	if err := gob.NewDecoder(f).Decode(&c.Titles); err != nil {
-		return nil, fmt.Errorf("open titles cache %s: %s", path, err)
+	return c, nil
+}
+
+// OpenTitlesCacheStore opens a TitlesCache using the given storage
+// backend.
+func OpenTitlesCacheStore(s CacheStore) (*TitlesCache, error) {
+	c := &TitlesCache{Store: s}
+	if err := c.load(); err != nil {
+		return nil, fmt.Errorf("open titles cache: %s", err)
 	}
 	return c, nil
 }
 
+func (c *TitlesCache) store() CacheStore {
+	if c.Store != nil {
+		return c.Store
+	}
+	return TitlesFileStore{Path: c.Path}
+}
+
+func (c *TitlesCache) load() error {
+	d, err := c.store().Load(context.Background())
+	if errors.Is(err, ErrCorruptCache) {
+		c.LoadError = err
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	c.Titles = d.Titles
+	c.ETag = d.ETag
+	c.LastModified = d.LastModified
+	return nil
+}
+
 // GetTitles gets titles from the cache.
 // If the cache has not been populated yet, downloads titles from AniDB.
 func (c *TitlesCache) GetTitles() ([]AnimeT, error) {
@@ -67,40 +108,94 @@ func (c *TitlesCache) GetTitles() ([]AnimeT, error) {
 }
 
 // GetFreshTitles downloads titles from AniDB and stores it in the cache.
+// If the cache has titles, the download is conditional, and the
+// cached titles are returned if they have not been modified.
 // See AniDB API documentation about rate limits.
 func (c *TitlesCache) GetFreshTitles() ([]AnimeT, error) {
-	t, err := RequestTitles()
+	var v titlesValidators
+	if len(c.Titles) > 0 {
+		v = titlesValidators{ETag: c.ETag, LastModified: c.LastModified}
+	}
+	var client Client
+	d, v, err := client.downloadTitles(context.Background(), TitlesXML, v)
+	if errors.Is(err, errNotModified) {
+		c.log(slog.LevelDebug, "Cached titles not modified", "etag", c.ETag)
+		return c.Titles, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("anidb request titles: %s", err)
+	}
+	t, err := DecodeTitles(d)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("anidb request titles: %s", err)
 	}
 	c.Titles = t
+	c.ETag = v.ETag
+	c.LastModified = v.LastModified
 	c.Updated = true
+	c.log(slog.LevelInfo, "Downloaded titles", "count", len(t), "etag", v.ETag)
 	return t, nil
 }
 
-// Save saves the cached titles to the cache file.
-// This method sets Updated to false if successful.
-// See also the SaveIfUpdated method, which is probably more useful.
-func (c *TitlesCache) Save() error {
-	if err := os.MkdirAll(filepath.Dir(c.Path), 0777); err != nil {
-		return fmt.Errorf("save titles cache: %s", err)
+// FindByExactTitle returns the cached anime with a title matching
+// the given title exactly, ignoring case and whitespace differences.
+// If langs are given, only titles in those languages are matched.
+// This does not download titles if the cache is empty.
+//
+// This builds a new [TitleIndex] each call; for many lookups, keep
+// the index returned by [TitlesCache.Index] instead.
+func (c *TitlesCache) FindByExactTitle(title string, langs ...string) []AnimeT {
+	aids := c.Index().Fold(title, TitleFilter{Langs: langs})
+	if len(aids) == 0 {
+		return nil
 	}
-	f, err := os.Create(c.Path)
-	if err != nil {
-		return fmt.Errorf("save titles cache: %s", err)
+	match := make(map[int]bool, len(aids))
+	for _, aid := range aids {
+		match[aid] = true
 	}
-	defer f.Close()
-	if err := gob.NewEncoder(f).Encode(c.Titles); err != nil {
-		return fmt.Errorf("save titles cache %s: %s", c.Path, err)
+	var found []AnimeT
+	for _, a := range c.Titles {
+		if match[a.AID] {
+			found = append(found, a)
+		}
 	}
-	if err := f.Close(); err != nil {
+	return found
+}
+
+// Index returns a new TitleIndex over the cached titles.
+// The index does not reflect later changes to Titles.
+// This does not download titles if the cache is empty.
+func (c *TitlesCache) Index() *TitleIndex {
+	return NewTitleIndex(c.Titles)
+}
+
+// normalizeTitle normalizes a title for matching.
+func normalizeTitle(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+// Save saves the cached titles to the cache store.
+// This method sets Updated to false if successful.
+// See also the SaveIfUpdated method, which is probably more useful.
+func (c *TitlesCache) Save() error {
+	d := TitlesData{Titles: c.Titles, ETag: c.ETag, LastModified: c.LastModified}
+	if err := c.store().Save(context.Background(), d); err != nil {
 		return fmt.Errorf("save titles cache: %s", err)
 	}
 	c.Updated = false
+	c.log(slog.LevelDebug, "Saved titles cache", "count", len(c.Titles))
 	return nil
 }
 
-// SaveIfUpdated saves the cached titles to the cache file if they
+// log logs a message to Logger, if set.
+func (c *TitlesCache) log(level slog.Level, msg string, args ...any) {
+	if c.Logger == nil {
+		return
+	}
+	c.Logger.Log(context.Background(), level, msg, args...)
+}
+
+// SaveIfUpdated saves the cached titles to the cache store if they
 // have been updated.
 // This method sets Updated to false if successful.
 func (c *TitlesCache) SaveIfUpdated() error {
@@ -111,12 +206,31 @@ func (c *TitlesCache) SaveIfUpdated() error {
 }
 
 func defaultTitlesCacheFile() string {
-	return filepath.Join(cacheDir(), xdgName, "titles.gob")
+	return filepath.Join(cacheDir(), "titles.gob")
 }
 
-func cacheDir() string {
+// CacheDir returns the directory used by the default caches, such as
+// [DefaultTitlesCache].
+// This is a directory in XDG_CACHE_HOME if set, or else in the
+// directory returned by [os.UserCacheDir], which is the platform
+// cache directory on Windows and macOS.
+func CacheDir() (string, error) {
 	if p := os.Getenv("XDG_CACHE_HOME"); p != "" {
-		return p
+		return filepath.Join(p, xdgName), nil
+	}
+	p, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("anidb CacheDir: %w", err)
+	}
+	return filepath.Join(p, xdgName), nil
+}
+
+// cacheDir returns [CacheDir], falling back to a directory in the
+// temporary directory if there is no user cache directory.
+func cacheDir() string {
+	p, err := CacheDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), xdgName)
 	}
-	return filepath.Join(os.Getenv("HOME"), ".cache")
+	return p
 }
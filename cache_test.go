@@ -15,9 +15,17 @@
 package anidb
 
 import (
+	"bytes"
+	"encoding/gob"
+	"errors"
 	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -43,6 +51,7 @@ func TestTitlesCache(t *testing.T) {
 	c := &TitlesCache{
 		Path:   f.Name(),
 		Titles: ts,
+		ETag:   `"abc"`,
 	}
 	if err := c.Save(); err != nil {
 		t.Fatalf("Error saving: %s", err)
@@ -54,4 +63,199 @@ func TestTitlesCache(t *testing.T) {
 	if !reflect.DeepEqual(c.Titles, ts) {
 		t.Errorf("got %#v; want %#v", c.Titles, ts)
 	}
+	if c.ETag != `"abc"` {
+		t.Errorf("Got ETag %q; want %q", c.ETag, `"abc"`)
+	}
+}
+
+func TestOpenTitlesCache_withoutValidators(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "titles.gob")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := []AnimeT{{AID: 22}}
+	if err := gob.NewEncoder(f).Encode(ts); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	c, err := OpenTitlesCache(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(c.Titles, ts) {
+		t.Errorf("Got %#v; want %#v", c.Titles, ts)
+	}
+}
+
+func TestTitlesCache_store(t *testing.T) {
+	s := &TitlesMemoryStore{}
+	c, err := OpenTitlesCacheStore(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Titles) != 0 {
+		t.Errorf("Got titles %#v from empty store", c.Titles)
+	}
+	ts := []AnimeT{{AID: 22}}
+	c.Titles = ts
+	c.ETag = `"abc"`
+	c.Updated = true
+	if err := c.SaveIfUpdated(); err != nil {
+		t.Fatal(err)
+	}
+	c, err = OpenTitlesCacheStore(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(c.Titles, ts) {
+		t.Errorf("Got %#v; want %#v", c.Titles, ts)
+	}
+	if c.ETag != `"abc"` {
+		t.Errorf("Got ETag %q; want %q", c.ETag, `"abc"`)
+	}
+}
+
+func TestOpenTitlesCache_corrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "titles.gob")
+	if err := os.WriteFile(path, []byte("garbage"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	c, err := OpenTitlesCache(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !errors.Is(c.LoadError, ErrCorruptCache) {
+		t.Errorf("Got LoadError %v; want %v", c.LoadError, ErrCorruptCache)
+	}
+	if len(c.Titles) != 0 {
+		t.Errorf("Got titles %#v; want none", c.Titles)
+	}
+	ts := []AnimeT{{AID: 22}}
+	c.Titles = ts
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+	c, err = OpenTitlesCache(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.LoadError != nil {
+		t.Errorf("Got LoadError %v; want nil", c.LoadError)
+	}
+	if !reflect.DeepEqual(c.Titles, ts) {
+		t.Errorf("Got %#v; want %#v", c.Titles, ts)
+	}
+}
+
+func TestTitlesCache_GetFreshTitles_notModified(t *testing.T) {
+	var gotETag string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotETag = r.Header.Get("If-None-Match")
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	t.Cleanup(srv.Close)
+	titlesBaseURL = srv.URL + "/anime-titles"
+	t.Cleanup(func() { titlesBaseURL = DefaultTitlesBaseURL })
+
+	ts := []AnimeT{{AID: 22}}
+	c := &TitlesCache{Titles: ts, ETag: `"abc"`}
+	got, err := c.GetFreshTitles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, ts) {
+		t.Errorf("Got %#v; want %#v", got, ts)
+	}
+	if gotETag != `"abc"` {
+		t.Errorf("Got If-None-Match %q; want %q", gotETag, `"abc"`)
+	}
+	if c.Updated {
+		t.Errorf("Got Updated true; want false")
+	}
+}
+
+func TestTitlesCache_GetFreshTitles_logger(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	t.Cleanup(srv.Close)
+	titlesBaseURL = srv.URL + "/anime-titles"
+	t.Cleanup(func() { titlesBaseURL = DefaultTitlesBaseURL })
+
+	var buf bytes.Buffer
+	l := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	c := &TitlesCache{Titles: []AnimeT{{AID: 22}}, ETag: `"abc"`, Logger: l}
+	if _, err := c.GetFreshTitles(); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	want := `msg="Cached titles not modified" etag="\"abc\""`
+	if !strings.Contains(got, want) {
+		t.Errorf("Got log %q; want containing %q", got, want)
+	}
+}
+
+func TestTitlesCache_FindByExactTitle(t *testing.T) {
+	kino := AnimeT{AID: 86, Titles: []Title{
+		{Name: "Kino no Tabi", Type: "main", Lang: "x-jat"},
+		{Name: "Kino`s Journey", Type: "official", Lang: "en"},
+	}}
+	kino2017 := AnimeT{AID: 12718, Titles: []Title{
+		{Name: "Kino no Tabi: The Beautiful World - The Animated Series", Type: "main", Lang: "x-jat"},
+		{Name: "Kino no Tabi", Type: "synonym", Lang: "en"},
+	}}
+	c := &TitlesCache{Titles: []AnimeT{kino, kino2017}}
+	cases := []struct {
+		desc  string
+		title string
+		langs []string
+		want  []AnimeT
+	}{
+		{desc: "all langs", title: "Kino no Tabi", want: []AnimeT{kino, kino2017}},
+		{desc: "normalized", title: "  kino NO  tabi", want: []AnimeT{kino, kino2017}},
+		{desc: "lang filter", title: "Kino no Tabi", langs: []string{"en"}, want: []AnimeT{kino2017}},
+		{desc: "multiple langs", title: "Kino no Tabi", langs: []string{"en", "x-jat"}, want: []AnimeT{kino, kino2017}},
+		{desc: "no match", title: "Kino", want: nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			got := c.FindByExactTitle(tc.title, tc.langs...)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %#v; want %#v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTitlesCache_FindByExactTitle_modified(t *testing.T) {
+	t.Parallel()
+	c := &TitlesCache{Titles: []AnimeT{
+		{AID: 1, Titles: []Title{{Name: "Foo", Type: "main", Lang: "x-jat"}}},
+	}}
+	if got := c.FindByExactTitle("Foo"); len(got) != 1 {
+		t.Fatalf("Got %v; want AID 1", got)
+	}
+	c.Titles[0] = AnimeT{AID: 2, Titles: []Title{{Name: "Bar", Type: "main", Lang: "x-jat"}}}
+	if got := c.FindByExactTitle("Foo"); got != nil {
+		t.Errorf("Got %v for old title; want nil", got)
+	}
+	got := c.FindByExactTitle("bar")
+	if len(got) != 1 || got[0].AID != 2 {
+		t.Errorf("Got %v; want AID 2", got)
+	}
+}
+
+func TestCacheDir(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", "/xdg/cache")
+	got, err := CacheDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join("/xdg/cache", xdgName); got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+	if got, want := DefaultAnimeCache().Dir, filepath.Join("/xdg/cache", xdgName, "anime"); got != want {
+		t.Errorf("Got anime cache dir %q; want %q", got, want)
+	}
 }
@@ -0,0 +1,109 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ed2k implements the ed2k hash used by AniDB to identify
+// files.
+//
+// A file is split into chunks of ChunkSize bytes, each of which is
+// hashed with MD4.
+// The ed2k hash of a file smaller than ChunkSize is the MD4 hash of
+// the file.
+// Otherwise, it is the MD4 hash of the concatenated chunk hashes.
+//
+// For files whose size is a non-zero multiple of ChunkSize, this
+// package follows the original eDonkey client and includes the hash
+// of a trailing empty chunk, which is what AniDB expects.
+package ed2k
+
+import (
+	"encoding/hex"
+	"hash"
+)
+
+// ChunkSize is the size of an ed2k chunk in bytes.
+const ChunkSize = 9728000
+
+// Size is the size of an ed2k hash in bytes.
+const Size = 16
+
+// BlockSize is the block size of ed2k in bytes.
+const BlockSize = md4BlockSize
+
+// New returns a new hash.Hash computing the ed2k hash.
+func New() hash.Hash {
+	return &digest{chunk: newMD4()}
+}
+
+type digest struct {
+	chunk *md4
+	// n is the number of bytes written to the current chunk.
+	n int
+	// hashes are the hashes of the completed chunks.
+	hashes []byte
+}
+
+func (d *digest) Size() int      { return Size }
+func (d *digest) BlockSize() int { return BlockSize }
+
+func (d *digest) Reset() {
+	d.chunk.Reset()
+	d.n = 0
+	d.hashes = d.hashes[:0]
+}
+
+func (d *digest) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		c := min(len(p), ChunkSize-d.n)
+		d.chunk.Write(p[:c])
+		d.n += c
+		p = p[c:]
+		if d.n == ChunkSize {
+			d.hashes = d.chunk.Sum(d.hashes)
+			d.chunk.Reset()
+			d.n = 0
+		}
+	}
+	return n, nil
+}
+
+func (d *digest) Sum(b []byte) []byte {
+	if len(d.hashes) == 0 {
+		return d.chunk.Sum(b)
+	}
+	h := make([]byte, 0, len(d.hashes)+Size)
+	h = append(h, d.hashes...)
+	return combine(d.chunk.Sum(h), b)
+}
+
+// combine appends the ed2k hash of a multi-chunk file to b, given
+// the concatenated chunk hashes.
+func combine(hashes, b []byte) []byte {
+	m := newMD4()
+	m.Write(hashes)
+	return m.Sum(b)
+}
+
+// A File is the ed2k identification of a file, as used by AniDB
+// FILE and MYLISTADD requests.
+type File struct {
+	Size int64
+	Hash [Size]byte
+}
+
+// String returns the hash as lowercase hex, as used by the AniDB
+// APIs.
+func (f File) String() string {
+	return hex.EncodeToString(f.Hash[:])
+}
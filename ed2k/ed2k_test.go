@@ -0,0 +1,129 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ed2k
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestMD4(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{"", "31d6cfe0d16ae931b73c59d7e0c089c0"},
+		{"abc", "a448017aaf21d8525fc10ae87aa6729d"},
+		{"message digest", "d9130a8164549fe818874806e1c7014b"},
+		{"12345678901234567890123456789012345678901234567890123456789012345678901234567890", "e33b4ddc9c38f2199c3e7b164fcc0536"},
+	}
+	for _, c := range cases {
+		d := newMD4()
+		d.Write([]byte(c.in))
+		if got := hex.EncodeToString(d.Sum(nil)); got != c.want {
+			t.Errorf("md4(%q) = %s; want %s", c.in, got, c.want)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		desc string
+		size int
+		want string
+	}{
+		{desc: "empty", size: 0, want: "31d6cfe0d16ae931b73c59d7e0c089c0"},
+		{desc: "one chunk", size: ChunkSize, want: "fc21d9af828f92a8df64beac3357425d"},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.desc, func(t *testing.T) {
+			t.Parallel()
+			h := New()
+			h.Write(make([]byte, c.size))
+			if got := hex.EncodeToString(h.Sum(nil)); got != c.want {
+				t.Errorf("Got %s; want %s", got, c.want)
+			}
+		})
+	}
+}
+
+func TestHashFile(t *testing.T) {
+	t.Parallel()
+	d := make([]byte, 2*ChunkSize+100)
+	for i := range d {
+		d[i] = byte(i * 7)
+	}
+	path := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(path, d, 0666); err != nil {
+		t.Fatal(err)
+	}
+	h := New()
+	// Write in uneven pieces to exercise chunk boundaries.
+	for p := d; len(p) > 0; {
+		n := min(len(p), 1000003)
+		h.Write(p[:n])
+		p = p[n:]
+	}
+	want := h.Sum(nil)
+
+	var (
+		mu       sync.Mutex
+		progress []int64
+	)
+	got, err := HashFile(context.Background(), path, &Options{
+		Workers: 2,
+		Progress: func(done, total int64) {
+			mu.Lock()
+			defer mu.Unlock()
+			if total != int64(len(d)) {
+				t.Errorf("Got total %d; want %d", total, len(d))
+			}
+			progress = append(progress, done)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Size != int64(len(d)) {
+		t.Errorf("Got size %d; want %d", got.Size, len(d))
+	}
+	if !bytes.Equal(got.Hash[:], want) {
+		t.Errorf("Got %s; want %x", got, want)
+	}
+	if n := len(progress); n != 3 || progress[n-1] != int64(len(d)) {
+		t.Errorf("Got progress %v", progress)
+	}
+}
+
+func TestHashFile_cancelled(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(path, []byte("abc"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := HashFile(ctx, path, nil); err == nil {
+		t.Errorf("Expected error")
+	}
+}
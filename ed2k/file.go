@@ -0,0 +1,132 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ed2k
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"sync"
+)
+
+// Options configures HashFile.
+type Options struct {
+	// Workers is the number of chunks hashed concurrently.
+	// If zero, runtime.NumCPU is used.
+	Workers int
+	// Progress, if set, is called after each chunk is hashed with
+	// the number of bytes hashed so far and the file size.
+	// Calls are serialized.
+	Progress func(done, total int64)
+}
+
+// HashFile computes the ed2k hash of the file at path.
+// Chunks are hashed concurrently.
+// The options may be nil.
+func HashFile(ctx context.Context, path string, o *Options) (File, error) {
+	if o == nil {
+		o = &Options{}
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return File{}, fmt.Errorf("ed2k HashFile: %w", err)
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return File{}, fmt.Errorf("ed2k HashFile: %w", err)
+	}
+	size := fi.Size()
+	hashes, err := hashChunks(ctx, f, size, o)
+	if err != nil {
+		return File{}, fmt.Errorf("ed2k HashFile %s: %w", path, err)
+	}
+	r := File{Size: size}
+	if size < ChunkSize {
+		copy(r.Hash[:], hashes)
+	} else {
+		copy(r.Hash[:], combine(hashes, nil))
+	}
+	return r, nil
+}
+
+// hashChunks returns the concatenated hashes of the chunks of a file.
+// If the size is a multiple of ChunkSize, the hash of the trailing
+// empty chunk is included.
+func hashChunks(ctx context.Context, f *os.File, size int64, o *Options) ([]byte, error) {
+	n := int(size/ChunkSize) + 1
+	hashes := make([]byte, n*Size)
+	workers := o.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	idx := make(chan int)
+	go func() {
+		defer close(idx)
+		for i := 0; i < n; i++ {
+			select {
+			case idx <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		done     int64
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			buf := make([]byte, ChunkSize)
+			for i := range idx {
+				off := int64(i) * ChunkSize
+				c := int(min(size-off, ChunkSize))
+				if _, err := f.ReadAt(buf[:c], off); err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+					cancel()
+					return
+				}
+				m := newMD4()
+				m.Write(buf[:c])
+				copy(hashes[i*Size:], m.Sum(nil))
+				mu.Lock()
+				done += int64(c)
+				if o.Progress != nil {
+					o.Progress(done, size)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return hashes, nil
+}
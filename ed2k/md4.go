@@ -0,0 +1,123 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ed2k
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// md4 implements the MD4 hash algorithm (RFC 1320), which ed2k is
+// built on.
+// The standard library does not provide MD4.
+type md4 struct {
+	s   [4]uint32
+	x   [md4BlockSize]byte
+	nx  int
+	len uint64
+}
+
+const md4BlockSize = 64
+
+func newMD4() *md4 {
+	d := new(md4)
+	d.Reset()
+	return d
+}
+
+func (d *md4) Reset() {
+	d.s = [4]uint32{0x67452301, 0xefcdab89, 0x98badcfe, 0x10325476}
+	d.nx = 0
+	d.len = 0
+}
+
+func (d *md4) Write(p []byte) (int, error) {
+	n := len(p)
+	d.len += uint64(n)
+	if d.nx > 0 {
+		c := copy(d.x[d.nx:], p)
+		d.nx += c
+		p = p[c:]
+		if d.nx < md4BlockSize {
+			return n, nil
+		}
+		d.block(d.x[:])
+		d.nx = 0
+	}
+	for len(p) >= md4BlockSize {
+		d.block(p[:md4BlockSize])
+		p = p[md4BlockSize:]
+	}
+	d.nx = copy(d.x[:], p)
+	return n, nil
+}
+
+// Sum appends the hash to b without changing the state of d.
+func (d *md4) Sum(b []byte) []byte {
+	c := *d
+	var pad [md4BlockSize + 8]byte
+	pad[0] = 0x80
+	n := 56 - int(c.len%md4BlockSize)
+	if n <= 0 {
+		n += md4BlockSize
+	}
+	binary.LittleEndian.PutUint64(pad[n:], c.len<<3)
+	c.Write(pad[:n+8])
+	for _, s := range c.s {
+		b = binary.LittleEndian.AppendUint32(b, s)
+	}
+	return b
+}
+
+var md4Shifts = [3][4]int{
+	{3, 7, 11, 19},
+	{3, 5, 9, 13},
+	{3, 9, 11, 15},
+}
+
+var md4Order = [3][16]int{
+	{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	{0, 4, 8, 12, 1, 5, 9, 13, 2, 6, 10, 14, 3, 7, 11, 15},
+	{0, 8, 4, 12, 2, 10, 6, 14, 1, 9, 5, 13, 3, 11, 7, 15},
+}
+
+func (d *md4) block(p []byte) {
+	var x [16]uint32
+	for i := range x {
+		x[i] = binary.LittleEndian.Uint32(p[4*i:])
+	}
+	a, b, c, e := d.s[0], d.s[1], d.s[2], d.s[3]
+	for r := 0; r < 3; r++ {
+		for i, k := range md4Order[r] {
+			var f, add uint32
+			switch r {
+			case 0:
+				f = (b & c) | (^b & e)
+			case 1:
+				f = (b & c) | (b & e) | (c & e)
+				add = 0x5a827999
+			case 2:
+				f = b ^ c ^ e
+				add = 0x6ed9eba1
+			}
+			t := bits.RotateLeft32(a+f+x[k]+add, md4Shifts[r][i%4])
+			a, b, c, e = e, t, b, c
+		}
+	}
+	d.s[0] += a
+	d.s[1] += b
+	d.s[2] += c
+	d.s[3] += e
+}
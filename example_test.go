@@ -47,8 +47,22 @@ func ExampleTitlesCache() {
 	for _, anime := range titles {
 		for _, t := range anime.Titles {
 			if strings.Index(t.Name, "bofuri") >= 0 {
-				matched = append(matched)
+				matched = append(matched, anime)
 			}
 		}
 	}
 }
+
+func ExampleTitleIndex() {
+	c, err := anidb.DefaultTitlesCache()
+	if err != nil {
+		panic(err)
+	}
+	defer c.SaveIfUpdated()
+	if _, err := c.GetTitles(); err != nil {
+		panic(err)
+	}
+	x := c.Index()
+	aids := x.Fuzzy("bofuri", anidb.TitleFilter{Langs: []string{"x-jat", "en"}})
+	fmt.Print(aids)
+}
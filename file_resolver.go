@@ -0,0 +1,337 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package anidb
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.felesatra.moe/anidb/ed2k"
+	"go.felesatra.moe/anidb/udpapi"
+)
+
+// DefaultFileTTL is the default TTL for file information in
+// [FileCache].
+const DefaultFileTTL = 7 * 24 * time.Hour
+
+// A FileCache is an on-disk cache for [FileResolver].
+// It caches the ed2k hashes of local files, keyed by path, size and
+// modification time, and FILE responses, keyed by size and ed2k hash.
+//
+// The cache file is loaded when first used.
+// Changes are kept in memory until saved with Save or
+// SaveIfUpdated.
+type FileCache struct {
+	// Path is the cache file.
+	Path string
+	// TTL is how long cached FILE responses are fresh.
+	// Hashes do not expire, as they are invalidated by changes to
+	// the file size or modification time.
+	// If zero, [DefaultFileTTL] is used.
+	TTL time.Duration
+
+	mu      sync.Mutex
+	loaded  bool
+	updated bool
+	data    fileCacheData
+}
+
+// DefaultFileCache returns a FileCache at a default location,
+// in [CacheDir].
+func DefaultFileCache() *FileCache {
+	return &FileCache{Path: filepath.Join(cacheDir(), "files.json")}
+}
+
+type fileCacheData struct {
+	// Hashes is keyed by absolute path.
+	Hashes map[string]hashEntry `json:"hashes"`
+	// Files is keyed by fileKey.
+	Files map[string]fileEntry `json:"files"`
+}
+
+type hashEntry struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mtime"`
+	ED2K    string    `json:"ed2k"`
+}
+
+type fileEntry struct {
+	FID   int              `json:"fid"`
+	Fmask udpapi.FileFmask `json:"fmask"`
+	Amask udpapi.FileAmask `json:"amask"`
+	Row   []string         `json:"row"`
+	Time  time.Time        `json:"time"`
+}
+
+func fileKey(f ed2k.File) string {
+	return strconv.FormatInt(f.Size, 10) + ":" + f.String()
+}
+
+// hash returns the cached hash for a file, if the file is unchanged.
+func (c *FileCache) hash(path string, fi fs.FileInfo) (ed2k.File, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.load(); err != nil {
+		return ed2k.File{}, false, err
+	}
+	e, ok := c.data.Hashes[path]
+	if !ok || e.Size != fi.Size() || !e.ModTime.Equal(fi.ModTime()) {
+		return ed2k.File{}, false, nil
+	}
+	f, err := parseED2K(e.Size, e.ED2K)
+	if err != nil {
+		return ed2k.File{}, false, nil
+	}
+	return f, true, nil
+}
+
+func (c *FileCache) putHash(path string, fi fs.FileInfo, f ed2k.File) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.load(); err != nil {
+		return err
+	}
+	c.data.Hashes[path] = hashEntry{Size: fi.Size(), ModTime: fi.ModTime(), ED2K: f.String()}
+	c.updated = true
+	return nil
+}
+
+// file returns the cached FILE entry for a file.
+// The entry may be stale or for other masks.
+func (c *FileCache) file(f ed2k.File) (fileEntry, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.load(); err != nil {
+		return fileEntry{}, false, err
+	}
+	e, ok := c.data.Files[fileKey(f)]
+	return e, ok, nil
+}
+
+func (c *FileCache) putFile(f ed2k.File, e fileEntry) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.load(); err != nil {
+		return err
+	}
+	c.data.Files[fileKey(f)] = e
+	c.updated = true
+	return nil
+}
+
+// fresh returns whether a FILE entry is fresh and for the given masks.
+func (c *FileCache) fresh(e fileEntry, fmask udpapi.FileFmask, amask udpapi.FileAmask) bool {
+	ttl := c.TTL
+	if ttl <= 0 {
+		ttl = DefaultFileTTL
+	}
+	return e.Fmask == fmask && e.Amask == amask && time.Since(e.Time) <= ttl
+}
+
+// load loads the cache file once.
+// c.mu must be held.
+func (c *FileCache) load() error {
+	if c.loaded {
+		return nil
+	}
+	d, err := os.ReadFile(c.Path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("file cache load: %s", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(d, &c.data); err != nil {
+			return fmt.Errorf("file cache load: %s", err)
+		}
+	}
+	if c.data.Hashes == nil {
+		c.data.Hashes = make(map[string]hashEntry)
+	}
+	if c.data.Files == nil {
+		c.data.Files = make(map[string]fileEntry)
+	}
+	c.loaded = true
+	return nil
+}
+
+// Save saves the cache file.
+// If the cache has not been used, this does nothing.
+func (c *FileCache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.loaded {
+		return nil
+	}
+	return c.save()
+}
+
+// SaveIfUpdated saves the cache file if the cache has been updated
+// since it was loaded or last saved.
+func (c *FileCache) SaveIfUpdated() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.updated {
+		return nil
+	}
+	return c.save()
+}
+
+// save saves the cache file.
+// c.mu must be held.
+func (c *FileCache) save() error {
+	d, err := json.Marshal(c.data)
+	if err != nil {
+		return fmt.Errorf("file cache save: %s", err)
+	}
+	if err := writeCacheFile(filepath.Dir(c.Path), c.Path, d); err != nil {
+		return fmt.Errorf("file cache save: %s", err)
+	}
+	c.updated = false
+	return nil
+}
+
+// A FileResolver identifies local files with AniDB, by hashing them
+// and looking them up with the FILE command.
+//
+// Hashes and FILE responses are cached if Cache is set, so files
+// that have already been resolved do not need to be hashed or looked
+// up again.
+// Call [FileCache.SaveIfUpdated] after resolving files to save the
+// cache.
+type FileResolver struct {
+	// Client is the UDP API client, which must be logged in.
+	Client *udpapi.Client
+	// Fmask and Amask select the file information to look up.
+	Fmask udpapi.FileFmask
+	Amask udpapi.FileAmask
+	// Cache is an optional cache.
+	Cache *FileCache
+	// HashOptions are the options for hashing files.
+	HashOptions *ed2k.Options
+}
+
+// Resolve identifies a local file with AniDB.
+// The returned error wraps a return code if applicable, such as
+// [go.felesatra.moe/anidb/udpapi/codes.NO_SUCH_FILE] for files
+// unknown to AniDB.
+func (r *FileResolver) Resolve(ctx context.Context, path string) (udpapi.FileInfo, error) {
+	f, err := r.Hash(ctx, path)
+	if err != nil {
+		return udpapi.FileInfo{}, fmt.Errorf("anidb resolve %s: %w", path, err)
+	}
+	fi, err := r.ResolveHash(ctx, f)
+	if err != nil {
+		return udpapi.FileInfo{}, fmt.Errorf("anidb resolve %s: %w", path, err)
+	}
+	return fi, nil
+}
+
+// Hash returns the ed2k hash of a local file, using the cache if set.
+func (r *FileResolver) Hash(ctx context.Context, path string) (ed2k.File, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return ed2k.File{}, err
+	}
+	st, err := os.Stat(path)
+	if err != nil {
+		return ed2k.File{}, err
+	}
+	if r.Cache != nil {
+		f, ok, err := r.Cache.hash(path, st)
+		if err != nil {
+			return ed2k.File{}, err
+		}
+		if ok {
+			return f, nil
+		}
+	}
+	f, err := ed2k.HashFile(ctx, path, r.HashOptions)
+	if err != nil {
+		return ed2k.File{}, err
+	}
+	if r.Cache != nil {
+		if err := r.Cache.putHash(path, st, f); err != nil {
+			return ed2k.File{}, err
+		}
+	}
+	return f, nil
+}
+
+// ResolveHash identifies a file by ed2k hash with AniDB, using the
+// cache if set.
+func (r *FileResolver) ResolveHash(ctx context.Context, f ed2k.File) (udpapi.FileInfo, error) {
+	var e fileEntry
+	var cached bool
+	if r.Cache != nil {
+		var err error
+		e, cached, err = r.Cache.file(f)
+		if err != nil {
+			return udpapi.FileInfo{}, err
+		}
+		if cached && r.Cache.fresh(e, r.Fmask, r.Amask) {
+			return udpapi.DecodeFileInfo(r.Fmask, r.Amask, e.Row)
+		}
+	}
+	var row []string
+	var err error
+	if cached {
+		// Looking up by fid avoids sending the hash again.
+		row, err = r.Client.FileByFID(ctx, e.FID, r.Fmask, r.Amask)
+	} else {
+		row, err = r.Client.FileByHash(ctx, f.Size, f.String(), r.Fmask, r.Amask)
+	}
+	if err != nil {
+		return udpapi.FileInfo{}, err
+	}
+	fi, err := udpapi.DecodeFileInfo(r.Fmask, r.Amask, row)
+	if err != nil {
+		return udpapi.FileInfo{}, err
+	}
+	if r.Cache != nil {
+		e := fileEntry{
+			FID:   fi.FID,
+			Fmask: r.Fmask,
+			Amask: r.Amask,
+			Row:   row,
+			Time:  time.Now(),
+		}
+		if err := r.Cache.putFile(f, e); err != nil {
+			return udpapi.FileInfo{}, err
+		}
+	}
+	return fi, nil
+}
+
+// parseED2K parses a hex ed2k hash.
+func parseED2K(size int64, s string) (ed2k.File, error) {
+	f := ed2k.File{Size: size}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return f, err
+	}
+	if len(b) != ed2k.Size {
+		return f, fmt.Errorf("invalid ed2k hash %q", s)
+	}
+	copy(f.Hash[:], b)
+	return f, nil
+}
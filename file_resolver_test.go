@@ -0,0 +1,121 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package anidb
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.felesatra.moe/anidb/ed2k"
+	"go.felesatra.moe/anidb/udpapi"
+	"go.felesatra.moe/anidb/udpapi/codes"
+	"go.felesatra.moe/anidb/udpapi/udptest"
+)
+
+type nopLimiter struct{}
+
+func (nopLimiter) Wait(context.Context) error { return nil }
+
+func TestFileResolver(t *testing.T) {
+	t.Parallel()
+	ctx, cf := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cf)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "video.mkv")
+	if err := os.WriteFile(path, []byte("some video"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	f, err := ed2k.HashFile(ctx, path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := udptest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	s.AddUser("ionasal", "pass")
+	s.AddFile(udptest.File{FID: 312498, Size: f.Size, ED2K: f.String(), Fields: []string{"8076"}})
+	c, err := udpapi.Dial(s.Addr, slog.New(slog.NewTextHandler(discard{}, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	c.SetLimiter(nopLimiter{})
+	if _, err := c.Auth(ctx, udpapi.UserInfo{UserName: "ionasal", UserPassword: "pass"}); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &FileResolver{
+		Client: c,
+		Cache:  &FileCache{Path: filepath.Join(dir, "cache", "files.json")},
+	}
+	r.Fmask.Set("aid")
+	for i := 0; i < 2; i++ {
+		fi, err := r.Resolve(ctx, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fi.FID != 312498 || fi.AID != 8076 {
+			t.Errorf("Got %#v; want fid 312498, aid 8076", fi)
+		}
+	}
+	var n int
+	for _, req := range s.Requests() {
+		if req.Cmd == "FILE" {
+			n++
+		}
+	}
+	if n != 1 {
+		t.Errorf("Got %d FILE requests; want 1", n)
+	}
+
+	if _, err := os.Stat(r.Cache.Path); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Got cache file before save; stat error %v", err)
+	}
+	if err := r.Cache.SaveIfUpdated(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A new cache instance loads the persisted cache.
+	r2 := &FileResolver{Client: c, Cache: &FileCache{Path: r.Cache.Path}, Fmask: r.Fmask}
+	got, ok, err := r2.Cache.hash(path, mustStat(t, path))
+	if err != nil || !ok || got != f {
+		t.Errorf("Got cached hash %v, %v, %v; want %v", got, ok, err, f)
+	}
+
+	if err := os.WriteFile(path, []byte("other video"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r2.Resolve(ctx, path); !errors.Is(err, codes.NO_SUCH_FILE) {
+		t.Errorf("Got error %v for changed file; want %v", err, codes.NO_SUCH_FILE)
+	}
+}
+
+func mustStat(t *testing.T, path string) os.FileInfo {
+	t.Helper()
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fi
+}
@@ -0,0 +1,122 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package anidb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// fileLimiterPoll is how often a FileLimiter retries taking the lock.
+const fileLimiterPoll = 50 * time.Millisecond
+
+// A FileLimiter is a rate limiter that is shared across processes
+// using a lock file.
+// This is useful for multiple tools on the same machine sharing the
+// AniDB request budget.
+//
+// A FileLimiter implements both [Limiter] and [udpapi.Limiter].
+// File locking is only supported on Unix.
+type FileLimiter struct {
+	// Path is the path to the lock file, which is created if needed.
+	// The file stores the earliest time for the next request.
+	Path string
+	// Interval is the minimum time between requests.
+	Interval time.Duration
+}
+
+// Wait blocks until a request is allowed.
+func (l *FileLimiter) Wait(ctx context.Context) error {
+	for {
+		d, err := l.take()
+		if err != nil {
+			return fmt.Errorf("anidb FileLimiter: %w", err)
+		}
+		if d == 0 {
+			return nil
+		}
+		t := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// take takes a request slot if one is available.
+// Otherwise, it returns how long to wait before trying again.
+func (l *FileLimiter) take() (time.Duration, error) {
+	f, err := os.OpenFile(l.Path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	ok, err := tryLockFile(f)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return fileLimiterPoll, nil
+	}
+	defer unlockFile(f)
+	next, err := readNextTime(f)
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	if d := next.Sub(now); d > 0 {
+		return d, nil
+	}
+	if err := writeNextTime(f, now.Add(l.Interval)); err != nil {
+		return 0, err
+	}
+	return 0, nil
+}
+
+// readNextTime reads the next request time from a lock file.
+// An empty file returns the zero time.
+func readNextTime(f *os.File) (time.Time, error) {
+	b := make([]byte, 32)
+	n, err := f.ReadAt(b, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return time.Time{}, err
+	}
+	s := strings.TrimSpace(string(b[:n]))
+	if s == "" {
+		return time.Time{}, nil
+	}
+	ns, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("read lock file: %w", err)
+	}
+	return time.Unix(0, ns), nil
+}
+
+// writeNextTime writes the next request time to a lock file.
+func writeNextTime(f *os.File, t time.Time) error {
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	_, err := f.WriteAt([]byte(strconv.FormatInt(t.UnixNano(), 10)+"\n"), 0)
+	return err
+}
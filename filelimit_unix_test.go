@@ -0,0 +1,68 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unix
+
+package anidb
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.felesatra.moe/anidb/udpapi"
+)
+
+var (
+	_ Limiter        = &FileLimiter{}
+	_ udpapi.Limiter = &FileLimiter{}
+)
+
+func TestFileLimiter(t *testing.T) {
+	t.Parallel()
+	ctx, cf := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cf()
+	path := filepath.Join(t.TempDir(), "lock")
+	const every = 100 * time.Millisecond
+	// Separate limiters act like separate processes.
+	ls := []*FileLimiter{
+		{Path: path, Interval: every},
+		{Path: path, Interval: every},
+	}
+	start := time.Now()
+	const n = 4
+	for i := 0; i < n; i++ {
+		if err := ls[i%2].Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := time.Since(start), (n-1)*every; got < want {
+		t.Errorf("Got %v for %d waits; want at least %v", got, n, want)
+	}
+}
+
+func TestFileLimiter_canceled(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "lock")
+	l := &FileLimiter{Path: path, Interval: time.Hour}
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cf := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cf()
+	if err := l.Wait(ctx); err == nil {
+		t.Errorf("Expected error")
+	}
+}
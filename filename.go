@@ -0,0 +1,162 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package anidb
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// A ReleaseName is the information parsed from a release filename by
+// [ParseReleaseName].
+type ReleaseName struct {
+	// Title is the probable anime title.
+	Title string
+	// Episode is the episode number, like "12" or "S1", or empty if
+	// none was found.
+	Episode string
+	// Group is the release group, or empty if none was found.
+	Group string
+}
+
+var (
+	// leadingGroup matches a release group in brackets at the start
+	// of a filename.
+	leadingGroup = regexp.MustCompile(`^\s*[\[(]([^\])]+)[\])]`)
+	// bracketed matches tags in brackets, like CRC32 checksums and
+	// resolutions.
+	bracketed = regexp.MustCompile(`\[[^\]]*\]|\([^)]*\)|\{[^}]*\}`)
+	// qualityTag matches common quality tags outside of brackets.
+	qualityTag = regexp.MustCompile(`(?i)\b(\d{3,4}p|x26[45]|hevc|avc|aac|flac|web-?dl|web-?rip|bd-?rip|blu-?ray|dvd-?rip|10-?bit)\b`)
+	// episodePatterns match episode numbers, in order of preference.
+	// The first group is the episode number.
+	episodePatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)\bS\d{1,2}E(\d{1,4})\b`),
+		regexp.MustCompile(`(?i)\s-\s+((?:S|SP|OVA|C|T|P|O)?\d{1,4})(?:v\d+)?\b`),
+		regexp.MustCompile(`(?i)\b(?:ep?|episode)\.?\s*(\d{1,4})(?:v\d+)?\b`),
+		regexp.MustCompile(`(?i)\s(\d{1,4})(?:v\d+)?\s*$`),
+	}
+)
+
+// ParseReleaseName extracts the probable title, episode number and
+// release group from a release filename, such as
+// "[Group] Anime Title - 01 [1080p][ABCD1234].mkv".
+//
+// Filenames vary wildly, so this is a heuristic.
+// See [TitleIndex.MatchFilename] to match the title against anime
+// titles.
+func ParseReleaseName(name string) ReleaseName {
+	var r ReleaseName
+	name = filepath.Base(name)
+	if ext := filepath.Ext(name); len(ext) >= 2 && len(ext) <= 5 {
+		name = strings.TrimSuffix(name, ext)
+	}
+	if m := leadingGroup.FindStringSubmatchIndex(name); m != nil {
+		r.Group = strings.TrimSpace(name[m[2]:m[3]])
+		name = name[m[1]:]
+	}
+	name = bracketed.ReplaceAllString(name, " ")
+	// Filenames without spaces use dots or underscores instead.
+	name = strings.ReplaceAll(name, "_", " ")
+	if !strings.Contains(name, " ") {
+		name = strings.ReplaceAll(name, ".", " ")
+	}
+	name = qualityTag.ReplaceAllString(name, " ")
+	name = strings.Join(strings.Fields(name), " ")
+	for _, p := range episodePatterns {
+		m := p.FindStringSubmatchIndex(name)
+		if m == nil {
+			continue
+		}
+		r.Episode = strings.ToUpper(name[m[2]:m[3]])
+		r.Title = name[:m[0]]
+		break
+	}
+	if r.Episode == "" {
+		r.Title = name
+	}
+	r.Title = strings.Trim(r.Title, " -_.")
+	return r
+}
+
+// A TitleMatch is a candidate anime returned by
+// [TitleIndex.MatchFilename].
+type TitleMatch struct {
+	AID int
+	// Score is how well the title matched, from 0 to 1.
+	// 1 is a full match after normalization.
+	Score float64
+}
+
+// MatchFilename parses a release filename with [ParseReleaseName]
+// and matches the title against the index.
+// It returns the parsed filename and the candidate anime, ranked by
+// score, with ties broken by AID.
+//
+// Titles are matched after normalization like [TitleIndex.Fuzzy].
+// A full match scores highest, then titles starting with or
+// containing the parsed title, and titles that the parsed title
+// starts with, such as when the filename has a season suffix the
+// title does not.
+func (x *TitleIndex) MatchFilename(name string, f TitleFilter) (ReleaseName, []TitleMatch) {
+	r := ParseReleaseName(name)
+	q := fuzzyTitle(r.Title)
+	if q == "" {
+		return r, nil
+	}
+	scores := make(map[int]float64)
+	for _, e := range x.entries {
+		if e.norm == "" || !f.match(e.title) {
+			continue
+		}
+		s := matchScore(q, e.norm)
+		if s > scores[e.aid] {
+			scores[e.aid] = s
+		}
+	}
+	var m []TitleMatch
+	for aid, s := range scores {
+		m = append(m, TitleMatch{AID: aid, Score: s})
+	}
+	sort.Slice(m, func(i, j int) bool {
+		if m[i].Score != m[j].Score {
+			return m[i].Score > m[j].Score
+		}
+		return m[i].AID < m[j].AID
+	})
+	return r, m
+}
+
+// matchScore scores how well a normalized query matches a normalized
+// title.
+func matchScore(q, title string) float64 {
+	ratio := func(a, b string) float64 {
+		return float64(len(a)) / float64(len(b))
+	}
+	switch {
+	case q == title:
+		return 1
+	case strings.HasPrefix(title, q):
+		return 0.9 * ratio(q, title)
+	case strings.HasPrefix(q, title):
+		return 0.8 * ratio(title, q)
+	case strings.Contains(title, q):
+		return 0.6 * ratio(q, title)
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package anidb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseReleaseName(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		name string
+		want ReleaseName
+	}{
+		{
+			name: "[SubsPlease] Suzumiya Haruhi no Yuuutsu - 01 (1080p) [ABCD1234].mkv",
+			want: ReleaseName{Title: "Suzumiya Haruhi no Yuuutsu", Episode: "01", Group: "SubsPlease"},
+		},
+		{
+			name: "/videos/[Coalgirls]_Neon_Genesis_Evangelion_-_26v2_[BD_1080p][12345678].mkv",
+			want: ReleaseName{Title: "Neon Genesis Evangelion", Episode: "26", Group: "Coalgirls"},
+		},
+		{
+			name: "Neon.Genesis.Evangelion.S01E05.1080p.BluRay.x264.mkv",
+			want: ReleaseName{Title: "Neon Genesis Evangelion", Episode: "05"},
+		},
+		{
+			name: "Tokyo Magnitude 8.0 Episode 3.mp4",
+			want: ReleaseName{Title: "Tokyo Magnitude 8.0", Episode: "3"},
+		},
+		{
+			name: "[Group] Suzumiya Haruhi no Yuuutsu - s2 [720p].mkv",
+			want: ReleaseName{Title: "Suzumiya Haruhi no Yuuutsu", Episode: "S2", Group: "Group"},
+		},
+		{
+			name: "Evangelion Shin Gekijouban.mkv",
+			want: ReleaseName{Title: "Evangelion Shin Gekijouban"},
+		},
+	}
+	for _, c := range cases {
+		if got := ParseReleaseName(c.name); got != c.want {
+			t.Errorf("ParseReleaseName(%q) = %+v; want %+v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestTitleIndex_MatchFilename(t *testing.T) {
+	t.Parallel()
+	x := testTitleIndex()
+	r, got := x.MatchFilename("[Group] Evangelion - 01 [1080p].mkv", TitleFilter{})
+	if r.Episode != "01" {
+		t.Errorf("Got episode %q; want 01", r.Episode)
+	}
+	var aids []int
+	for _, m := range got {
+		aids = append(aids, m.AID)
+	}
+	// Evangelion Shin Gekijouban starts with the title, so it ranks
+	// above the titles only containing it.
+	if want := []int{5000, 22}; !reflect.DeepEqual(aids, want) {
+		t.Errorf("Got %v; want %v", aids, want)
+	}
+
+	_, got = x.MatchFilename("The Melancholy of Haruhi Suzumiya - 03.mkv", TitleFilter{})
+	if len(got) == 0 || got[0].AID != 3651 || got[0].Score != 1 {
+		t.Errorf("Got %v; want full match for 3651 first", got)
+	}
+
+	_, got = x.MatchFilename("Suzumiya Haruhi no Yuuutsu 2009 - 03.mkv", TitleFilter{})
+	if len(got) != 1 || got[0].AID != 3651 {
+		t.Errorf("Got %v; want match for 3651", got)
+	}
+}
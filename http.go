@@ -17,11 +17,13 @@ package anidb
 import (
 	"context"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strconv"
+	"sync"
 	"time"
 )
 
@@ -35,6 +37,30 @@ type Client struct {
 	// Limiter specifies a rate limiter to use.
 	// If unset, no rate limiting is done.
 	Limiter Limiter
+	// HTTPClient is the HTTP client to use for requests.
+	// This can be used to configure proxies, TLS and
+	// instrumentation.
+	// If unset, a default client with a timeout is used.
+	HTTPClient *http.Client
+	// BaseURL is the URL of the HTTP API.
+	// This can be used to connect with HTTPS or to a test server.
+	// If unset, [DefaultHTTPAPIURL] is used.
+	BaseURL string
+	// UserAgent is the User-Agent header for requests.
+	// If unset, the Go default is used.
+	UserAgent string
+	// AnimeCache is consulted before requesting anime, and updated
+	// with requested anime.
+	// If unset, anime are not cached.
+	AnimeCache *AnimeCache
+	// ListCache is consulted before requesting hot anime and the
+	// main page, and updated with requested lists.
+	// If unset, lists are not cached.
+	ListCache *ListCache
+	// TitlesBaseURL is the URL of the titles dump without the
+	// format extension.
+	// If unset, [DefaultTitlesBaseURL] is used.
+	TitlesBaseURL string
 }
 
 // A Limiter implements rate limiting.
@@ -43,24 +69,50 @@ type Limiter interface {
 	Wait(context.Context) error
 }
 
-var httpClient = http.Client{
+// DefaultHTTPAPIURL is the URL of the AniDB HTTP API.
+const DefaultHTTPAPIURL = "http://api.anidb.net:9001/httpapi"
+
+var defaultHTTPClient = http.Client{
 	Timeout: 5 * time.Second,
 }
 
-func (c *Client) httpAPI(params map[string]string) ([]byte, error) {
+// Validate checks the client configuration for problems.
+// All problems found are reported in the returned error.
+func (c *Client) Validate() error {
+	var errs []error
+	if c.Name == "" {
+		errs = append(errs, errors.New("missing client name"))
+	}
+	if c.Version <= 0 {
+		errs = append(errs, fmt.Errorf("invalid client version %d", c.Version))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("anidb Client: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) httpAPI(ctx context.Context, params map[string]string) ([]byte, error) {
 	if c.Limiter != nil {
-		if err := c.Limiter.Wait(context.Background()); err != nil {
+		if err := c.Limiter.Wait(ctx); err != nil {
 			return nil, err
 		}
 	}
 	u := c.apiRequestURL(params)
-	resp, err := httpClient.Get(u)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+	resp, err := c.httpClient().Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != 200 {
-		return nil, err
+		return nil, fmt.Errorf("got HTTP status %s", resp.Status)
 	}
 	d, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
@@ -72,6 +124,14 @@ func (c *Client) httpAPI(params map[string]string) ([]byte, error) {
 	return d, nil
 }
 
+// httpClient returns the HTTP client to use for requests.
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return &defaultHTTPClient
+}
+
 func (c *Client) apiRequestURL(params map[string]string) string {
 	vals := url.Values{}
 	vals.Set("client", c.Name)
@@ -80,23 +140,131 @@ func (c *Client) apiRequestURL(params map[string]string) string {
 	for k, v := range params {
 		vals.Set(k, v)
 	}
-	return "http://api.anidb.net:9001/httpapi?" + vals.Encode()
+	base := c.BaseURL
+	if base == "" {
+		base = DefaultHTTPAPIURL
+	}
+	return base + "?" + vals.Encode()
 }
 
 // RequestAnime requests anime information from AniDB.
+// See [Client.RequestAnimeContext].
 func (c *Client) RequestAnime(aid int) (*Anime, error) {
-	d, err := c.httpAPI(map[string]string{
+	return c.RequestAnimeContext(context.Background(), aid)
+}
+
+// RequestAnimeContext requests anime information from AniDB.
+// The context is used for waiting on the client Limiter and for the
+// HTTP request.
+func (c *Client) RequestAnimeContext(ctx context.Context, aid int) (*Anime, error) {
+	a, _, err := c.RequestAnimeRaw(ctx, aid)
+	return a, err
+}
+
+// RequestAnimeRaw requests anime information from AniDB.
+// It returns the original XML along with the decoded anime, for
+// caching or for fields not decoded by [Anime].
+func (c *Client) RequestAnimeRaw(ctx context.Context, aid int) (*Anime, []byte, error) {
+	return c.requestAnime(ctx, aid, AllAnimeParts)
+}
+
+// RequestAnimeParts requests anime information from AniDB, decoding
+// only the selected optional parts.
+// See [AnimeParts].
+func (c *Client) RequestAnimeParts(ctx context.Context, aid int, parts AnimeParts) (*Anime, error) {
+	a, _, err := c.requestAnime(ctx, aid, parts)
+	return a, err
+}
+
+func (c *Client) requestAnime(ctx context.Context, aid int, parts AnimeParts) (*Anime, []byte, error) {
+	if c.AnimeCache != nil {
+		d, ok, err := c.AnimeCache.getRaw(aid)
+		if err != nil {
+			return nil, nil, fmt.Errorf("anidb request anime %d: %s", aid, err)
+		}
+		if ok {
+			a, err := DecodeAnimeParts(d, parts)
+			if err != nil {
+				return nil, nil, fmt.Errorf("anidb request anime %d: %s", aid, err)
+			}
+			return a, d, nil
+		}
+	}
+	d, err := c.httpAPI(ctx, map[string]string{
 		"request": "anime",
 		"aid":     strconv.Itoa(aid),
 	})
 	if err != nil {
-		return nil, fmt.Errorf("anidb request anime %d: %s", aid, err)
+		return nil, nil, fmt.Errorf("anidb request anime %d: %s", aid, err)
 	}
-	a, err := decodeAnime(d)
+	a, err := DecodeAnimeParts(d, parts)
 	if err != nil {
-		return nil, fmt.Errorf("anidb request anime %d: %s", aid, err)
+		return nil, nil, fmt.Errorf("anidb request anime %d: %s", aid, err)
 	}
-	return a, nil
+	if c.AnimeCache != nil {
+		if err := c.AnimeCache.Put(aid, d); err != nil {
+			return nil, nil, fmt.Errorf("anidb request anime %d: %s", aid, err)
+		}
+	}
+	return a, d, nil
+}
+
+// animeBatchWorkers is the number of concurrent requests made by
+// RequestAnimeBatch.
+const animeBatchWorkers = 2
+
+// An AnimeResult is the result of requesting one anime in
+// RequestAnimeBatch.
+type AnimeResult struct {
+	AID   int
+	Anime *Anime
+	Err   error
+}
+
+// RequestAnimeBatch requests anime information for multiple anime
+// from AniDB.
+// Requests are made by a small number of concurrent workers, each
+// respecting the client Limiter.
+// Results are sent on the returned channel as they complete, in no
+// particular order.
+// The channel is closed after all results are sent.
+//
+// To stop early, cancel ctx; the workers then stop, the remaining
+// results are not sent, and the channel is closed.
+// Otherwise, the caller must receive all of the results.
+func (c *Client) RequestAnimeBatch(ctx context.Context, aids []int) <-chan AnimeResult {
+	in := make(chan int)
+	out := make(chan AnimeResult)
+	var wg sync.WaitGroup
+	for i := 0; i < animeBatchWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for aid := range in {
+				a, err := c.RequestAnimeContext(ctx, aid)
+				select {
+				case out <- AnimeResult{AID: aid, Anime: a, Err: err}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		defer func() {
+			close(in)
+			wg.Wait()
+			close(out)
+		}()
+		for _, aid := range aids {
+			select {
+			case in <- aid:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
 }
 
 // RequestAnime requests anime information from AniDB.
@@ -109,12 +277,133 @@ func RequestAnime(c Client, aid int) (*Anime, error) {
 // HTTP API.
 type Anime struct {
 	AID          int       `xml:"id,attr"`
+	Restricted   bool      `xml:"restricted,attr"`
 	Titles       []Title   `xml:"titles>title"`
 	Type         string    `xml:"type"`
 	EpisodeCount int       `xml:"episodecount"`
 	StartDate    string    `xml:"startdate"`
 	EndDate      string    `xml:"enddate"`
 	Episodes     []Episode `xml:"episodes>episode"`
+
+	RelatedAnime    []RelatedAnime    `xml:"relatedanime>anime"`
+	SimilarAnime    []SimilarAnimeRef `xml:"similaranime>anime"`
+	Recommendations []Recommendation  `xml:"recommendations>recommendation"`
+	URL             string            `xml:"url"`
+	Creators        []Creator         `xml:"creators>name"`
+	Description     string            `xml:"description"`
+	PermanentRating Rating            `xml:"ratings>permanent"`
+	TemporaryRating Rating            `xml:"ratings>temporary"`
+	ReviewRating    Rating            `xml:"ratings>review"`
+	Picture         string            `xml:"picture"`
+	Resources       []Resource        `xml:"resources>resource"`
+	Tags            []Tag             `xml:"tags>tag"`
+	Characters      []Character       `xml:"characters>character"`
+}
+
+// A RelatedAnime holds information for a related anime returned from
+// the AniDB HTTP API.
+type RelatedAnime struct {
+	AID int `xml:"id,attr"`
+	// Type is the relation type, like "Sequel".
+	Type  string `xml:"type,attr"`
+	Title string `xml:",chardata"`
+}
+
+// A SimilarAnimeRef holds information for a similar anime returned
+// from the AniDB HTTP API.
+type SimilarAnimeRef struct {
+	AID int `xml:"id,attr"`
+	// Approval is the number of users who approved the similarity,
+	// out of Total.
+	Approval int    `xml:"approval,attr"`
+	Total    int    `xml:"total,attr"`
+	Title    string `xml:",chardata"`
+}
+
+// A Recommendation holds a user recommendation returned from the
+// AniDB HTTP API.
+type Recommendation struct {
+	// Type is the recommendation type, like "Must See".
+	Type string `xml:"type,attr"`
+	UID  int    `xml:"uid,attr"`
+	Text string `xml:",chardata"`
+}
+
+// A Creator holds information for a creator returned from the AniDB
+// HTTP API.
+type Creator struct {
+	ID int `xml:"id,attr"`
+	// Type is the creator role, like "Direction".
+	Type string `xml:"type,attr"`
+	Name string `xml:",chardata"`
+}
+
+// A Resource holds external resources of one type returned from the
+// AniDB HTTP API.
+type Resource struct {
+	Type     int              `xml:"type,attr"`
+	Entities []ExternalEntity `xml:"externalentity"`
+}
+
+// An ExternalEntity holds an external resource returned from the AniDB
+// HTTP API.
+// Depending on the resource type, it has identifiers or a URL.
+type ExternalEntity struct {
+	Identifiers []string `xml:"identifier"`
+	URL         string   `xml:"url"`
+}
+
+// A Tag holds information for an anime tag returned from the AniDB
+// HTTP API.
+type Tag struct {
+	ID            int    `xml:"id,attr"`
+	ParentID      int    `xml:"parentid,attr"`
+	Weight        int    `xml:"weight,attr"`
+	LocalSpoiler  bool   `xml:"localspoiler,attr"`
+	GlobalSpoiler bool   `xml:"globalspoiler,attr"`
+	Verified      bool   `xml:"verified,attr"`
+	Update        string `xml:"update,attr"`
+	Name          string `xml:"name"`
+	Description   string `xml:"description"`
+	PicURL        string `xml:"picurl"`
+}
+
+// A Character holds information for a character returned from the
+// AniDB HTTP API.
+type Character struct {
+	ID int `xml:"id,attr"`
+	// Type is the character role, like "main character in".
+	Type          string        `xml:"type,attr"`
+	Update        string        `xml:"update,attr"`
+	Rating        VoteRating    `xml:"rating"`
+	Name          string        `xml:"name"`
+	Gender        string        `xml:"gender"`
+	CharacterType CharacterType `xml:"charactertype"`
+	Description   string        `xml:"description"`
+	Picture       string        `xml:"picture"`
+	Seiyuu        []Seiyuu      `xml:"seiyuu"`
+}
+
+// A CharacterType holds a character type returned from the AniDB HTTP
+// API.
+type CharacterType struct {
+	ID   int    `xml:"id,attr"`
+	Name string `xml:",chardata"`
+}
+
+// A Seiyuu holds information for a voice actor returned from the
+// AniDB HTTP API.
+type Seiyuu struct {
+	ID      int    `xml:"id,attr"`
+	Picture string `xml:"picture,attr"`
+	Name    string `xml:",chardata"`
+}
+
+// A VoteRating holds a rating with a vote count returned from the
+// AniDB HTTP API.
+type VoteRating struct {
+	Value float64 `xml:",chardata"`
+	Votes int     `xml:"votes,attr"`
 }
 
 // A Title holds information for a single anime title returned from
@@ -134,8 +423,10 @@ type Episode struct {
 	// as a unique identifier.
 	EpNo string `xml:"epno"`
 	// Length is the length of the episode in minutes.
-	Length int       `xml:"length"`
-	Titles []EpTitle `xml:"title"`
+	Length  int        `xml:"length"`
+	AirDate string     `xml:"airdate"`
+	Rating  VoteRating `xml:"rating"`
+	Titles  []EpTitle  `xml:"title"`
 }
 
 // An EpTitle holds information for a single episode title returned
@@ -0,0 +1,176 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package anidb
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+)
+
+// An AnimeSummary holds summary information for an anime returned in
+// lists from the AniDB HTTP API.
+type AnimeSummary struct {
+	AID          int    `xml:"id,attr"`
+	Restricted   bool   `xml:"restricted,attr"`
+	EpisodeCount int    `xml:"episodecount"`
+	StartDate    string `xml:"startdate"`
+	EndDate      string `xml:"enddate"`
+	// Title is the main title.
+	Title   Title  `xml:"title"`
+	Picture string `xml:"picture"`
+	// PermanentRating and TemporaryRating are the permanent and
+	// temporary ratings.
+	PermanentRating Rating `xml:"ratings>permanent"`
+	TemporaryRating Rating `xml:"ratings>temporary"`
+	ReviewRating    Rating `xml:"ratings>review"`
+}
+
+// A Rating holds a rating returned from the AniDB HTTP API.
+type Rating struct {
+	Value float64 `xml:",chardata"`
+	Count int     `xml:"count,attr"`
+}
+
+// A SimilarPair holds a pair of similar anime returned from the AniDB
+// HTTP API.
+type SimilarPair struct {
+	Source SimilarAnime `xml:"source"`
+	Target SimilarAnime `xml:"target"`
+}
+
+// A SimilarAnime holds information for an anime in a SimilarPair.
+type SimilarAnime struct {
+	AID        int    `xml:"aid,attr"`
+	Restricted bool   `xml:"restricted,attr"`
+	Title      string `xml:"title"`
+	Picture    string `xml:"picture"`
+}
+
+// A MainPage holds the main page data returned from the AniDB HTTP
+// API, which combines hot anime, random similar anime and random
+// recommendations.
+type MainPage struct {
+	HotAnime      []AnimeSummary `xml:"hotanime>anime"`
+	RandomSimilar []SimilarPair  `xml:"randomsimilar>similar"`
+	// RandomRecommendations is only returned when requested with
+	// user credentials.
+	RandomRecommendations []AnimeSummary `xml:"randomrecommendation>recommendation>anime"`
+}
+
+// RequestHotAnime requests the currently popular anime from AniDB.
+// See [Client.ListCache] for caching.
+func (c *Client) RequestHotAnime(ctx context.Context) ([]AnimeSummary, error) {
+	d, err := c.listAPI(ctx, "hotanime", map[string]string{
+		"request": "hotanime",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("anidb request hot anime: %s", err)
+	}
+	var r struct {
+		Anime []AnimeSummary `xml:"anime"`
+	}
+	if err := xml.Unmarshal(d, &r); err != nil {
+		return nil, fmt.Errorf("anidb request hot anime: %s", err)
+	}
+	return r.Anime, nil
+}
+
+// RequestMain requests the main page data from AniDB.
+// user and pass are optional, and are needed for the random
+// recommendations.
+// See [Client.ListCache] for caching.
+func (c *Client) RequestMain(ctx context.Context, user, pass string) (*MainPage, error) {
+	params := map[string]string{
+		"request": "main",
+	}
+	key := "main"
+	if user != "" {
+		params["user"] = user
+		params["pass"] = pass
+		key = "main-" + user
+	}
+	d, err := c.listAPI(ctx, key, params)
+	if err != nil {
+		return nil, fmt.Errorf("anidb request main: %s", err)
+	}
+	var r MainPage
+	if err := xml.Unmarshal(d, &r); err != nil {
+		return nil, fmt.Errorf("anidb request main: %s", err)
+	}
+	return &r, nil
+}
+
+// listAPI makes an HTTP API request for a list, using
+// [Client.ListCache] with the given key.
+func (c *Client) listAPI(ctx context.Context, key string, params map[string]string) ([]byte, error) {
+	if c.ListCache != nil {
+		d, ok, err := c.ListCache.Get(key)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return d, nil
+		}
+	}
+	d, err := c.httpAPI(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	if c.ListCache != nil {
+		if err := c.ListCache.Put(key, d); err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+// RequestRandomRecommendation requests random anime recommendations
+// for a user from AniDB.
+func (c *Client) RequestRandomRecommendation(ctx context.Context, user, pass string) ([]AnimeSummary, error) {
+	d, err := c.httpAPI(ctx, map[string]string{
+		"request": "randomrecommendation",
+		"user":    user,
+		"pass":    pass,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("anidb request random recommendation: %s", err)
+	}
+	var r struct {
+		Anime []AnimeSummary `xml:"recommendation>anime"`
+	}
+	if err := xml.Unmarshal(d, &r); err != nil {
+		return nil, fmt.Errorf("anidb request random recommendation: %s", err)
+	}
+	return r.Anime, nil
+}
+
+// RequestRandomSimilar requests random pairs of similar anime from
+// AniDB.
+func (c *Client) RequestRandomSimilar(ctx context.Context) ([]SimilarPair, error) {
+	d, err := c.httpAPI(ctx, map[string]string{
+		"request": "randomsimilar",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("anidb request random similar: %s", err)
+	}
+	var r struct {
+		Pairs []SimilarPair `xml:"similar"`
+	}
+	if err := xml.Unmarshal(d, &r); err != nil {
+		return nil, fmt.Errorf("anidb request random similar: %s", err)
+	}
+	return r.Pairs, nil
+}
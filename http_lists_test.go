@@ -0,0 +1,209 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package anidb
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newXMLServer(t *testing.T, body string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestClient_RequestHotAnime(t *testing.T) {
+	t.Parallel()
+	srv := newXMLServer(t, `<hotanime>
+<anime id="8076" restricted="false">
+<episodecount>13</episodecount>
+<startdate>2010-07-04</startdate>
+<enddate>2010-09-26</enddate>
+<title xml:lang="x-jat" type="main">Seitokai Yakuindomo</title>
+<picture>12345.jpg</picture>
+<ratings><permanent count="100">7.50</permanent><temporary count="120">7.60</temporary></ratings>
+</anime>
+</hotanime>`)
+	c := Client{Name: "test", Version: 1, BaseURL: srv.URL}
+	got, err := c.RequestHotAnime(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []AnimeSummary{{
+		AID:             8076,
+		EpisodeCount:    13,
+		StartDate:       "2010-07-04",
+		EndDate:         "2010-09-26",
+		Title:           Title{Name: "Seitokai Yakuindomo", Type: "main", Lang: "x-jat"},
+		Picture:         "12345.jpg",
+		PermanentRating: Rating{Value: 7.5, Count: 100},
+		TemporaryRating: Rating{Value: 7.6, Count: 120},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %#v; want %#v", got, want)
+	}
+}
+
+func TestClient_RequestRandomRecommendation(t *testing.T) {
+	t.Parallel()
+	srv := newXMLServer(t, `<randomrecommendation>
+<recommendation><anime id="1" restricted="false"><episodecount>12</episodecount></anime></recommendation>
+<recommendation><anime id="2" restricted="true"><episodecount>24</episodecount></anime></recommendation>
+</randomrecommendation>`)
+	c := Client{Name: "test", Version: 1, BaseURL: srv.URL}
+	got, err := c.RequestRandomRecommendation(context.Background(), "user", "pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []AnimeSummary{
+		{AID: 1, EpisodeCount: 12},
+		{AID: 2, Restricted: true, EpisodeCount: 24},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %#v; want %#v", got, want)
+	}
+}
+
+func TestClient_RequestRandomSimilar(t *testing.T) {
+	t.Parallel()
+	srv := newXMLServer(t, `<randomsimilar>
+<similar>
+<source aid="1" restricted="false"><title>Foo</title><picture>1.jpg</picture></source>
+<target aid="2" restricted="false"><title>Bar</title><picture>2.jpg</picture></target>
+</similar>
+</randomsimilar>`)
+	c := Client{Name: "test", Version: 1, BaseURL: srv.URL}
+	got, err := c.RequestRandomSimilar(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []SimilarPair{{
+		Source: SimilarAnime{AID: 1, Title: "Foo", Picture: "1.jpg"},
+		Target: SimilarAnime{AID: 2, Title: "Bar", Picture: "2.jpg"},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %#v; want %#v", got, want)
+	}
+}
+
+func TestClient_RequestMain(t *testing.T) {
+	t.Parallel()
+	var gotUser string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUser = r.URL.Query().Get("user")
+		fmt.Fprint(w, `<main>
+<hotanime><anime id="8076" restricted="false"><episodecount>13</episodecount></anime></hotanime>
+<randomsimilar><similar>
+<source aid="1" restricted="false"><title>Foo</title></source>
+<target aid="2" restricted="false"><title>Bar</title></target>
+</similar></randomsimilar>
+<randomrecommendation><recommendation><anime id="3" restricted="false"><episodecount>12</episodecount></anime></recommendation></randomrecommendation>
+</main>`)
+	}))
+	t.Cleanup(srv.Close)
+	c := Client{Name: "test", Version: 1, BaseURL: srv.URL}
+	got, err := c.RequestMain(context.Background(), "user", "pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotUser != "user" {
+		t.Errorf("Got user %q; want %q", gotUser, "user")
+	}
+	want := &MainPage{
+		HotAnime: []AnimeSummary{{AID: 8076, EpisodeCount: 13}},
+		RandomSimilar: []SimilarPair{{
+			Source: SimilarAnime{AID: 1, Title: "Foo"},
+			Target: SimilarAnime{AID: 2, Title: "Bar"},
+		}},
+		RandomRecommendations: []AnimeSummary{{AID: 3, EpisodeCount: 12}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %#v; want %#v", got, want)
+	}
+}
+
+func TestClient_ListCache(t *testing.T) {
+	t.Parallel()
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprint(w, `<hotanime><anime id="1" restricted="false"></anime></hotanime>`)
+	}))
+	t.Cleanup(srv.Close)
+	c := Client{
+		Name:      "test",
+		Version:   1,
+		BaseURL:   srv.URL,
+		ListCache: &ListCache{Dir: t.TempDir()},
+	}
+	for i := 0; i < 2; i++ {
+		got, err := c.RequestHotAnime(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 1 || got[0].AID != 1 {
+			t.Errorf("Got %#v; want anime 1", got)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("Got %d requests; want 1", requests)
+	}
+}
+
+func TestListCache(t *testing.T) {
+	t.Parallel()
+	c := &ListCache{Dir: filepath.Join(t.TempDir(), "lists"), TTL: time.Hour}
+	if _, ok, err := c.Get("main-a/b"); err != nil || ok {
+		t.Fatalf("Got ok %v, error %v; want not ok", ok, err)
+	}
+	const raw = `<main></main>`
+	if err := c.Put("main-a/b", []byte(raw)); err != nil {
+		t.Fatal(err)
+	}
+	d, ok, err := c.Get("main-a/b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok || string(d) != raw {
+		t.Errorf("Got %q, %v; want cached list", d, ok)
+	}
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(c.path("main-a/b"), old, old); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, err := c.Get("main-a/b"); err != nil || ok {
+		t.Errorf("Got ok %v, error %v for stale list; want not ok", ok, err)
+	}
+}
+
+func TestClient_RequestHotAnime_apiError(t *testing.T) {
+	t.Parallel()
+	srv := newXMLServer(t, `<error>Banned</error>`)
+	c := Client{Name: "test", Version: 1, BaseURL: srv.URL}
+	if _, err := c.RequestHotAnime(context.Background()); err == nil {
+		t.Errorf("Expected error")
+	}
+}
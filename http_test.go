@@ -15,9 +15,17 @@
 package anidb
 
 import (
+	"context"
+	"fmt"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestDecodeAnime(t *testing.T) {
@@ -31,9 +39,11 @@ func TestDecodeAnime(t *testing.T) {
 	}
 	e := []Episode{
 		{
-			EID:    113,
-			EpNo:   "1",
-			Length: 25,
+			EID:     113,
+			EpNo:    "1",
+			Length:  25,
+			AirDate: "1995-10-04",
+			Rating:  VoteRating{Value: 5.91, Votes: 51},
 			Titles: []EpTitle{
 				{Title: "使徒, 襲来", Lang: "ja"},
 				{Title: "Angel Attack!", Lang: "en"},
@@ -60,6 +70,64 @@ func TestDecodeAnime(t *testing.T) {
 			{Name: "Neon Genesis Evangelion", Type: "official", Lang: "en"},
 		},
 		Episodes: e,
+		RelatedAnime: []RelatedAnime{
+			{AID: 202, Type: "Sequel", Title: "Shinseiki Evangelion Gekijouban: The End of Evangelion"},
+		},
+		SimilarAnime: []SimilarAnimeRef{
+			{AID: 4861, Approval: 40, Total: 68, Title: "Bokura no"},
+			{AID: 8069, Approval: 21, Total: 48, Title: "Mahou Shoujo Madoka Magica"},
+		},
+		Recommendations: []Recommendation{
+			{Type: "Recommended", UID: 143269, Text: "nothing to say"},
+			{Type: "Must See", UID: 269092, Text: "Sublime"},
+		},
+		URL: "http://www.gainax.co.jp/anime/eva/",
+		Creators: []Creator{
+			{ID: 57, Type: "Direction", Name: "Anno Hideaki"},
+			{ID: 1955, Type: "Music", Name: "Sagisu Shirou"},
+		},
+		PermanentRating: Rating{Value: 7.72, Count: 13944},
+		TemporaryRating: Rating{Value: 8.27, Count: 14292},
+		ReviewRating:    Rating{Value: 8.08, Count: 30},
+		Picture:         "133461.jpg",
+		Resources: []Resource{
+			{Type: 1, Entities: []ExternalEntity{{Identifiers: []string{"49"}}}},
+			{Type: 4, Entities: []ExternalEntity{{URL: "http://www.gainax.co.jp/anime/eva/"}}},
+		},
+		Tags: []Tag{
+			{
+				ID:          520,
+				ParentID:    6149,
+				Update:      "2014-10-14",
+				Name:        "nopan",
+				Description: "The character foregoes underwear.",
+				PicURL:      "162753.jpg",
+			},
+		},
+		Characters: []Character{
+			{
+				ID:            310,
+				Type:          "main character in",
+				Update:        "2016-03-02",
+				Rating:        VoteRating{Value: 7.92, Votes: 1481},
+				Name:          "Ayanami Rei",
+				Gender:        "female",
+				CharacterType: CharacterType{ID: 1, Name: "Character"},
+				Picture:       "59479.png",
+				Seiyuu:        []Seiyuu{{ID: 13, Picture: "16583.jpg", Name: "Hayashibara Megumi"}},
+			},
+		},
+	}
+	// Descriptions are long, so only check their start.
+	if !strings.HasPrefix(a.Description, "In the year 2015, the Angels") {
+		t.Errorf("Got description %q", a.Description)
+	}
+	a.Description = ""
+	if len(a.Characters) > 0 {
+		if !strings.HasPrefix(a.Characters[0].Description, "The First Child") {
+			t.Errorf("Got character description %q", a.Characters[0].Description)
+		}
+		a.Characters[0].Description = ""
 	}
 	if !reflect.DeepEqual(a, exp) {
 		t.Errorf("Expected %#v, got %#v", exp, a)
@@ -87,3 +155,161 @@ func TestCheckAPIErrorGood(t *testing.T) {
 		t.Errorf("Got unexpected error %+v", err)
 	}
 }
+
+func TestClient_Validate(t *testing.T) {
+	c := Client{}
+	err := c.Validate()
+	if err == nil {
+		t.Fatal("Expected error")
+	}
+	for _, want := range []string{"missing client name", "invalid client version 0"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Got error %q; want it to contain %q", err, want)
+		}
+	}
+}
+
+func TestRequestAnimeBatch(t *testing.T) {
+	var mu sync.Mutex
+	var active, maxActive int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		active++
+		maxActive = max(maxActive, active)
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		active--
+		mu.Unlock()
+		fmt.Fprintf(w, `<anime id="%s"></anime>`, r.URL.Query().Get("aid"))
+	}))
+	t.Cleanup(srv.Close)
+	l := &countLimiter{}
+	c := Client{Name: "test", Version: 1, Limiter: l, BaseURL: srv.URL}
+	aids := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	var got []int
+	for r := range c.RequestAnimeBatch(context.Background(), aids) {
+		if r.Err != nil {
+			t.Errorf("aid %d: %s", r.AID, r.Err)
+			continue
+		}
+		if r.Anime.AID != r.AID {
+			t.Errorf("Got anime %d for aid %d", r.Anime.AID, r.AID)
+		}
+		got = append(got, r.AID)
+	}
+	sort.Ints(got)
+	if !reflect.DeepEqual(got, aids) {
+		t.Errorf("Got aids %v; want %v", got, aids)
+	}
+	if maxActive > animeBatchWorkers {
+		t.Errorf("Got %d concurrent requests; want at most %d", maxActive, animeBatchWorkers)
+	}
+	if n := l.count(); n != len(aids) {
+		t.Errorf("Got %d limiter waits; want %d", n, len(aids))
+	}
+}
+
+func TestRequestAnimeBatch_canceled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<anime id="%s"></anime>`, r.URL.Query().Get("aid"))
+	}))
+	t.Cleanup(srv.Close)
+	c := Client{Name: "test", Version: 1, Limiter: nopLimiter{}, BaseURL: srv.URL}
+	ctx, cf := context.WithCancel(context.Background())
+	defer cf()
+	aids := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	ch := c.RequestAnimeBatch(ctx, aids)
+	<-ch
+	// Stop receiving early; the channel is still closed.
+	cf()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case _, ok := <-ch:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("Channel not closed after cancel")
+		}
+	}
+}
+
+func TestRequestAnimeContext_canceled(t *testing.T) {
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { close(done) })
+	c := Client{Name: "test", Version: 1, BaseURL: srv.URL}
+	ctx, cf := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cf()
+	if _, err := c.RequestAnimeContext(ctx, 1); err == nil {
+		t.Errorf("Expected error")
+	}
+}
+
+func TestClient_httpOptions(t *testing.T) {
+	var gotUA string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUA = r.UserAgent()
+		fmt.Fprint(w, `<anime id="1"></anime>`)
+	}))
+	t.Cleanup(srv.Close)
+	c := Client{
+		Name:       "test",
+		Version:    1,
+		HTTPClient: srv.Client(),
+		BaseURL:    srv.URL,
+		UserAgent:  "test/1",
+	}
+	if _, err := c.RequestAnime(1); err != nil {
+		t.Fatal(err)
+	}
+	if gotUA != "test/1" {
+		t.Errorf("Got User-Agent %q; want %q", gotUA, "test/1")
+	}
+}
+
+func TestClient_RequestAnimeRaw(t *testing.T) {
+	const body = `<anime id="1"><unknown>x</unknown></anime>`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(srv.Close)
+	c := Client{Name: "test", Version: 1, BaseURL: srv.URL}
+	a, raw, err := c.RequestAnimeRaw(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.AID != 1 {
+		t.Errorf("Got aid %d; want 1", a.AID)
+	}
+	if string(raw) != body {
+		t.Errorf("Got raw %q; want %q", raw, body)
+	}
+}
+
+// A countLimiter is a Limiter that counts calls to Wait.
+type countLimiter struct {
+	mu sync.Mutex
+	n  int
+}
+
+func (l *countLimiter) Wait(context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.n++
+	return nil
+}
+
+func (l *countLimiter) count() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.n
+}
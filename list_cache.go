@@ -0,0 +1,75 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package anidb
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"time"
+)
+
+// DefaultListTTL is the default TTL for [ListCache].
+const DefaultListTTL = time.Hour
+
+// A ListCache is an on-disk cache for list data from the HTTP API,
+// like hot anime and the main page, keyed by request.
+// These requests are meant for periodic polling, so caching them
+// avoids polling more often than needed.
+// The original XML is cached.
+//
+// See [Client.ListCache] for using the cache transparently.
+type ListCache struct {
+	// Dir is the directory for cache files.
+	Dir string
+	// TTL is how long cached lists are fresh.
+	// If zero, [DefaultListTTL] is used.
+	TTL time.Duration
+}
+
+// DefaultListCache returns a ListCache at a default location,
+// in [CacheDir].
+func DefaultListCache() *ListCache {
+	return &ListCache{Dir: filepath.Join(cacheDir(), "lists")}
+}
+
+// Get gets the XML for a list from the cache.
+// ok is false if the list is not cached or the cached list is stale.
+func (c *ListCache) Get(key string) (raw []byte, ok bool, _ error) {
+	d, ok, err := readCacheFile(c.path(key), c.ttl())
+	if err != nil {
+		return nil, false, fmt.Errorf("list cache get %s: %s", key, err)
+	}
+	return d, ok, nil
+}
+
+// Put stores the XML for a list in the cache.
+func (c *ListCache) Put(key string, raw []byte) error {
+	if err := writeCacheFile(c.Dir, c.path(key), raw); err != nil {
+		return fmt.Errorf("list cache put %s: %s", key, err)
+	}
+	return nil
+}
+
+func (c *ListCache) path(key string) string {
+	return filepath.Join(c.Dir, url.PathEscape(key)+".xml")
+}
+
+func (c *ListCache) ttl() time.Duration {
+	if c.TTL > 0 {
+		return c.TTL
+	}
+	return DefaultListTTL
+}
@@ -0,0 +1,319 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package anidb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+
+	"go.felesatra.moe/anidb/ed2k"
+	"go.felesatra.moe/anidb/udpapi"
+	"go.felesatra.moe/anidb/udpapi/codes"
+)
+
+// A MylistItem is a local file to sync to mylist.
+type MylistItem struct {
+	// FID identifies the file.
+	// If zero, File is used instead.
+	FID int
+	// File identifies the file by size and ed2k hash.
+	File ed2k.File
+	// Watched is the local watched state.
+	Watched bool
+}
+
+func (i MylistItem) key() string {
+	if i.FID != 0 {
+		return "fid:" + strconv.Itoa(i.FID)
+	}
+	return "ed2k:" + fileKey(i.File)
+}
+
+func (i MylistItem) String() string {
+	if i.FID != 0 {
+		return "fid " + strconv.Itoa(i.FID)
+	}
+	return "ed2k " + fileKey(i.File)
+}
+
+// A MylistSyncError is an error syncing one item.
+type MylistSyncError struct {
+	Item MylistItem
+	Err  error
+}
+
+func (e *MylistSyncError) Error() string {
+	return fmt.Sprintf("mylist sync %s: %s", e.Item, e.Err)
+}
+
+func (e *MylistSyncError) Unwrap() error {
+	return e.Err
+}
+
+// A MylistSyncResult summarizes a mylist sync.
+type MylistSyncResult struct {
+	// Added is the number of entries added to mylist.
+	Added int
+	// Updated is the number of entries whose watched state was
+	// updated.
+	Updated int
+	// Unchanged is the number of items that were already in sync,
+	// including items completed by a previous interrupted sync.
+	Unchanged int
+	// Deleted is the number of orphaned entries deleted.
+	Deleted int
+	// Errors contains the errors for items that failed to sync.
+	// Failed items are retried by the next sync.
+	Errors []*MylistSyncError
+}
+
+// A MylistSync reconciles a set of local files with mylist.
+//
+// Items missing from mylist are added, and the watched state of
+// existing entries is updated to match the local state.
+// Requests are made one at a time using the client, which handles
+// rate limiting.
+//
+// If StatePath is set, the entries known to be in sync are stored
+// there after each item, so an interrupted sync can be resumed
+// without repeating requests, and so entries added by earlier syncs
+// can be found to delete orphans.
+type MylistSync struct {
+	// Client is the UDP API client, which must be logged in.
+	Client *udpapi.Client
+	// Options are used when adding entries.
+	// Viewed is set from each item's watched state.
+	Options udpapi.MylistAddOptions
+	// DeleteOrphans deletes entries added by earlier syncs for items
+	// that are no longer present.
+	// Entries not added by a sync with the same StatePath are never
+	// deleted.
+	DeleteOrphans bool
+	// StatePath is an optional file for storing sync progress.
+	StatePath string
+	// Progress, if set, is called after each item with the number of
+	// items done and the total.
+	Progress func(done, total int)
+
+	mu     sync.Mutex
+	loaded bool
+	state  mylistSyncState
+}
+
+type mylistSyncState struct {
+	// Entries is keyed by MylistItem.key.
+	Entries map[string]mylistSyncEntry `json:"entries"`
+}
+
+type mylistSyncEntry struct {
+	LID     int  `json:"lid"`
+	Watched bool `json:"watched"`
+}
+
+type mylistSyncAction int
+
+const (
+	syncUnchanged mylistSyncAction = iota
+	syncAdded
+	syncUpdated
+)
+
+// Sync reconciles items with mylist.
+// Errors for individual items are returned in the result.
+// The returned error is only set if the sync could not continue,
+// such as if ctx is done or the state file could not be written.
+func (s *MylistSync) Sync(ctx context.Context, items []MylistItem) (MylistSyncResult, error) {
+	var res MylistSyncResult
+	s.mu.Lock()
+	err := s.load()
+	s.mu.Unlock()
+	if err != nil {
+		return res, err
+	}
+	var fatal error
+	o := udpapi.BatchOptions{Progress: s.Progress}
+	br := udpapi.BatchWith(ctx, items, o, func(ctx context.Context, i MylistItem) (mylistSyncAction, error) {
+		if fatal != nil {
+			return 0, fatal
+		}
+		a, e, err := s.syncItem(ctx, i)
+		if err == nil {
+			if err = s.put(i.key(), e); err != nil {
+				fatal = err
+			}
+		} else if ctx.Err() != nil {
+			fatal = ctx.Err()
+		}
+		return a, err
+	})
+	for i, r := range br {
+		if err := r.Err; err != nil {
+			res.Errors = append(res.Errors, &MylistSyncError{Item: items[i], Err: err})
+			continue
+		}
+		switch r.Value {
+		case syncAdded:
+			res.Added++
+		case syncUpdated:
+			res.Updated++
+		default:
+			res.Unchanged++
+		}
+	}
+	if fatal == nil {
+		fatal = ctx.Err()
+	}
+	if fatal != nil {
+		return res, fmt.Errorf("anidb mylist sync: %w", fatal)
+	}
+	if s.DeleteOrphans {
+		n, err := s.deleteOrphans(ctx, items)
+		res.Deleted = n
+		if err != nil {
+			return res, fmt.Errorf("anidb mylist sync: %w", err)
+		}
+	}
+	return res, nil
+}
+
+// syncItem syncs one item, returning the entry to store.
+func (s *MylistSync) syncItem(ctx context.Context, i MylistItem) (mylistSyncAction, mylistSyncEntry, error) {
+	s.mu.Lock()
+	e, ok := s.state.Entries[i.key()]
+	s.mu.Unlock()
+	if ok && e.Watched == i.Watched {
+		return syncUnchanged, e, nil
+	}
+	o := s.Options
+	o.Viewed = &i.Watched
+	var r udpapi.MylistAddResult
+	var err error
+	if i.FID != 0 {
+		r, err = s.Client.MylistAdd(ctx, i.FID, o)
+	} else {
+		r, err = s.Client.MylistAddByHash(ctx, i.File.Size, i.File.String(), o)
+	}
+	if err != nil {
+		return 0, e, err
+	}
+	e = mylistSyncEntry{LID: r.LID, Watched: i.Watched}
+	if !r.AlreadyAdded {
+		return syncAdded, e, nil
+	}
+	if watched := !r.Existing.ViewDate.IsZero(); watched == i.Watched {
+		return syncUnchanged, e, nil
+	}
+	if _, err := s.Client.MylistSetWatched(ctx, r.LID, i.Watched); err != nil {
+		return 0, e, err
+	}
+	return syncUpdated, e, nil
+}
+
+// deleteOrphans deletes known entries that are not in items.
+func (s *MylistSync) deleteOrphans(ctx context.Context, items []MylistItem) (int, error) {
+	keep := make(map[string]bool, len(items))
+	for _, i := range items {
+		keep[i.key()] = true
+	}
+	s.mu.Lock()
+	var orphans []string
+	for k := range s.state.Entries {
+		if !keep[k] {
+			orphans = append(orphans, k)
+		}
+	}
+	s.mu.Unlock()
+	sort.Strings(orphans)
+	n := 0
+	for _, k := range orphans {
+		s.mu.Lock()
+		lid := s.state.Entries[k].LID
+		s.mu.Unlock()
+		_, err := s.Client.MylistDel(ctx, lid)
+		if err != nil && !errors.Is(err, codes.NO_SUCH_MYLIST_ENTRY) {
+			return n, err
+		}
+		if err := s.remove(k); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+func (s *MylistSync) put(k string, e mylistSyncEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if old, ok := s.state.Entries[k]; ok && old == e {
+		return nil
+	}
+	s.state.Entries[k] = e
+	return s.save()
+}
+
+func (s *MylistSync) remove(k string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.state.Entries, k)
+	return s.save()
+}
+
+// load loads the state file once.
+// s.mu must be held.
+func (s *MylistSync) load() error {
+	if s.loaded {
+		return nil
+	}
+	if s.StatePath != "" {
+		d, err := os.ReadFile(s.StatePath)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("mylist sync load: %w", err)
+		}
+		if err == nil {
+			if err := json.Unmarshal(d, &s.state); err != nil {
+				return fmt.Errorf("mylist sync load: %s", err)
+			}
+		}
+	}
+	if s.state.Entries == nil {
+		s.state.Entries = make(map[string]mylistSyncEntry)
+	}
+	s.loaded = true
+	return nil
+}
+
+// save saves the state file.
+// s.mu must be held.
+func (s *MylistSync) save() error {
+	if s.StatePath == "" {
+		return nil
+	}
+	d, err := json.Marshal(s.state)
+	if err != nil {
+		return fmt.Errorf("mylist sync save: %w", err)
+	}
+	if err := writeCacheFile(filepath.Dir(s.StatePath), s.StatePath, d); err != nil {
+		return fmt.Errorf("mylist sync save: %w", err)
+	}
+	return nil
+}
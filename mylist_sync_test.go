@@ -0,0 +1,122 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package anidb
+
+import (
+	"context"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.felesatra.moe/anidb/ed2k"
+	"go.felesatra.moe/anidb/udpapi"
+	"go.felesatra.moe/anidb/udpapi/udptest"
+)
+
+func TestMylistSync(t *testing.T) {
+	t.Parallel()
+	ctx, cf := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cf)
+	s, err := udptest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	s.AddUser("ionasal", "pass")
+	f3 := ed2k.File{Size: 3, Hash: [ed2k.Size]byte{3}}
+	s.AddFile(udptest.File{FID: 1})
+	s.AddFile(udptest.File{FID: 2})
+	s.AddFile(udptest.File{FID: 3, Size: f3.Size, ED2K: f3.String()})
+	s.Handle("MYLISTDEL", func(udptest.Request) string {
+		return "211 MYLIST ENTRY DELETED\n1"
+	})
+	c, err := udpapi.Dial(s.Addr, slog.New(slog.NewTextHandler(discard{}, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	c.SetLimiter(nopLimiter{})
+	if _, err := c.Auth(ctx, udpapi.UserInfo{UserName: "ionasal", UserPassword: "pass"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.MylistAdd(ctx, 2, udpapi.MylistAddOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "sync.json")
+	items := []MylistItem{
+		{FID: 1},
+		{FID: 2, Watched: true},
+		{File: f3},
+		{FID: 4},
+	}
+	var progress int
+	ms := &MylistSync{
+		Client:    c,
+		StatePath: path,
+		Progress:  func(done, total int) { progress = done },
+	}
+	res, err := ms.Sync(ctx, items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Added != 2 || res.Updated != 1 || res.Unchanged != 0 {
+		t.Errorf("Got %+v; want 2 added, 1 updated", res)
+	}
+	if len(res.Errors) != 1 || res.Errors[0].Item.FID != 4 {
+		t.Errorf("Got errors %v; want error for fid 4", res.Errors)
+	}
+	if progress != len(items) {
+		t.Errorf("Got progress %d; want %d", progress, len(items))
+	}
+
+	// A new sync resumes from the state file.
+	n := len(s.Requests())
+	ms = &MylistSync{
+		Client:        c,
+		StatePath:     path,
+		DeleteOrphans: true,
+	}
+	res, err = ms.Sync(ctx, items[:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := MylistSyncResult{Unchanged: 2, Deleted: 1}
+	if res.Added != want.Added || res.Updated != want.Updated || res.Unchanged != want.Unchanged || res.Deleted != want.Deleted || len(res.Errors) != 0 {
+		t.Errorf("Got %+v; want %+v", res, want)
+	}
+	var cmds []string
+	for _, r := range s.Requests()[n:] {
+		cmds = append(cmds, r.Cmd)
+	}
+	if len(cmds) != 1 || cmds[0] != "MYLISTDEL" {
+		t.Errorf("Got requests %q; want only MYLISTDEL", cmds)
+	}
+}
+
+func TestMylistSync_canceled(t *testing.T) {
+	t.Parallel()
+	ctx, cf := context.WithCancel(context.Background())
+	cf()
+	ms := &MylistSync{}
+	res, err := ms.Sync(ctx, []MylistItem{{FID: 1}, {FID: 2}})
+	if err == nil {
+		t.Errorf("Got nil error; want error")
+	}
+	if len(res.Errors) != 2 {
+		t.Errorf("Got errors %v; want 2 errors", res.Errors)
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mylistexport
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"go.felesatra.moe/anidb/udpapi"
+)
+
+// ParseCSV parses a mylist export generated with the csv template.
+// The first row is the header naming the fields.
+func ParseCSV(r io.Reader) ([]udpapi.MylistEntry, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("mylistexport ParseCSV: %w", err)
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+	var entries []udpapi.MylistEntry
+	for {
+		row, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("mylistexport ParseCSV: %w", err)
+		}
+		var b entryBuilder
+		for i, v := range row {
+			if i >= len(header) {
+				break
+			}
+			if err := b.set(header[i], v); err != nil {
+				line, _ := cr.FieldPos(i)
+				return nil, fmt.Errorf("mylistexport ParseCSV: line %d: %s", line, err)
+			}
+		}
+		entries = append(entries, b.e)
+	}
+	return entries, nil
+}
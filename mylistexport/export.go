@@ -0,0 +1,174 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mylistexport parses AniDB mylist exports.
+//
+// Mylist exports are requested from AniDB, such as with
+// [go.felesatra.moe/anidb/udpapi.Client.MylistExport], and delivered
+// as archives generated from a template.
+// This package reads exports generated with the xml-plain and csv
+// templates into [udpapi.MylistEntry] values, so tools can bootstrap
+// from an export instead of querying each entry over the UDP API.
+//
+// Fields are matched by name, ignoring case and underscores, so
+// templates with extra or reordered fields can be read.
+// The recognized fields are lid, fid, eid, aid, gid, date, state,
+// viewdate, storage, source, other and filestate.
+// Dates may be Unix timestamps or formatted dates.
+package mylistexport
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.felesatra.moe/anidb/udpapi"
+)
+
+// ReadArchive reads a gzipped tar mylist export archive.
+// Entries are read from the .xml and .csv files in the archive, in
+// order.
+// Other files are ignored.
+func ReadArchive(r io.Reader) ([]udpapi.MylistEntry, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("mylistexport ReadArchive: %w", err)
+	}
+	defer zr.Close()
+	tr := tar.NewReader(zr)
+	var entries []udpapi.MylistEntry
+	found := false
+	for {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("mylistexport ReadArchive: %w", err)
+		}
+		if h.Typeflag != tar.TypeReg {
+			continue
+		}
+		var e []udpapi.MylistEntry
+		switch strings.ToLower(path.Ext(h.Name)) {
+		case ".xml":
+			e, err = ParseXML(tr)
+		case ".csv":
+			e, err = ParseCSV(tr)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("mylistexport ReadArchive: %s: %w", h.Name, err)
+		}
+		found = true
+		entries = append(entries, e...)
+	}
+	if !found {
+		return nil, errors.New("mylistexport ReadArchive: no xml or csv files in archive")
+	}
+	return entries, nil
+}
+
+// An entryBuilder builds a mylist entry from named fields.
+type entryBuilder struct {
+	e udpapi.MylistEntry
+}
+
+// normalizeField normalizes a field name for matching.
+func normalizeField(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+}
+
+// set sets a field by name.
+// Unknown fields are ignored.
+func (b *entryBuilder) set(name, v string) error {
+	v = strings.TrimSpace(v)
+	var err error
+	switch normalizeField(name) {
+	case "lid":
+		b.e.LID, err = parseInt(v)
+	case "fid":
+		b.e.FID, err = parseInt(v)
+	case "eid":
+		b.e.EID, err = parseInt(v)
+	case "aid":
+		b.e.AID, err = parseInt(v)
+	case "gid":
+		b.e.GID, err = parseInt(v)
+	case "date":
+		b.e.Date, err = parseTime(v)
+	case "state":
+		var n int
+		n, err = parseInt(v)
+		b.e.State = udpapi.MylistState(n)
+	case "viewdate":
+		b.e.ViewDate, err = parseTime(v)
+	case "storage":
+		b.e.Storage = v
+	case "source":
+		b.e.Source = v
+	case "other":
+		b.e.Other = v
+	case "filestate":
+		b.e.FileState, err = parseInt(v)
+	default:
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("field %s: %s", name, err)
+	}
+	return nil
+}
+
+func parseInt(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(s)
+}
+
+// timeLayouts are the layouts tried for formatted dates.
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"02.01.2006 15:04",
+	"02.01.2006",
+}
+
+// parseTime parses a Unix timestamp or formatted date.
+// Empty and zero values are returned as the zero time.
+// Formatted dates without a time zone are assumed to be UTC.
+func parseTime(s string) (time.Time, error) {
+	if s == "" || s == "0" {
+		return time.Time{}, nil
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(n, 0), nil
+	}
+	for _, l := range timeLayouts {
+		if t, err := time.Parse(l, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", s)
+}
@@ -0,0 +1,133 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mylistexport
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.felesatra.moe/anidb/udpapi"
+)
+
+const testXML = `<?xml version="1.0" encoding="UTF-8"?>
+<myList>
+  <anime id="8076" name="Seitokai Yakuindomo">
+    <ep id="124523" epno="1">
+      <file id="312498" lid="1234" gid="4243" state="1" date="1600000000" viewdate="1600003600" filestate="0">
+        <storage>nas</storage>
+      </file>
+    </ep>
+    <ep id="124524" epno="2">
+      <file id="312499" lid="1235" gid="4243" state="1" date="2020-09-13 12:26" viewdate="0"/>
+    </ep>
+  </anime>
+</myList>
+`
+
+var testXMLEntries = []udpapi.MylistEntry{
+	{
+		LID: 1234, FID: 312498, EID: 124523, AID: 8076, GID: 4243,
+		Date:     time.Unix(1600000000, 0),
+		State:    udpapi.MylistHDD,
+		ViewDate: time.Unix(1600003600, 0),
+		Storage:  "nas",
+	},
+	{
+		LID: 1235, FID: 312499, EID: 124524, AID: 8076, GID: 4243,
+		Date:  time.Date(2020, 9, 13, 12, 26, 0, 0, time.UTC),
+		State: udpapi.MylistHDD,
+	},
+}
+
+const testCSV = "\ufeffLID,FID,EID,AID,GID,Date,State,View_Date,Storage,Source,Other,File_State,Extra\n" +
+	"1236,312500,124525,8076,4243,1600000000,2,0,,,\"a, b\",0,x\n"
+
+var testCSVEntries = []udpapi.MylistEntry{
+	{
+		LID: 1236, FID: 312500, EID: 124525, AID: 8076, GID: 4243,
+		Date:  time.Unix(1600000000, 0),
+		State: udpapi.MylistCD,
+		Other: "a, b",
+	},
+}
+
+func TestParseXML(t *testing.T) {
+	t.Parallel()
+	got, err := ParseXML(strings.NewReader(testXML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, testXMLEntries) {
+		t.Errorf("Got %#v; want %#v", got, testXMLEntries)
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	t.Parallel()
+	got, err := ParseCSV(strings.NewReader(testCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, testCSVEntries) {
+		t.Errorf("Got %#v; want %#v", got, testCSVEntries)
+	}
+}
+
+func TestParseCSV_badField(t *testing.T) {
+	t.Parallel()
+	if _, err := ParseCSV(strings.NewReader("lid,fid\n1,x\n")); err == nil {
+		t.Errorf("Expected error")
+	}
+}
+
+func TestReadArchive(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(zw)
+	files := []struct{ name, data string }{
+		{"export/mylist.xml", testXML},
+		{"export/README", "ignored"},
+		{"export/mylist.csv", testCSV},
+	}
+	for _, f := range files {
+		h := &tar.Header{Name: f.name, Mode: 0644, Size: int64(len(f.data)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(h); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(f.data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadArchive(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := append(append([]udpapi.MylistEntry{}, testXMLEntries...), testCSVEntries...)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %#v; want %#v", got, want)
+	}
+}
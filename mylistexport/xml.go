@@ -0,0 +1,110 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mylistexport
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"go.felesatra.moe/anidb/udpapi"
+)
+
+// ParseXML parses a mylist export generated with the xml-plain
+// template.
+//
+// Each file element is an entry, with fields given as attributes or
+// child elements.
+// The aid and eid default to the id attribute of the enclosing anime
+// and ep elements, and the fid defaults to the id attribute of the
+// file element.
+func ParseXML(r io.Reader) ([]udpapi.MylistEntry, error) {
+	d := xml.NewDecoder(r)
+	var entries []udpapi.MylistEntry
+	var aid, eid string
+	for {
+		tok, err := d.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("mylistexport ParseXML: %w", err)
+		}
+		se, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(se.Name.Local) {
+		case "anime":
+			aid = attr(se, "id")
+		case "ep", "episode":
+			eid = attr(se, "id")
+		case "file":
+			e, err := parseXMLFile(d, se, aid, eid)
+			if err != nil {
+				return nil, fmt.Errorf("mylistexport ParseXML: %w", err)
+			}
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+// parseXMLFile parses a file element.
+func parseXMLFile(d *xml.Decoder, se xml.StartElement, aid, eid string) (udpapi.MylistEntry, error) {
+	var b entryBuilder
+	defaults := map[string]string{"aid": aid, "eid": eid, "fid": attr(se, "id")}
+	for k, v := range defaults {
+		if err := b.set(k, v); err != nil {
+			return b.e, err
+		}
+	}
+	for _, a := range se.Attr {
+		if err := b.set(a.Name.Local, a.Value); err != nil {
+			return b.e, err
+		}
+	}
+	// Child elements are simple fields.
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return b.e, err
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			var v string
+			if err := d.DecodeElement(&v, &tok); err != nil {
+				return b.e, err
+			}
+			if err := b.set(tok.Name.Local, v); err != nil {
+				return b.e, err
+			}
+		case xml.EndElement:
+			return b.e, nil
+		}
+	}
+}
+
+// attr returns the value of an attribute, ignoring case.
+func attr(se xml.StartElement, name string) string {
+	for _, a := range se.Attr {
+		if strings.EqualFold(a.Name.Local, name) {
+			return a.Value
+		}
+	}
+	return ""
+}
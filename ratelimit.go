@@ -0,0 +1,135 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package anidb
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// A RateConfig configures a [RateLimiter].
+// See [HTTPRateConfig] and [UDPRateConfig] for configurations that
+// comply with the AniDB API rate limits.
+type RateConfig struct {
+	// Interval is the minimum time between requests.
+	// If zero, there is no minimum.
+	Interval time.Duration
+	// LongInterval is the minimum average time between requests
+	// over the long term, after LongBurst requests.
+	// If zero, there is no long term limit.
+	LongInterval time.Duration
+	// LongBurst is the number of requests allowed before
+	// LongInterval applies.
+	LongBurst int
+	// DailyLimit is the maximum number of requests per day.
+	// Requests beyond this fail with [ErrDailyLimit].
+	// If zero, there is no maximum.
+	DailyLimit int
+}
+
+// HTTPRateConfig returns a RateConfig for the AniDB HTTP API, which
+// asks for no more than one request every two seconds.
+func HTTPRateConfig() RateConfig {
+	return RateConfig{Interval: 2 * time.Second}
+}
+
+// UDPRateConfig returns a RateConfig for the AniDB UDP API, which
+// asks for no more than one request every two seconds, and one
+// request every four seconds after a short burst.
+// This is the same as the default limiter used by
+// [go.felesatra.moe/anidb/udpapi.Client].
+func UDPRateConfig() RateConfig {
+	return RateConfig{
+		Interval:     2 * time.Second,
+		LongInterval: 4 * time.Second,
+		LongBurst:    30,
+	}
+}
+
+// ErrDailyLimit is returned by [RateLimiter.Wait] for requests beyond
+// [RateConfig.DailyLimit].
+var ErrDailyLimit = errors.New("daily request limit exceeded")
+
+// A RateLimiter is a rate limiter for the AniDB APIs.
+// It implements [Limiter] and
+// [go.felesatra.moe/anidb/udpapi.Limiter], so a single RateLimiter
+// can be shared between a [Client] and a udpapi Client, for
+// applications that want one budget for both APIs.
+//
+// The methods can be called concurrently.
+type RateLimiter struct {
+	short      *rate.Limiter
+	long       *rate.Limiter
+	dailyLimit int
+
+	mu       sync.Mutex
+	dayStart time.Time
+	dayCount int
+}
+
+// NewRateLimiter returns a new RateLimiter.
+func NewRateLimiter(cfg RateConfig) *RateLimiter {
+	l := &RateLimiter{dailyLimit: cfg.DailyLimit}
+	if cfg.Interval > 0 {
+		l.short = rate.NewLimiter(rate.Every(cfg.Interval), 1)
+	}
+	if cfg.LongInterval > 0 {
+		l.long = rate.NewLimiter(rate.Every(cfg.LongInterval), max(cfg.LongBurst, 1))
+	}
+	return l
+}
+
+// Wait blocks until a request is allowed.
+// Returns [ErrDailyLimit] if the daily limit is
+// exceeded, or an error if ctx is done first.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if !l.takeDaily(time.Now()) {
+		return ErrDailyLimit
+	}
+	if l.long != nil {
+		if err := l.long.Wait(ctx); err != nil {
+			return err
+		}
+	}
+	if l.short != nil {
+		if err := l.short.Wait(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// takeDaily takes one request from the daily limit.
+// Returns false if the limit is exceeded.
+func (l *RateLimiter) takeDaily(now time.Time) bool {
+	if l.dailyLimit <= 0 {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if now.Sub(l.dayStart) >= 24*time.Hour {
+		l.dayStart = now
+		l.dayCount = 0
+	}
+	if l.dayCount >= l.dailyLimit {
+		return false
+	}
+	l.dayCount++
+	return true
+}
@@ -0,0 +1,76 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package anidb
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.felesatra.moe/anidb/udpapi"
+)
+
+var (
+	_ Limiter        = (*RateLimiter)(nil)
+	_ udpapi.Limiter = (*RateLimiter)(nil)
+)
+
+func TestRateLimiter_interval(t *testing.T) {
+	t.Parallel()
+	l := NewRateLimiter(RateConfig{Interval: 50 * time.Millisecond})
+	ctx := context.Background()
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := l.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if d := time.Since(start); d < 100*time.Millisecond {
+		t.Errorf("Got 3 requests in %s; want at least 100ms", d)
+	}
+}
+
+func TestRateLimiter_dailyLimit(t *testing.T) {
+	t.Parallel()
+	l := NewRateLimiter(RateConfig{DailyLimit: 2})
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if err := l.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := l.Wait(ctx); !errors.Is(err, ErrDailyLimit) {
+		t.Errorf("Got error %v; want %v", err, ErrDailyLimit)
+	}
+	if !l.takeDaily(time.Now().Add(25 * time.Hour)) {
+		t.Errorf("Got daily limit exceeded the next day")
+	}
+}
+
+func TestRateLimiter_shared(t *testing.T) {
+	t.Parallel()
+	l := NewRateLimiter(RateConfig{DailyLimit: 1})
+	srv := newXMLServer(t, `<hotanime></hotanime>`)
+	c := Client{Name: "test", Version: 1, BaseURL: srv.URL, Limiter: l}
+	if _, err := c.RequestHotAnime(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// Requests through another client count against the same limit.
+	var ul udpapi.Limiter = l
+	if err := ul.Wait(context.Background()); !errors.Is(err, ErrDailyLimit) {
+		t.Errorf("Got error %v; want %v", err, ErrDailyLimit)
+	}
+}
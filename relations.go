@@ -0,0 +1,104 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package anidb
+
+import (
+	"context"
+	"fmt"
+)
+
+// Relation types for [RelatedAnime.Type].
+const (
+	RelationSequel             = "Sequel"
+	RelationPrequel            = "Prequel"
+	RelationSameSetting        = "Same Setting"
+	RelationAlternativeSetting = "Alternative Setting"
+	RelationAlternativeVersion = "Alternative Version"
+	RelationSideStory          = "Side Story"
+	RelationParentStory        = "Parent Story"
+	RelationSummary            = "Summary"
+	RelationFullStory          = "Full Story"
+	RelationCharacter          = "Character"
+	RelationOther              = "Other"
+)
+
+// Related returns the AIDs of the related anime with the given
+// relation type, like [RelationSequel].
+func (a *Anime) Related(typ string) []int {
+	var aids []int
+	for _, r := range a.RelatedAnime {
+		if r.Type == typ {
+			aids = append(aids, r.AID)
+		}
+	}
+	return aids
+}
+
+// RequestFranchise returns the prequel/sequel chain containing an
+// anime, in order from the first prequel to the last sequel.
+// Only the first prequel and sequel of each anime are followed.
+// The chain stops if a relation leads back to an anime already in the
+// chain.
+//
+// Anime are requested with [Client.RequestAnimeParts], so the anime
+// cache is used if set.
+// The returned anime include the given parts and
+// [AnimeRelations].
+func (c *Client) RequestFranchise(ctx context.Context, aid int, parts AnimeParts) ([]*Anime, error) {
+	parts |= AnimeRelations
+	seen := make(map[int]*Anime)
+	get := func(aid int) (*Anime, error) {
+		if a, ok := seen[aid]; ok {
+			return a, nil
+		}
+		a, err := c.RequestAnimeParts(ctx, aid, parts)
+		if err != nil {
+			return nil, fmt.Errorf("anidb request franchise %d: %w", aid, err)
+		}
+		seen[aid] = a
+		return a, nil
+	}
+	first, err := get(aid)
+	if err != nil {
+		return nil, err
+	}
+	// Walk back to the first prequel.
+	back := map[int]bool{aid: true}
+	for {
+		p := first.Related(RelationPrequel)
+		if len(p) == 0 || back[p[0]] {
+			break
+		}
+		back[p[0]] = true
+		if first, err = get(p[0]); err != nil {
+			return nil, err
+		}
+	}
+	// Walk forward through the sequels.
+	chain := []*Anime{first}
+	inChain := map[int]bool{first.AID: true}
+	for a := first; ; {
+		s := a.Related(RelationSequel)
+		if len(s) == 0 || inChain[s[0]] {
+			break
+		}
+		if a, err = get(s[0]); err != nil {
+			return nil, err
+		}
+		inChain[s[0]] = true
+		chain = append(chain, a)
+	}
+	return chain, nil
+}
@@ -0,0 +1,90 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package anidb
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestClient_RequestFranchise(t *testing.T) {
+	t.Parallel()
+	// 1 -> 2 -> 3 -> 1, with 4 as a side story of 2.
+	related := map[string]string{
+		"1": `<anime id="2" type="Sequel">B</anime><anime id="3" type="Prequel">C</anime>`,
+		"2": `<anime id="1" type="Prequel">A</anime><anime id="3" type="Sequel">C</anime><anime id="4" type="Side Story">D</anime>`,
+		"3": `<anime id="2" type="Prequel">B</anime><anime id="1" type="Sequel">A</anime>`,
+		"4": `<anime id="2" type="Parent Story">B</anime>`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		aid := r.URL.Query().Get("aid")
+		fmt.Fprintf(w, `<anime id="%s" restricted="false"><relatedanime>%s</relatedanime></anime>`, aid, related[aid])
+	}))
+	t.Cleanup(srv.Close)
+	l := &countLimiter{}
+	c := Client{
+		Name:       "test",
+		Version:    1,
+		Limiter:    l,
+		BaseURL:    srv.URL,
+		AnimeCache: &AnimeCache{Dir: t.TempDir()},
+	}
+	for _, aid := range []int{2, 4} {
+		got, err := c.RequestFranchise(context.Background(), aid, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var aids []int
+		for _, a := range got {
+			aids = append(aids, a.AID)
+		}
+		want := []int{3, 1, 2}
+		if aid == 4 {
+			want = []int{4}
+		}
+		if !reflect.DeepEqual(aids, want) {
+			t.Errorf("aid %d: Got %v; want %v", aid, aids, want)
+		}
+	}
+	// Anime are requested once each and cached.
+	if got := l.count(); got != 4 {
+		t.Errorf("Got %d requests; want 4", got)
+	}
+	if _, err := c.RequestFranchise(context.Background(), 1, 0); err != nil {
+		t.Fatal(err)
+	}
+	if got := l.count(); got != 4 {
+		t.Errorf("Got %d requests after cached walk; want 4", got)
+	}
+}
+
+func TestAnime_Related(t *testing.T) {
+	t.Parallel()
+	a := &Anime{RelatedAnime: []RelatedAnime{
+		{AID: 1, Type: RelationPrequel},
+		{AID: 2, Type: RelationSideStory},
+		{AID: 3, Type: RelationSideStory},
+	}}
+	if got, want := a.Related(RelationSideStory), []int{2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v; want %v", got, want)
+	}
+	if got := a.Related(RelationSequel); got != nil {
+		t.Errorf("Got %v; want nil", got)
+	}
+}
@@ -0,0 +1,220 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package anidb
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// A TitleIndex is an in-memory index over anime titles for looking
+// up AIDs by title.
+// A TitleIndex is safe for concurrent use.
+type TitleIndex struct {
+	// entries is sorted by folded title.
+	entries []titleEntry
+	exact   map[string][]int
+}
+
+type titleEntry struct {
+	aid    int
+	title  Title
+	folded string
+	norm   string
+}
+
+// A TitleFilter restricts which titles are matched by a TitleIndex
+// lookup.
+// Empty fields match everything.
+type TitleFilter struct {
+	// Langs are title languages, e.g., "en" or "x-jat".
+	Langs []string
+	// Types are title types, e.g., "main" or "official".
+	Types []string
+}
+
+func (f TitleFilter) match(t Title) bool {
+	return (len(f.Langs) == 0 || slices.Contains(f.Langs, t.Lang)) &&
+		(len(f.Types) == 0 || slices.Contains(f.Types, t.Type))
+}
+
+// NewTitleIndex builds a TitleIndex over the given titles.
+func NewTitleIndex(a []AnimeT) *TitleIndex {
+	x := &TitleIndex{
+		exact: make(map[string][]int),
+	}
+	for _, a := range a {
+		for _, t := range a.Titles {
+			x.entries = append(x.entries, titleEntry{
+				aid:    a.AID,
+				title:  t,
+				folded: normalizeTitle(t.Name),
+				norm:   fuzzyTitle(t.Name),
+			})
+		}
+	}
+	sort.SliceStable(x.entries, func(i, j int) bool {
+		return x.entries[i].folded < x.entries[j].folded
+	})
+	for i, e := range x.entries {
+		x.exact[e.title.Name] = append(x.exact[e.title.Name], i)
+	}
+	return x
+}
+
+// Exact returns the AIDs of anime with a title exactly matching name.
+func (x *TitleIndex) Exact(name string, f TitleFilter) []int {
+	var m aidSet
+	for _, i := range x.exact[name] {
+		m.add(x.entries[i], f)
+	}
+	return m.aids
+}
+
+// Fold returns the AIDs of anime with a title matching name,
+// ignoring case and differences in whitespace.
+func (x *TitleIndex) Fold(name string, f TitleFilter) []int {
+	name = normalizeTitle(name)
+	var m aidSet
+	for _, e := range x.foldedRange(name) {
+		if e.folded == name {
+			m.add(e, f)
+		}
+	}
+	return m.aids
+}
+
+// Prefix returns the AIDs of anime with a title starting with
+// prefix, ignoring case and differences in whitespace.
+func (x *TitleIndex) Prefix(prefix string, f TitleFilter) []int {
+	var m aidSet
+	for _, e := range x.foldedRange(normalizeTitle(prefix)) {
+		m.add(e, f)
+	}
+	return m.aids
+}
+
+// foldedRange returns the entries whose folded title starts with
+// prefix, which must already be folded.
+func (x *TitleIndex) foldedRange(prefix string) []titleEntry {
+	i := sort.Search(len(x.entries), func(i int) bool {
+		return x.entries[i].folded >= prefix
+	})
+	j := i
+	for j < len(x.entries) && strings.HasPrefix(x.entries[j].folded, prefix) {
+		j++
+	}
+	return x.entries[i:j]
+}
+
+// Fuzzy returns the AIDs of anime with a title containing query
+// after normalization.
+// Normalization ignores case, punctuation, spacing and diacritics,
+// and treats common romanization variants of long vowels
+// (e.g., "ō", "ou" and "oo") as equivalent.
+//
+// AIDs whose title matches the query in full are returned first,
+// then AIDs whose title starts with the query, then the rest.
+func (x *TitleIndex) Fuzzy(query string, f TitleFilter) []int {
+	query = fuzzyTitle(query)
+	if query == "" {
+		return nil
+	}
+	var full, prefix, rest aidSet
+	for _, e := range x.entries {
+		switch {
+		case e.norm == query:
+			full.add(e, f)
+		case strings.HasPrefix(e.norm, query):
+			prefix.add(e, f)
+		case strings.Contains(e.norm, query):
+			rest.add(e, f)
+		}
+	}
+	var m aidSet
+	for _, s := range []aidSet{full, prefix, rest} {
+		for _, aid := range s.aids {
+			m.addAID(aid)
+		}
+	}
+	return m.aids
+}
+
+// An aidSet collects unique AIDs in insertion order.
+type aidSet struct {
+	seen map[int]bool
+	aids []int
+}
+
+func (s *aidSet) add(e titleEntry, f TitleFilter) {
+	if f.match(e.title) {
+		s.addAID(e.aid)
+	}
+}
+
+func (s *aidSet) addAID(aid int) {
+	if s.seen[aid] {
+		return
+	}
+	if s.seen == nil {
+		s.seen = make(map[int]bool)
+	}
+	s.seen[aid] = true
+	s.aids = append(s.aids, aid)
+}
+
+// longVowels replaces romanization variants of long vowels.
+var longVowels = strings.NewReplacer(
+	"ou", "o",
+	"oo", "o",
+	"uu", "u",
+	"aa", "a",
+	"ee", "e",
+)
+
+// fuzzyTitle normalizes a title for fuzzy matching.
+func fuzzyTitle(s string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if r, ok := diacritics[r]; ok {
+			sb.WriteRune(r)
+			continue
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(r)
+		}
+	}
+	s = sb.String()
+	for {
+		r := longVowels.Replace(s)
+		if r == s {
+			return s
+		}
+		s = r
+	}
+}
+
+// diacritics maps lowercase letters with diacritics to their base
+// letter.
+var diacritics = map[rune]rune{
+	'ā': 'a', 'á': 'a', 'à': 'a', 'â': 'a', 'ä': 'a', 'ã': 'a', 'å': 'a',
+	'ē': 'e', 'é': 'e', 'è': 'e', 'ê': 'e', 'ë': 'e',
+	'ī': 'i', 'í': 'i', 'ì': 'i', 'î': 'i', 'ï': 'i',
+	'ō': 'o', 'ó': 'o', 'ò': 'o', 'ô': 'o', 'ö': 'o', 'õ': 'o', 'ø': 'o',
+	'ū': 'u', 'ú': 'u', 'ù': 'u', 'û': 'u', 'ü': 'u',
+	'ç': 'c', 'ñ': 'n', 'ý': 'y', 'ÿ': 'y',
+}
@@ -0,0 +1,74 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package anidb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func testTitleIndex() *TitleIndex {
+	return NewTitleIndex([]AnimeT{
+		{AID: 22, Titles: []Title{
+			{Name: "Shinseiki Evangelion", Type: "main", Lang: "x-jat"},
+			{Name: "Neon Genesis Evangelion", Type: "official", Lang: "en"},
+		}},
+		{AID: 3651, Titles: []Title{
+			{Name: "Suzumiya Haruhi no Yuuutsu", Type: "main", Lang: "x-jat"},
+			{Name: "The Melancholy of Haruhi Suzumiya", Type: "official", Lang: "en"},
+		}},
+		{AID: 4097, Titles: []Title{
+			{Name: "Tōkyō Magnitude 8.0", Type: "main", Lang: "x-jat"},
+		}},
+		{AID: 5000, Titles: []Title{
+			{Name: "Evangelion Shin Gekijouban", Type: "main", Lang: "x-jat"},
+		}},
+	})
+}
+
+func TestTitleIndex(t *testing.T) {
+	t.Parallel()
+	x := testTitleIndex()
+	cases := []struct {
+		desc string
+		f    func(string, TitleFilter) []int
+		q    string
+		tf   TitleFilter
+		want []int
+	}{
+		{desc: "exact", f: x.Exact, q: "Neon Genesis Evangelion", want: []int{22}},
+		{desc: "exact case", f: x.Exact, q: "neon genesis evangelion"},
+		{desc: "fold", f: x.Fold, q: "neon genesis EVANGELION", want: []int{22}},
+		{desc: "prefix", f: x.Prefix, q: "evangelion", want: []int{5000}},
+		{desc: "prefix filter", f: x.Prefix, q: "s", tf: TitleFilter{Langs: []string{"en"}}},
+		{desc: "prefix multiple", f: x.Prefix, q: "S", want: []int{22, 3651}},
+		{desc: "fuzzy long vowels", f: x.Fuzzy, q: "tokyo magnitude 8", want: []int{4097}},
+		{desc: "fuzzy macron query", f: x.Fuzzy, q: "Gekijōban", want: []int{5000}},
+		{desc: "fuzzy punctuation", f: x.Fuzzy, q: "haruhi no yuutsu", want: []int{3651}},
+		{desc: "fuzzy ranking", f: x.Fuzzy, q: "evangelion", want: []int{5000, 22}},
+		{desc: "fuzzy type", f: x.Fuzzy, q: "evangelion", tf: TitleFilter{Types: []string{"official"}}, want: []int{22}},
+		{desc: "fuzzy empty", f: x.Fuzzy, q: "!!"},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.desc, func(t *testing.T) {
+			t.Parallel()
+			got := c.f(c.q, c.tf)
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("Got %v; want %v", got, c.want)
+			}
+		})
+	}
+}
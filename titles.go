@@ -15,11 +15,18 @@
 package anidb
 
 import (
+	"bufio"
+	"bytes"
 	"compress/gzip"
+	"context"
+	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strconv"
+	"strings"
 )
 
 // RequestTitles requests title information from AniDB.
@@ -27,43 +34,143 @@ import (
 // TitlesCache is more convenient to use, as AniDB has severe rate
 // limits on this.
 func RequestTitles() ([]AnimeT, error) {
-	d, err := downloadTitles()
+	return RequestTitlesFormat(TitlesXML)
+}
+
+// RequestTitlesFormat requests title information from AniDB using
+// the given dump format.
+// The dat format is much smaller and faster to decode than XML.
+func RequestTitlesFormat(f TitlesFormat) ([]AnimeT, error) {
+	var c Client
+	return c.RequestTitles(context.Background(), f)
+}
+
+// RequestTitles requests title information from AniDB using the
+// given dump format.
+// The titles dump is not part of the HTTP API and is not subject to
+// the client's Limiter, but AniDB has severe rate limits on it.
+func (c *Client) RequestTitles(ctx context.Context, f TitlesFormat) ([]AnimeT, error) {
+	d, _, err := c.downloadTitles(ctx, f, titlesValidators{})
 	if err != nil {
 		return nil, fmt.Errorf("anidb request titles: %s", err)
 	}
-	ts, err := DecodeTitles(d)
+	ts, err := f.decode(d)
 	if err != nil {
 		return nil, fmt.Errorf("anidb request titles: %s", err)
 	}
 	return ts, nil
 }
 
-const titlesURL = "http://anidb.net/api/anime-titles.xml.gz"
+// A TitlesFormat is a format of the AniDB titles dump.
+type TitlesFormat int
+
+// Titles dump formats.
+const (
+	TitlesXML TitlesFormat = iota
+	TitlesDat
+	TitlesJSON
+)
+
+func (f TitlesFormat) String() string {
+	switch f {
+	case TitlesXML:
+		return "xml"
+	case TitlesDat:
+		return "dat"
+	case TitlesJSON:
+		return "json"
+	default:
+		return fmt.Sprintf("TitlesFormat(%d)", int(f))
+	}
+}
+
+// url returns the URL of the titles dump in this format.
+func (f TitlesFormat) url(base string) string {
+	return base + "." + f.String() + ".gz"
+}
+
+// decode decodes an uncompressed titles dump in this format.
+func (f TitlesFormat) decode(d []byte) ([]AnimeT, error) {
+	switch f {
+	case TitlesXML:
+		return DecodeTitles(d)
+	case TitlesDat:
+		return DecodeTitlesDat(d)
+	case TitlesJSON:
+		return DecodeTitlesJSON(d)
+	default:
+		return nil, fmt.Errorf("anidb decode titles: unknown format %s", f)
+	}
+}
+
+// DefaultTitlesBaseURL is the URL of the AniDB titles dump without
+// the format extension.
+const DefaultTitlesBaseURL = "http://anidb.net/api/anime-titles"
+
+// titlesBaseURL is the default for [Client.TitlesBaseURL].
+// This is a variable for testing.
+var titlesBaseURL = DefaultTitlesBaseURL
+
+// titlesValidators are the HTTP cache validators for a titles dump.
+type titlesValidators struct {
+	ETag         string
+	LastModified string
+}
 
-func downloadTitles() ([]byte, error) {
-	req, err := http.NewRequest("GET", titlesURL, nil)
+// errNotModified is returned by downloadTitles if the titles dump has
+// not been modified.
+var errNotModified = errors.New("titles not modified")
+
+// downloadTitles downloads the titles dump in the given format.
+// If validators are given, the request is conditional and
+// errNotModified is returned if the dump has not been modified.
+// The validators for the downloaded dump are returned.
+func (c *Client) downloadTitles(ctx context.Context, f TitlesFormat, v titlesValidators) ([]byte, titlesValidators, error) {
+	base := c.TitlesBaseURL
+	if base == "" {
+		base = titlesBaseURL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.url(base), nil)
 	if err != nil {
-		panic(err)
+		return nil, v, err
+	}
+	ua := c.UserAgent
+	if ua == "" {
+		ua = userAgent
 	}
-	req.Header.Add("User-Agent", userAgent)
-	resp, err := httpClient.Do(req)
+	req.Header.Add("User-Agent", ua)
+	if v.ETag != "" {
+		req.Header.Set("If-None-Match", v.ETag)
+	}
+	if v.LastModified != "" {
+		req.Header.Set("If-Modified-Since", v.LastModified)
+	}
+	resp, err := c.httpClient().Do(req)
 	if err != nil {
-		return nil, err
+		return nil, v, err
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode != 200 {
-		return nil, err
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		return nil, v, errNotModified
+	default:
+		return nil, v, fmt.Errorf("got HTTP status %s", resp.Status)
 	}
 	r, err := gzip.NewReader(resp.Body)
 	if err != nil {
-		return nil, err
+		return nil, v, err
 	}
 	defer r.Close()
 	d, err := ioutil.ReadAll(r)
 	if err != nil {
-		return nil, err
+		return nil, v, err
+	}
+	v = titlesValidators{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
 	}
-	return d, nil
+	return d, v, nil
 }
 
 // DecodeTitles decodes XML title information from an AniDB title dump.
@@ -78,9 +185,126 @@ func DecodeTitles(d []byte) ([]AnimeT, error) {
 	return r.Anime, nil
 }
 
+// DecodeTitlesDat decodes title information from an AniDB title dump
+// in the dat format.
+// The input should be uncompressed.
+func DecodeTitlesDat(d []byte) ([]AnimeT, error) {
+	var a []AnimeT
+	index := make(map[int]int)
+	sc := bufio.NewScanner(bytes.NewReader(d))
+	for n := 1; sc.Scan(); n++ {
+		line := sc.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		f := strings.SplitN(line, "|", 4)
+		if len(f) != 4 {
+			return nil, fmt.Errorf("anidb decode titles: line %d: got %d fields", n, len(f))
+		}
+		aid, err := strconv.Atoi(f[0])
+		if err != nil {
+			return nil, fmt.Errorf("anidb decode titles: line %d: %s", n, err)
+		}
+		t := Title{Name: f[3], Type: datTitleTypes[f[1]], Lang: f[2]}
+		if t.Type == "" {
+			return nil, fmt.Errorf("anidb decode titles: line %d: unknown title type %q", n, f[1])
+		}
+		i, ok := index[aid]
+		if !ok {
+			i = len(a)
+			index[aid] = i
+			a = append(a, AnimeT{AID: aid})
+		}
+		a[i].Titles = append(a[i].Titles, t)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("anidb decode titles: %s", err)
+	}
+	return a, nil
+}
+
+// datTitleTypes maps dat title types to the XML title types.
+var datTitleTypes = map[string]string{
+	"1": "main",
+	"2": "syn",
+	"3": "short",
+	"4": "official",
+}
+
+// DecodeTitlesJSON decodes title information from an AniDB title dump
+// in the JSON format.
+// The input should be uncompressed.
+func DecodeTitlesJSON(d []byte) ([]AnimeT, error) {
+	var r []struct {
+		AID    int `json:"aid"`
+		Titles []struct {
+			Title string `json:"title"`
+			Type  string `json:"type"`
+			Lang  string `json:"lang"`
+		} `json:"titles"`
+	}
+	if err := json.Unmarshal(d, &r); err != nil {
+		return nil, fmt.Errorf("anidb decode titles: %s", err)
+	}
+	a := make([]AnimeT, len(r))
+	for i, r := range r {
+		a[i].AID = r.AID
+		for _, t := range r.Titles {
+			a[i].Titles = append(a[i].Titles, Title{Name: t.Title, Type: t.Type, Lang: t.Lang})
+		}
+	}
+	return a, nil
+}
+
 // An AnimeT is like Anime but holds title information only.
 // This is used for representing anime titles from the AniDB title dump.
 type AnimeT struct {
 	AID    int     `xml:"aid,attr"`
 	Titles []Title `xml:"title"`
 }
+
+// DiffTitles compares two sets of titles data by AID, such as from two
+// titles dumps.
+// Anime are changed if their set of titles differs, ignoring order.
+// The changed anime are returned from new.
+func DiffTitles(old, new []AnimeT) (added, removed, changed []AnimeT) {
+	oldByAID := make(map[int]AnimeT, len(old))
+	for _, a := range old {
+		oldByAID[a.AID] = a
+	}
+	newAIDs := make(map[int]bool, len(new))
+	for _, a := range new {
+		newAIDs[a.AID] = true
+		o, ok := oldByAID[a.AID]
+		switch {
+		case !ok:
+			added = append(added, a)
+		case !sameTitles(o.Titles, a.Titles):
+			changed = append(changed, a)
+		}
+	}
+	for _, a := range old {
+		if !newAIDs[a.AID] {
+			removed = append(removed, a)
+		}
+	}
+	return added, removed, changed
+}
+
+// sameTitles returns true if the titles are the same, ignoring order.
+func sameTitles(a, b []Title) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	n := make(map[Title]int, len(a))
+	for _, t := range a {
+		n[t]++
+	}
+	for _, t := range b {
+		if n[t] == 0 {
+			return false
+		}
+		n[t]--
+	}
+	return true
+}
@@ -0,0 +1,131 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package anidb
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+// TitlesData is the data stored by a [CacheStore] for a
+// [TitlesCache].
+type TitlesData struct {
+	Titles []AnimeT
+	// ETag and LastModified are the HTTP cache validators for the
+	// titles.
+	ETag         string
+	LastModified string
+}
+
+// ErrCorruptCache is wrapped by errors from [CacheStore.Load] when the
+// stored titles cannot be decoded.
+var ErrCorruptCache = errors.New("corrupt cache")
+
+// A CacheStore is a storage backend for a [TitlesCache].
+// Implementations can store titles in places other than the local
+// filesystem, such as an object store for server deployments.
+type CacheStore interface {
+	// Load loads the stored titles.
+	// If nothing is stored, it returns the zero TitlesData and a
+	// nil error.
+	// If the stored titles cannot be decoded, the error should wrap
+	// [ErrCorruptCache].
+	Load(context.Context) (TitlesData, error)
+	// Save stores titles, replacing any stored titles.
+	Save(context.Context, TitlesData) error
+}
+
+// A TitlesFileStore is a [CacheStore] that stores titles in a gob
+// file.
+type TitlesFileStore struct {
+	Path string
+}
+
+// Load implements [CacheStore].
+func (s TitlesFileStore) Load(context.Context) (TitlesData, error) {
+	var d TitlesData
+	f, err := os.Open(s.Path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return d, nil
+		}
+		return d, err
+	}
+	defer f.Close()
+	dec := gob.NewDecoder(f)
+	if err := dec.Decode(&d.Titles); err != nil {
+		return TitlesData{}, fmt.Errorf("%s: %w: %s", s.Path, ErrCorruptCache, err)
+	}
+	// The validators are stored after the titles, so older cache
+	// files without them can still be read.
+	var v titlesValidators
+	if err := dec.Decode(&v); err != nil && !errors.Is(err, io.EOF) {
+		return TitlesData{}, fmt.Errorf("%s: %w: %s", s.Path, ErrCorruptCache, err)
+	}
+	d.ETag = v.ETag
+	d.LastModified = v.LastModified
+	return d, nil
+}
+
+// Save implements [CacheStore].
+// The file is replaced atomically, so a crash while saving does not
+// leave a partially written file.
+func (s TitlesFileStore) Save(_ context.Context, d TitlesData) error {
+	var buf bytes.Buffer
+	enc := gob.NewEncoder(&buf)
+	if err := enc.Encode(d.Titles); err != nil {
+		return fmt.Errorf("%s: %s", s.Path, err)
+	}
+	v := titlesValidators{ETag: d.ETag, LastModified: d.LastModified}
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("%s: %s", s.Path, err)
+	}
+	return writeCacheFile(filepath.Dir(s.Path), s.Path, buf.Bytes())
+}
+
+// A TitlesMemoryStore is a [CacheStore] that keeps titles in memory.
+// It is useful for tests and for sharing titles between caches in a
+// process.
+// The zero value is an empty store.
+type TitlesMemoryStore struct {
+	mu sync.Mutex
+	d  TitlesData
+}
+
+// Load implements [CacheStore].
+func (s *TitlesMemoryStore) Load(context.Context) (TitlesData, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d := s.d
+	d.Titles = slices.Clone(d.Titles)
+	return d, nil
+}
+
+// Save implements [CacheStore].
+func (s *TitlesMemoryStore) Save(_ context.Context, d TitlesData) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d.Titles = slices.Clone(d.Titles)
+	s.d = d
+	return nil
+}
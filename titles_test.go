@@ -45,3 +45,66 @@ func TestDecodeTitles(t *testing.T) {
 		t.Errorf("DecodeTitles(%#v) = %#v, expected %#v", d, a, exp)
 	}
 }
+
+func TestDecodeTitles_formats(t *testing.T) {
+	exp := []AnimeT{{AID: 22, Titles: []Title{
+		{
+			Name: "Neon Genesis Evangelion",
+			Type: "official",
+			Lang: "en",
+		},
+		{
+			Name: "Shinseiki Evangelion",
+			Type: "main",
+			Lang: "x-jat",
+		},
+	}}}
+	for _, f := range []TitlesFormat{TitlesXML, TitlesDat, TitlesJSON} {
+		f := f
+		t.Run(f.String(), func(t *testing.T) {
+			d, err := ioutil.ReadFile("testdata/titles." + f.String())
+			if err != nil {
+				t.Fatalf("Error reading test data file: %+v", err)
+			}
+			a, err := f.decode(d)
+			if err != nil {
+				t.Fatalf("Error decoding titles: %+v", err)
+			}
+			if !reflect.DeepEqual(a, exp) {
+				t.Errorf("Got %#v; want %#v", a, exp)
+			}
+		})
+	}
+}
+
+func TestDecodeTitlesDat_badType(t *testing.T) {
+	if _, err := DecodeTitlesDat([]byte("22|9|en|Evangelion\n")); err == nil {
+		t.Errorf("Expected error")
+	}
+}
+
+func TestDiffTitles(t *testing.T) {
+	eva := Title{Name: "Shinseiki Evangelion", Type: "main", Lang: "x-jat"}
+	evaEn := Title{Name: "Neon Genesis Evangelion", Type: "official", Lang: "en"}
+	bofuri := Title{Name: "Bofuri", Type: "main", Lang: "x-jat"}
+	old := []AnimeT{
+		{AID: 22, Titles: []Title{eva, evaEn}},
+		{AID: 23, Titles: []Title{{Name: "Cowboy Bebop", Type: "main"}}},
+		{AID: 8076, Titles: []Title{bofuri}},
+	}
+	new := []AnimeT{
+		{AID: 22, Titles: []Title{evaEn, eva}},
+		{AID: 8076, Titles: []Title{bofuri, {Name: "BOFURI", Type: "official", Lang: "en"}}},
+		{AID: 69, Titles: []Title{{Name: "One Piece", Type: "main"}}},
+	}
+	added, removed, changed := DiffTitles(old, new)
+	if want := []AnimeT{new[2]}; !reflect.DeepEqual(added, want) {
+		t.Errorf("Got added %#v; want %#v", added, want)
+	}
+	if want := []AnimeT{old[1]}; !reflect.DeepEqual(removed, want) {
+		t.Errorf("Got removed %#v; want %#v", removed, want)
+	}
+	if want := []AnimeT{new[1]}; !reflect.DeepEqual(changed, want) {
+		t.Errorf("Got changed %#v; want %#v", changed, want)
+	}
+}
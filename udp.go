@@ -0,0 +1,85 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package anidb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+
+	"go.felesatra.moe/anidb/udpapi"
+)
+
+// DefaultUDPServer is the address of the AniDB UDP API server.
+const DefaultUDPServer = "api.anidb.net:9000"
+
+// A UDPConfig is the configuration for an AniDB UDP API client.
+// Read the AniDB API documentation about registering a client.
+type UDPConfig struct {
+	// Server is the address of the UDP API server.
+	// See [DefaultUDPServer].
+	Server        string
+	ClientName    string
+	ClientVersion int32
+	// APIKey is the optional AniDB UDP API key.
+	APIKey string
+	// Local is the optional local address to bind, such as a fixed
+	// port for NAT pinning.
+	Local *net.UDPAddr
+	// Limiter is the optional rate limiter, replacing the default
+	// limiter of the client.
+	// This can be a [RateLimiter] shared with a [Client].
+	Limiter udpapi.Limiter
+}
+
+// Validate checks the configuration for problems.
+// All problems found are reported in the returned error.
+func (cfg UDPConfig) Validate() error {
+	var errs []error
+	if cfg.Server == "" {
+		errs = append(errs, errors.New("missing server"))
+	}
+	if cfg.ClientName == "" {
+		errs = append(errs, errors.New("missing client name"))
+	}
+	if cfg.ClientVersion <= 0 {
+		errs = append(errs, fmt.Errorf("invalid client version %d", cfg.ClientVersion))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("anidb UDPConfig: %w", err)
+	}
+	return nil
+}
+
+// Dial validates the configuration and connects a UDP API client.
+func (cfg UDPConfig) Dial(l *slog.Logger) (*udpapi.Client, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	dc := udpapi.DialConfig{LocalAddr: cfg.Local}
+	c, err := dc.Dial(context.Background(), cfg.Server, l)
+	if err != nil {
+		return nil, err
+	}
+	c.ClientName = cfg.ClientName
+	c.ClientVersion = cfg.ClientVersion
+	c.APIKey = cfg.APIKey
+	if cfg.Limiter != nil {
+		c.SetLimiter(cfg.Limiter)
+	}
+	return c, nil
+}
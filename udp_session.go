@@ -0,0 +1,104 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package anidb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"time"
+
+	"go.felesatra.moe/anidb/udpapi"
+)
+
+// sessionLogoutTimeout is the timeout for LOGOUT when closing a
+// Session.
+const sessionLogoutTimeout = 10 * time.Second
+
+// A Session is a logged in AniDB UDP API session.
+//
+// A Session is a batteries included alternative to using
+// [udpapi.Client] directly.
+// It handles encryption, authentication, NAT keepalive, request
+// retries and re-authentication.
+type Session struct {
+	c   *udpapi.Client
+	nat bool
+	ka  *udpapi.KeepAlive
+}
+
+// StartUDP connects to the UDP API and starts a session for the user.
+// If an API key is available from the UserInfo or the config, the
+// session is encrypted.
+// If the client is behind NAT, a [udpapi.KeepAlive] is run for the
+// session.
+// You must call Close after use.
+func StartUDP(ctx context.Context, cfg UDPConfig, u udpapi.UserInfo, l *slog.Logger) (*Session, error) {
+	c, err := cfg.Dial(l)
+	if err != nil {
+		return nil, fmt.Errorf("anidb StartUDP: %w", err)
+	}
+	c.RetryPolicy = udpapi.DefaultRetryPolicy()
+	c.Cooldown = udpapi.DefaultCooldownPolicy
+	c.AutoReauth = true
+	c.Encoding = udpapi.EncodingUTF8
+	var addr string
+	if u.APIKey != "" || cfg.APIKey != "" {
+		addr, err = c.LoginEncrypted(ctx, u)
+	} else {
+		addr, err = c.Auth(ctx, u)
+	}
+	if err != nil {
+		c.Close()
+		return nil, fmt.Errorf("anidb StartUDP: %w", err)
+	}
+	s := &Session{c: c}
+	// The server reports the address it sees us on, whose port
+	// differs from our local port if we are behind NAT.
+	if _, port, err := net.SplitHostPort(addr); err == nil && port != c.LocalPort() {
+		s.nat = true
+		l.Debug("Detected NAT, starting keepalive", "addr", addr, "port", c.LocalPort())
+		s.ka = udpapi.StartKeepAlive(c)
+	}
+	return s, nil
+}
+
+// Client returns the underlying client for making requests.
+// Do not call Logout or Close on the client; call [Session.Close]
+// instead.
+func (s *Session) Client() *udpapi.Client {
+	return s.c
+}
+
+// NAT returns true if the session was detected to be behind NAT.
+func (s *Session) NAT() bool {
+	return s.nat
+}
+
+// Close logs out and closes the session.
+func (s *Session) Close() error {
+	if s.ka != nil {
+		s.ka.Stop()
+	}
+	ctx, cf := context.WithTimeout(context.Background(), sessionLogoutTimeout)
+	defer cf()
+	err := errors.Join(s.c.Logout(ctx), s.c.Close())
+	if err != nil {
+		return fmt.Errorf("anidb Session Close: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package anidb
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.felesatra.moe/anidb/udpapi"
+)
+
+func TestStartUDP(t *testing.T) {
+	t.Parallel()
+	for _, nat := range []bool{false, true} {
+		nat := nat
+		t.Run(fmt.Sprintf("nat=%v", nat), func(t *testing.T) {
+			t.Parallel()
+			s := newFakeUDPServer(t, func(cmd string, args url.Values, port int) string {
+				switch cmd {
+				case "AUTH":
+					if nat {
+						port++
+					}
+					return fmt.Sprintf("200 key 1.2.3.4:%d LOGIN ACCEPTED", port)
+				case "LOGOUT":
+					return "203 LOGGED OUT"
+				default:
+					return "598 UNKNOWN COMMAND"
+				}
+			})
+			ctx, cf := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cf()
+			cfg := UDPConfig{
+				Server:        s.addr(),
+				ClientName:    "test",
+				ClientVersion: 1,
+			}
+			l := slog.New(slog.NewTextHandler(discard{}, nil))
+			sess, err := StartUDP(ctx, cfg, udpapi.UserInfo{UserName: "ionasal", UserPassword: "pass"}, l)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := sess.NAT(); got != nat {
+				t.Errorf("Got NAT %v; want %v", got, nat)
+			}
+			if err := sess.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if got, want := s.commands(), []string{"AUTH", "LOGOUT"}; strings.Join(got, " ") != strings.Join(want, " ") {
+				t.Errorf("Got commands %q; want %q", got, want)
+			}
+		})
+	}
+}
+
+type discard struct{}
+
+func (discard) Write(p []byte) (int, error) { return len(p), nil }
+
+// A fakeUDPServer is a fake AniDB UDP API server for testing.
+type fakeUDPServer struct {
+	pc      net.PacketConn
+	handler func(cmd string, args url.Values, port int) string
+
+	mu   sync.Mutex
+	cmds []string
+}
+
+func newFakeUDPServer(t *testing.T, h func(cmd string, args url.Values, port int) string) *fakeUDPServer {
+	t.Helper()
+	pc, err := net.ListenPacket("udp", "127.0.0.1:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { pc.Close() })
+	s := &fakeUDPServer{pc: pc, handler: h}
+	go s.serve()
+	return s
+}
+
+func (s *fakeUDPServer) addr() string {
+	return s.pc.LocalAddr().String()
+}
+
+func (s *fakeUDPServer) serve() {
+	buf := make([]byte, 1400)
+	for {
+		n, addr, err := s.pc.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		cmd, rest, _ := strings.Cut(string(buf[:n]), " ")
+		args, err := url.ParseQuery(rest)
+		if err != nil {
+			panic(err)
+		}
+		s.mu.Lock()
+		s.cmds = append(s.cmds, cmd)
+		s.mu.Unlock()
+		resp := s.handler(cmd, args, addr.(*net.UDPAddr).Port)
+		_, _ = s.pc.WriteTo([]byte(fmt.Sprintf("%s %s", args.Get("tag"), resp)), addr)
+	}
+}
+
+// commands returns the commands received so far.
+func (s *fakeUDPServer) commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.cmds...)
+}
@@ -0,0 +1,44 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package anidb
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUDPConfig_Validate(t *testing.T) {
+	cfg := UDPConfig{ClientVersion: -1}
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Expected error")
+	}
+	for _, want := range []string{"missing server", "missing client name", "invalid client version -1"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Got error %q; want it to contain %q", err, want)
+		}
+	}
+}
+
+func TestUDPConfig_Validate_ok(t *testing.T) {
+	cfg := UDPConfig{
+		Server:        DefaultUDPServer,
+		ClientName:    "test",
+		ClientVersion: 1,
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Got error %s", err)
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.felesatra.moe/anidb/udpapi/codes"
+)
+
+// An Anime is the decoded result of an ANIME command.
+// Only the fields requested in the amask are set.
+type Anime struct {
+	AID       int       `anidb:"aid"`
+	DateFlags DateFlags `anidb:"dateflags"`
+	// Year is the year or range of years, like "2019-2020".
+	Year string `anidb:"year"`
+	Type string `anidb:"type"`
+	// RelatedAIDs and RelatedAIDTypes are parallel lists of related
+	// anime and their relation types.
+	RelatedAIDs     []int `anidb:"related aid list"`
+	RelatedAIDTypes []int `anidb:"related aid type"`
+
+	RomajiName  string   `anidb:"romaji name"`
+	KanjiName   string   `anidb:"kanji name"`
+	EnglishName string   `anidb:"english name"`
+	OtherName   string   `anidb:"other name"`
+	ShortNames  []string `anidb:"short name list"`
+	Synonyms    []string `anidb:"synonym list"`
+
+	Episodes        int `anidb:"episodes"`
+	HighestEpisode  int `anidb:"highest episode number"`
+	SpecialEpisodes int `anidb:"special ep count"`
+	// AirDate and EndDate are zero if unknown.
+	AirDate time.Time `anidb:"air date"`
+	EndDate time.Time `anidb:"end date"`
+	URL     string    `anidb:"url"`
+	Picname string    `anidb:"picname"`
+
+	// Ratings are multiplied by 100.
+	Rating        int  `anidb:"rating"`
+	VoteCount     int  `anidb:"vote count"`
+	TempRating    int  `anidb:"temp rating"`
+	TempVoteCount int  `anidb:"temp vote count"`
+	ReviewRating  int  `anidb:"average review rating"`
+	ReviewCount   int  `anidb:"review count"`
+	Restricted    bool `anidb:"is 18+ restricted"`
+
+	CharacterIDs []int `anidb:"character id list,comma"`
+}
+
+// AnimeByAID calls the ANIME command by aid.
+// The returned error wraps a [codes.ReturnCode] if applicable.
+func (c *Client) AnimeByAID(ctx context.Context, aid int, amask AnimeAmask) (Anime, error) {
+	v, err := c.sessionValues(ctx)
+	if err != nil {
+		return Anime{}, fmt.Errorf("udpapi AnimeByAID: %w", err)
+	}
+	v.Set("aid", strconv.Itoa(aid))
+	v.Set("amask", formatMask(amask[:]))
+	resp, err := c.request(ctx, "ANIME", v)
+	if err != nil {
+		return Anime{}, fmt.Errorf("udpapi AnimeByAID: %w", err)
+	}
+	if resp.Code != 230 {
+		return Anime{}, fmt.Errorf("udpapi AnimeByAID: %w", codeError("ANIME", v, resp))
+	}
+	if n := len(resp.RawRows); n != 1 {
+		return Anime{}, fmt.Errorf("udpapi AnimeByAID: got unexpected number of rows %d", n)
+	}
+	a, err := DecodeAnime(amask, resp.RawRows[0])
+	if err != nil {
+		return Anime{}, fmt.Errorf("udpapi AnimeByAID: %w", err)
+	}
+	return a, nil
+}
+
+// AnimeDescription calls the ANIMEDESC command for each part of an
+// anime's description and returns the concatenated description.
+// The requests are made in sequence, so they are rate limited like
+// any other request.
+// The returned error wraps a [codes.ReturnCode] if applicable, such
+// as [codes.NO_SUCH_DESCRIPTION].
+func (c *Client) AnimeDescription(ctx context.Context, aid int) (string, error) {
+	var sb strings.Builder
+	for part, parts := 0, 1; part < parts; part++ {
+		v, err := c.sessionValues(ctx)
+		if err != nil {
+			return "", fmt.Errorf("udpapi AnimeDescription: %w", err)
+		}
+		v.Set("aid", strconv.Itoa(aid))
+		v.Set("part", strconv.Itoa(part))
+		resp, err := c.request(ctx, "ANIMEDESC", v)
+		if err != nil {
+			return "", fmt.Errorf("udpapi AnimeDescription: %w", err)
+		}
+		if resp.Code != codes.ANIME_DESCRIPTION {
+			return "", fmt.Errorf("udpapi AnimeDescription: %w", codeError("ANIMEDESC", v, resp))
+		}
+		if n := len(resp.Rows); n != 1 {
+			return "", fmt.Errorf("udpapi AnimeDescription: got unexpected number of rows %d", n)
+		}
+		row := resp.Rows[0]
+		if n := len(row); n < 3 {
+			return "", fmt.Errorf("udpapi AnimeDescription: got unexpected number of fields %d", n)
+		}
+		if parts, err = strconv.Atoi(row[1]); err != nil {
+			return "", fmt.Errorf("udpapi AnimeDescription: %s", err)
+		}
+		sb.WriteString(strings.Join(row[2:], "|"))
+	}
+	return sb.String(), nil
+}
+
+// DecodeAnime decodes an ANIME response row returned for the given
+// amask.
+// The row should be taken from [Response.RawRows]; see [DecodeRow].
+func DecodeAnime(amask AnimeAmask, row []string) (Anime, error) {
+	var a Anime
+	if err := decodeAnimeRow(amask, row, &a); err != nil {
+		return Anime{}, fmt.Errorf("decode anime: %w", err)
+	}
+	return a, nil
+}
+
+// parseUnixTime parses a Unix timestamp.
+// Zero and the empty string are parsed as the zero time.
+func parseUnixTime(s string) (time.Time, error) {
+	if s == "" || s == "0" {
+		return time.Time{}, nil
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(n, 0).UTC(), nil
+}
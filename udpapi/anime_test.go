@@ -0,0 +1,117 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.felesatra.moe/anidb/udpapi/codes"
+)
+
+func TestClient_AnimeByAID(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	var m AnimeAmask
+	m.Set("aid", "year", "type", "related aid list", "related aid type",
+		"romaji name", "english name", "synonym list",
+		"episodes", "air date", "end date", "rating", "vote count",
+		"is 18+ restricted")
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		if cmd != "ANIME" || args.Get("aid") != "8076" || args.Get("amask") != formatMask(m[:]) {
+			return "505 ILLEGAL INPUT OR ACCESS DENIED"
+		}
+		return "230 ANIME\n8076|2020-2020|TV Series|15284'16010|2'1|Itai no wa Iya nano de Bougyoryoku ni Kyokufuri Shitai to Omoimasu.|BOFURI|Bofuri'I Don`t Want to Get Hurt|12|1578441600|1585699200|767|3120|0"
+	})
+	s.client.sessionKey.set("key")
+	got, err := s.client.AnimeByAID(ctx, 8076, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Anime{
+		AID:             8076,
+		Year:            "2020-2020",
+		Type:            "TV Series",
+		RelatedAIDs:     []int{15284, 16010},
+		RelatedAIDTypes: []int{2, 1},
+		RomajiName:      "Itai no wa Iya nano de Bougyoryoku ni Kyokufuri Shitai to Omoimasu.",
+		EnglishName:     "BOFURI",
+		Synonyms:        []string{"Bofuri", "I Don't Want to Get Hurt"},
+		Episodes:        12,
+		AirDate:         time.Date(2020, 1, 8, 0, 0, 0, 0, time.UTC),
+		EndDate:         time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC),
+		Rating:          767,
+		VoteCount:       3120,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %#v; want %#v", got, want)
+	}
+}
+
+func TestDecodeAnime_wrongFields(t *testing.T) {
+	t.Parallel()
+	var m AnimeAmask
+	m.Set("aid", "year")
+	if _, err := DecodeAnime(m, []string{"1"}); err == nil {
+		t.Errorf("Expected error")
+	}
+}
+
+func TestClient_AnimeDescription(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		if cmd != "ANIMEDESC" || args.Get("aid") != "8076" {
+			return "505 ILLEGAL INPUT OR ACCESS DENIED"
+		}
+		switch args.Get("part") {
+		case "0":
+			return "233 ANIMEDESC\n0|3|Maple puts all<br />her points "
+		case "1":
+			return "233 ANIMEDESC\n1|3|into defense"
+		case "2":
+			return "233 ANIMEDESC\n2|3|."
+		}
+		return "333 NO SUCH DESCRIPTION"
+	})
+	s.client.sessionKey.set("key")
+	got, err := s.client.AnimeDescription(ctx, 8076)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Maple puts all\nher points into defense."
+	if got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+	if n := len(s.requests()); n != 3 {
+		t.Errorf("Got %d requests; want 3", n)
+	}
+}
+
+func TestClient_AnimeDescription_noDescription(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "333 NO SUCH DESCRIPTION"
+	})
+	s.client.sessionKey.set("key")
+	_, err := s.client.AnimeDescription(ctx, 8076)
+	if !errors.Is(err, codes.NO_SUCH_DESCRIPTION) {
+		t.Errorf("Got error %v; want %v", err, codes.NO_SUCH_DESCRIPTION)
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// errBackoffExhausted is returned when a backoff has no attempts left.
+var errBackoffExhausted = errors.New("backoff attempts exhausted")
+
+// A backoff generates delays for exponential backoff with jitter.
+// This is not concurrent safe.
+type backoff struct {
+	// base is the first delay.
+	base time.Duration
+	// cap is the maximum delay, before jitter.
+	// If zero, there is no maximum.
+	cap time.Duration
+	// maxAttempts is the maximum number of delays.
+	// If zero, there is no maximum.
+	maxAttempts int
+	// jitter is the fraction of each delay to randomize.
+	// For example, 0.2 gives delays between 80% and 120% of the
+	// exponential delay.
+	jitter float64
+	// rand returns a random number in [0, 1).
+	// If nil, math/rand is used.
+	rand func() float64
+
+	attempt int
+}
+
+// next returns the next delay.
+// Returns false if there are no attempts left.
+func (b *backoff) next() (time.Duration, bool) {
+	if b.maxAttempts > 0 && b.attempt >= b.maxAttempts {
+		return 0, false
+	}
+	d := b.base
+	for i := 0; i < b.attempt; i++ {
+		d *= 2
+		if b.cap > 0 && d >= b.cap {
+			break
+		}
+	}
+	if b.cap > 0 && d > b.cap {
+		d = b.cap
+	}
+	b.attempt++
+	if b.jitter > 0 {
+		r := rand.Float64
+		if b.rand != nil {
+			r = b.rand
+		}
+		d = time.Duration(float64(d) * (1 + b.jitter*(2*r()-1)))
+	}
+	return d, true
+}
+
+// wait waits for the next delay.
+// Returns an error if ctx is done first or there are no attempts left.
+func (b *backoff) wait(ctx context.Context) error {
+	d, ok := b.next()
+	if !ok {
+		return errBackoffExhausted
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// reset resets the backoff to the first delay.
+func (b *backoff) reset() {
+	b.attempt = 0
+}
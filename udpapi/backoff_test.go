@@ -0,0 +1,100 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBackoff_next(t *testing.T) {
+	t.Parallel()
+	b := backoff{
+		base:        time.Second,
+		cap:         5 * time.Second,
+		maxAttempts: 5,
+	}
+	var got []time.Duration
+	for {
+		d, ok := b.next()
+		if !ok {
+			break
+		}
+		got = append(got, d)
+	}
+	want := []time.Duration{
+		time.Second,
+		2 * time.Second,
+		4 * time.Second,
+		5 * time.Second,
+		5 * time.Second,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v; want %v", got, want)
+	}
+	b.reset()
+	if d, _ := b.next(); d != time.Second {
+		t.Errorf("Got %v after reset; want %v", d, time.Second)
+	}
+}
+
+func TestBackoff_jitter(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		r    float64
+		want time.Duration
+	}{
+		{0, 800 * time.Millisecond},
+		{0.5, time.Second},
+		{0.75, 1100 * time.Millisecond},
+	}
+	for _, c := range cases {
+		b := backoff{
+			base:   time.Second,
+			jitter: 0.2,
+			rand:   func() float64 { return c.r },
+		}
+		if d, _ := b.next(); d != c.want {
+			t.Errorf("With rand %v: got %v; want %v", c.r, d, c.want)
+		}
+	}
+}
+
+func TestBackoff_wait(t *testing.T) {
+	t.Parallel()
+	t.Run("canceled", func(t *testing.T) {
+		t.Parallel()
+		ctx, cf := context.WithCancel(context.Background())
+		cf()
+		b := backoff{base: time.Hour}
+		if err := b.wait(ctx); !errors.Is(err, context.Canceled) {
+			t.Errorf("Got error %v; want %v", err, context.Canceled)
+		}
+	})
+	t.Run("exhausted", func(t *testing.T) {
+		t.Parallel()
+		b := backoff{base: time.Millisecond, maxAttempts: 1}
+		ctx := testContext(t, time.Second)
+		if err := b.wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if err := b.wait(ctx); !errors.Is(err, errBackoffExhausted) {
+			t.Errorf("Got error %v; want %v", err, errBackoffExhausted)
+		}
+	})
+}
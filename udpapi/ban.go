@@ -0,0 +1,120 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.felesatra.moe/anidb/udpapi/codes"
+)
+
+// DefaultBanLockout is the default for [Client.BanLockout].
+const DefaultBanLockout = 30 * time.Minute
+
+// ErrLockedOut is returned for requests made while the client is
+// locked out after being banned.
+// See [Client.BanLockout].
+var ErrLockedOut = errors.New("client locked out after ban")
+
+// A BannedError is returned when the server responds with BANNED.
+// It wraps [codes.BANNED].
+type BannedError struct {
+	Reason string
+	// Cooldown is how long the client refuses further requests.
+	Cooldown time.Duration
+}
+
+func (e *BannedError) Error() string {
+	return fmt.Sprintf("banned: %s (locked out for %s)", e.Reason, e.Cooldown)
+}
+
+func (e *BannedError) Unwrap() error {
+	return codes.BANNED
+}
+
+// A ClientRejectedError is returned when the server rejects the
+// client itself, with CLIENT_VERSION_OUTDATED or CLIENT_BANNED.
+// It wraps the return code.
+type ClientRejectedError struct {
+	Code codes.ReturnCode
+	// Reason is the reason given for CLIENT_BANNED.
+	Reason string
+}
+
+func (e *ClientRejectedError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("client rejected: %s", e.Code)
+	}
+	return fmt.Sprintf("client rejected: %s: %s", e.Code, e.Reason)
+}
+
+func (e *ClientRejectedError) Unwrap() error {
+	return e.Code
+}
+
+// LockedOutUntil returns the time until which the client refuses
+// requests after being banned.
+// It returns the zero time if the client has not been banned.
+func (c *Client) LockedOutUntil() time.Time {
+	return c.lockout.get()
+}
+
+// checkLockout returns an error if the client is locked out.
+func (c *Client) checkLockout() error {
+	until := c.lockout.get()
+	if time.Now().Before(until) {
+		return fmt.Errorf("%w until %s", ErrLockedOut, until.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// checkBanned returns an error for responses that reject the user or
+// client, locking out the client if needed.
+func (c *Client) checkBanned(resp Response) error {
+	switch resp.Code {
+	case codes.BANNED:
+		d := c.banLockout()
+		c.lockOut(d)
+		var reason string
+		if len(resp.Rows) > 0 {
+			reason = strings.Join(resp.Rows[0], "|")
+		}
+		return &BannedError{Reason: reason, Cooldown: d}
+	case codes.CLIENT_BANNED:
+		c.lockOut(c.banLockout())
+		_, reason, _ := strings.Cut(resp.Header, " - ")
+		return &ClientRejectedError{Code: resp.Code, Reason: reason}
+	case codes.CLIENT_VERSION_OUTDATED:
+		return &ClientRejectedError{Code: resp.Code}
+	default:
+		return nil
+	}
+}
+
+func (c *Client) banLockout() time.Duration {
+	if c.BanLockout > 0 {
+		return c.BanLockout
+	}
+	return DefaultBanLockout
+}
+
+func (c *Client) lockOut(d time.Duration) {
+	until := time.Now().Add(d)
+	c.logger.Error("Locking out client after ban", "until", until)
+	c.lockout.set(until)
+}
@@ -0,0 +1,100 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"go.felesatra.moe/anidb/udpapi/codes"
+)
+
+func TestClient_banned(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "555 BANNED\nleech"
+	})
+	c := s.client
+	c.BanLockout = time.Hour
+	err := c.PingSimple(ctx)
+	var be *BannedError
+	if !errors.As(err, &be) {
+		t.Fatalf("Got error %v; want BannedError", err)
+	}
+	if be.Reason != "leech" || be.Cooldown != time.Hour {
+		t.Errorf("Got %#v", be)
+	}
+	if !errors.Is(err, codes.BANNED) {
+		t.Errorf("Got error %v; want %v", err, codes.BANNED)
+	}
+	if err := c.PingSimple(ctx); !errors.Is(err, ErrLockedOut) {
+		t.Errorf("Got error %v; want %v", err, ErrLockedOut)
+	}
+	if n := len(s.requests()); n != 1 {
+		t.Errorf("Got %d requests; want 1", n)
+	}
+	if got := c.LockedOutUntil(); time.Until(got) < 59*time.Minute {
+		t.Errorf("Got locked out until %v", got)
+	}
+}
+
+func TestClient_clientRejected(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		desc       string
+		resp       string
+		want       ClientRejectedError
+		wantLocked bool
+	}{
+		{
+			desc: "outdated",
+			resp: "503 CLIENT VERSION OUTDATED",
+			want: ClientRejectedError{Code: codes.CLIENT_VERSION_OUTDATED},
+		},
+		{
+			desc:       "banned",
+			resp:       "504 CLIENT BANNED - too many requests",
+			want:       ClientRejectedError{Code: codes.CLIENT_BANNED, Reason: "too many requests"},
+			wantLocked: true,
+		},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.desc, func(t *testing.T) {
+			t.Parallel()
+			ctx := testContext(t, time.Second)
+			s := newFakeServer(t, func(cmd string, args url.Values) string {
+				return c.resp
+			})
+			err := s.client.PingSimple(ctx)
+			var ce *ClientRejectedError
+			if !errors.As(err, &ce) {
+				t.Fatalf("Got error %v; want ClientRejectedError", err)
+			}
+			if *ce != c.want {
+				t.Errorf("Got %#v; want %#v", *ce, c.want)
+			}
+			if !errors.Is(err, c.want.Code) {
+				t.Errorf("Got error %v; want %v", err, c.want.Code)
+			}
+			if got := !s.client.LockedOutUntil().IsZero(); got != c.wantLocked {
+				t.Errorf("Got locked out %v; want %v", got, c.wantLocked)
+			}
+		})
+	}
+}
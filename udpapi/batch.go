@@ -0,0 +1,118 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"context"
+	"sync"
+)
+
+// A BatchResult is the result for one item of a batch operation.
+type BatchResult[R any] struct {
+	Value R
+	Err   error
+}
+
+// Batch calls f for each item in order and returns the results for
+// each item.
+// It is [BatchWith] with the zero BatchOptions, so items are
+// processed one at a time.
+//
+// Batch does not chunk items, as the UDP API takes one item per
+// command, and does not pace requests itself; see [BatchWith].
+func Batch[T, R any](ctx context.Context, items []T, f func(context.Context, T) (R, error)) []BatchResult[R] {
+	return BatchWith(ctx, items, BatchOptions{}, f)
+}
+
+// BatchOptions configures [BatchWith].
+type BatchOptions struct {
+	// Concurrency is the maximum number of items processed at once.
+	// Requests are still paced by the rate limiter, but concurrent
+	// items can overlap waiting for responses.
+	// If zero or negative, items are processed one at a time.
+	Concurrency int
+	// Progress, if set, is called after each item with the number of
+	// items done and the total.
+	// Calls are not concurrent.
+	Progress func(done, total int)
+}
+
+// BatchWith calls f for each item and returns the results for each
+// item, in the same order as items.
+// Items are started in order.
+//
+// The UDP API takes one item per command, so there is no chunking;
+// f is called once per item.
+// BatchWith does no pacing of its own; f should make its requests
+// using [Client] methods, which are rate limited.
+//
+// Requests are made with [PriorityBatch] unless ctx already has a
+// priority set with [WithPriority], so they do not starve
+// interactive requests.
+//
+// An error for one item does not stop the batch.
+// If ctx is done, f is not called for the remaining items and their
+// results contain the context error.
+func BatchWith[T, R any](ctx context.Context, items []T, o BatchOptions, f func(context.Context, T) (R, error)) []BatchResult[R] {
+	if _, ok := priorityFromContext(ctx); !ok {
+		ctx = WithPriority(ctx, PriorityBatch)
+	}
+	n := o.Concurrency
+	if n < 1 {
+		n = 1
+	}
+	res := make([]BatchResult[R], len(items))
+	var mu sync.Mutex
+	done := 0
+	finish := func() {
+		if o.Progress == nil {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		done++
+		o.Progress(done, len(items))
+	}
+	sem := make(chan struct{}, n)
+	var wg sync.WaitGroup
+	for i, item := range items {
+		if err := ctx.Err(); err != nil {
+			res[i].Err = err
+			finish()
+			continue
+		}
+		if n == 1 {
+			res[i].Value, res[i].Err = f(ctx, item)
+			finish()
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			res[i].Err = ctx.Err()
+			finish()
+			continue
+		}
+		wg.Add(1)
+		go func(i int, item T) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			res[i].Value, res[i].Err = f(ctx, item)
+			finish()
+		}(i, item)
+	}
+	wg.Wait()
+	return res
+}
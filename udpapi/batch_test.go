@@ -0,0 +1,181 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestBatch(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, 5*time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		if n, _ := strconv.Atoi(args.Get("n")); n%7 == 0 {
+			return "505 ILLEGAL INPUT OR ACCESS DENIED"
+		}
+		return "300 PONG"
+	})
+	c := s.client
+	l := &countLimiter{}
+	c.limiter = l
+	const n = 48
+	items := make([]int, n)
+	for i := range items {
+		items[i] = i
+	}
+	got := Batch(ctx, items, func(ctx context.Context, i int) (int, error) {
+		v := make(url.Values)
+		v.Set("n", strconv.Itoa(i))
+		resp, err := c.request(ctx, "PING", v)
+		if err != nil {
+			return 0, err
+		}
+		if resp.Code != 300 {
+			return 0, resp.Code
+		}
+		return i, nil
+	})
+	if len(got) != n {
+		t.Fatalf("Got %d results; want %d", len(got), n)
+	}
+	for i, r := range got {
+		if i%7 == 0 {
+			if r.Err == nil {
+				t.Errorf("Item %d: expected error", i)
+			}
+			continue
+		}
+		if r.Err != nil {
+			t.Errorf("Item %d: got error %s", i, r.Err)
+		}
+		if r.Value != i {
+			t.Errorf("Item %d: got value %d", i, r.Value)
+		}
+	}
+	if reqs := s.requests(); len(reqs) != n {
+		t.Errorf("Got %d requests; want %d", len(reqs), n)
+	}
+	// Every request is paced by the limiter.
+	if got := l.n.Load(); got != n {
+		t.Errorf("Got %d limiter waits; want %d", got, n)
+	}
+}
+
+// A countLimiter counts waits without limiting.
+type countLimiter struct {
+	n atomic.Int32
+}
+
+func (l *countLimiter) Wait(ctx context.Context) error {
+	l.n.Add(1)
+	return nil
+}
+
+func TestBatch_canceled(t *testing.T) {
+	t.Parallel()
+	ctx, cf := context.WithCancel(context.Background())
+	defer cf()
+	var calls int
+	got := Batch(ctx, []int{1, 2, 3}, func(ctx context.Context, i int) (int, error) {
+		calls++
+		if i == 2 {
+			cf()
+		}
+		return i, nil
+	})
+	if calls != 2 {
+		t.Errorf("Got %d calls; want 2", calls)
+	}
+	if err := got[2].Err; !errors.Is(err, context.Canceled) {
+		t.Errorf("Got error %v; want %v", err, context.Canceled)
+	}
+}
+
+func TestBatchWith(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, 5*time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "300 PONG"
+	})
+	c := s.client
+	c.limiter = &limiter{
+		short: rate.NewLimiter(rate.Inf, 1),
+		long:  rate.NewLimiter(rate.Inf, 1),
+	}
+	const n = 50
+	items := make([]int, n)
+	for i := range items {
+		items[i] = i
+	}
+	var running, maxRunning atomic.Int32
+	var progress []int
+	o := BatchOptions{
+		Concurrency: 4,
+		Progress: func(done, total int) {
+			if total != n {
+				t.Errorf("Got total %d; want %d", total, n)
+			}
+			progress = append(progress, done)
+		},
+	}
+	got := BatchWith(ctx, items, o, func(ctx context.Context, i int) (int, error) {
+		r := running.Add(1)
+		defer running.Add(-1)
+		for {
+			m := maxRunning.Load()
+			if r <= m || maxRunning.CompareAndSwap(m, r) {
+				break
+			}
+		}
+		if _, err := c.request(ctx, "PING", url.Values{}); err != nil {
+			return 0, err
+		}
+		return i * 2, nil
+	})
+	for i, r := range got {
+		if r.Err != nil || r.Value != i*2 {
+			t.Errorf("Item %d: got %d, %v; want %d", i, r.Value, r.Err, i*2)
+		}
+	}
+	if m := maxRunning.Load(); m > 4 {
+		t.Errorf("Got %d concurrent items; want at most 4", m)
+	}
+	if len(progress) != n || progress[n-1] != n {
+		t.Errorf("Got progress %v; want 1 to %d", progress, n)
+	}
+}
+
+func TestBatchWith_canceled(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	got := BatchWith(ctx, []int{1, 2, 3}, BatchOptions{Concurrency: 2}, func(ctx context.Context, i int) (int, error) {
+		t.Errorf("f called for item %d", i)
+		return i, nil
+	})
+	for i, r := range got {
+		if !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("Item %d: got error %v; want %v", i, r.Err, context.Canceled)
+		}
+	}
+}
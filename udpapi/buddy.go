@@ -0,0 +1,234 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.felesatra.moe/anidb/udpapi/codes"
+)
+
+// A BuddyListState is the state of an entry in the buddy list.
+type BuddyListState int
+
+const (
+	BuddyPending  BuddyListState = 0
+	BuddyAccepted BuddyListState = 1
+)
+
+func (s BuddyListState) String() string {
+	switch s {
+	case BuddyPending:
+		return "pending"
+	case BuddyAccepted:
+		return "accepted"
+	default:
+		return fmt.Sprintf("BuddyListState(%d)", int(s))
+	}
+}
+
+// A BuddyOnlineState is the online state of a buddy.
+type BuddyOnlineState int
+
+const (
+	BuddyOffline BuddyOnlineState = 0
+	BuddyOnline  BuddyOnlineState = 1
+)
+
+func (s BuddyOnlineState) String() string {
+	switch s {
+	case BuddyOffline:
+		return "offline"
+	case BuddyOnline:
+		return "online"
+	default:
+		return fmt.Sprintf("BuddyOnlineState(%d)", int(s))
+	}
+}
+
+// A Buddy is a row returned by the BUDDYLIST command.
+type Buddy struct {
+	UID   int
+	Name  string
+	State BuddyListState
+}
+
+// A BuddyStateRow is a row returned by the BUDDYSTATE command.
+type BuddyStateRow struct {
+	UID   int
+	State BuddyOnlineState
+}
+
+// A BuddyPage describes the range of rows in a paged buddy response.
+type BuddyPage struct {
+	Start int
+	End   int
+	Total int
+}
+
+// BuddyAdd calls the BUDDYADD command.
+// The returned error wraps a [codes.ReturnCode] if applicable,
+// such as BUDDY_ALREADY_ADDED.
+func (c *Client) BuddyAdd(ctx context.Context, uid int) error {
+	if err := c.buddyCmd(ctx, "BUDDYADD", uid, codes.BUDDY_ADDED); err != nil {
+		return fmt.Errorf("udpapi BuddyAdd: %w", err)
+	}
+	return nil
+}
+
+// BuddyDel calls the BUDDYDEL command.
+// The returned error wraps a [codes.ReturnCode] if applicable,
+// such as NO_SUCH_BUDDY.
+func (c *Client) BuddyDel(ctx context.Context, uid int) error {
+	if err := c.buddyCmd(ctx, "BUDDYDEL", uid, codes.BUDDY_DELETED); err != nil {
+		return fmt.Errorf("udpapi BuddyDel: %w", err)
+	}
+	return nil
+}
+
+// BuddyAccept calls the BUDDYACCEPT command.
+// The returned error wraps a [codes.ReturnCode] if applicable,
+// such as BUDDY_ALREADY_ACCEPTED.
+func (c *Client) BuddyAccept(ctx context.Context, uid int) error {
+	if err := c.buddyCmd(ctx, "BUDDYACCEPT", uid, codes.BUDDY_ACCEPTED); err != nil {
+		return fmt.Errorf("udpapi BuddyAccept: %w", err)
+	}
+	return nil
+}
+
+// BuddyDeny calls the BUDDYDENY command.
+// The returned error wraps a [codes.ReturnCode] if applicable,
+// such as BUDDY_ALREADY_DENIED.
+func (c *Client) BuddyDeny(ctx context.Context, uid int) error {
+	if err := c.buddyCmd(ctx, "BUDDYDENY", uid, codes.BUDDY_DENIED); err != nil {
+		return fmt.Errorf("udpapi BuddyDeny: %w", err)
+	}
+	return nil
+}
+
+// buddyCmd sends a buddy command for a user that has no response
+// data.
+func (c *Client) buddyCmd(ctx context.Context, cmd string, uid int, want codes.ReturnCode) error {
+	v, err := c.sessionValues(ctx)
+	if err != nil {
+		return err
+	}
+	v.Set("uid", strconv.Itoa(uid))
+	resp, err := c.request(ctx, cmd, v)
+	if err != nil {
+		return err
+	}
+	if resp.Code != want {
+		return codeError(cmd, v, resp)
+	}
+	return nil
+}
+
+// BuddyList calls the BUDDYLIST command.
+// startAt is the index of the first row to return.
+// The returned error wraps a [codes.ReturnCode] if applicable.
+func (c *Client) BuddyList(ctx context.Context, startAt int) (BuddyPage, []Buddy, error) {
+	p, rows, err := c.buddyPage(ctx, "BUDDYLIST", startAt, codes.BUDDY_LIST)
+	if err != nil {
+		return BuddyPage{}, nil, fmt.Errorf("udpapi BuddyList: %w", err)
+	}
+	buddies := make([]Buddy, len(rows))
+	for i, row := range rows {
+		if n := len(row); n != 3 {
+			return BuddyPage{}, nil, fmt.Errorf("udpapi BuddyList: got unexpected number of fields %d", n)
+		}
+		uid, err := strconv.Atoi(row[0])
+		if err != nil {
+			return BuddyPage{}, nil, fmt.Errorf("udpapi BuddyList: %s", err)
+		}
+		state, err := strconv.Atoi(row[2])
+		if err != nil {
+			return BuddyPage{}, nil, fmt.Errorf("udpapi BuddyList: %s", err)
+		}
+		buddies[i] = Buddy{UID: uid, Name: row[1], State: BuddyListState(state)}
+	}
+	return p, buddies, nil
+}
+
+// BuddyState calls the BUDDYSTATE command.
+// startAt is the index of the first row to return.
+// The returned error wraps a [codes.ReturnCode] if applicable.
+func (c *Client) BuddyState(ctx context.Context, startAt int) (BuddyPage, []BuddyStateRow, error) {
+	p, rows, err := c.buddyPage(ctx, "BUDDYSTATE", startAt, codes.BUDDY_STATE)
+	if err != nil {
+		return BuddyPage{}, nil, fmt.Errorf("udpapi BuddyState: %w", err)
+	}
+	states := make([]BuddyStateRow, len(rows))
+	for i, row := range rows {
+		if n := len(row); n != 2 {
+			return BuddyPage{}, nil, fmt.Errorf("udpapi BuddyState: got unexpected number of fields %d", n)
+		}
+		uid, err := strconv.Atoi(row[0])
+		if err != nil {
+			return BuddyPage{}, nil, fmt.Errorf("udpapi BuddyState: %s", err)
+		}
+		state, err := strconv.Atoi(row[1])
+		if err != nil {
+			return BuddyPage{}, nil, fmt.Errorf("udpapi BuddyState: %s", err)
+		}
+		states[i] = BuddyStateRow{UID: uid, State: BuddyOnlineState(state)}
+	}
+	return p, states, nil
+}
+
+// buddyPage sends a paged buddy command.
+func (c *Client) buddyPage(ctx context.Context, cmd string, startAt int, want codes.ReturnCode) (BuddyPage, [][]string, error) {
+	v, err := c.sessionValues(ctx)
+	if err != nil {
+		return BuddyPage{}, nil, err
+	}
+	v.Set("startat", strconv.Itoa(startAt))
+	resp, err := c.request(ctx, cmd, v)
+	if err != nil {
+		return BuddyPage{}, nil, err
+	}
+	if resp.Code != want {
+		return BuddyPage{}, nil, codeError(cmd, v, resp)
+	}
+	p, err := parseBuddyPage(resp.Header)
+	if err != nil {
+		return BuddyPage{}, nil, err
+	}
+	return p, resp.Rows, nil
+}
+
+// parseBuddyPage parses the header of a paged buddy response, like
+// "0|1|2 BUDDY LIST".
+func parseBuddyPage(h string) (BuddyPage, error) {
+	var p BuddyPage
+	f, _, err := SplitHeaderFields(h, 1)
+	if err != nil {
+		return p, err
+	}
+	parts := strings.Split(f[0], "|")
+	if len(parts) != 3 {
+		return p, fmt.Errorf("invalid response header %q", h)
+	}
+	for i, ptr := range []*int{&p.Start, &p.End, &p.Total} {
+		*ptr, err = strconv.Atoi(parts[i])
+		if err != nil {
+			return p, fmt.Errorf("invalid response header %q: %s", h, err)
+		}
+	}
+	return p, nil
+}
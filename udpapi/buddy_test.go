@@ -0,0 +1,104 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.felesatra.moe/anidb/udpapi/codes"
+)
+
+func TestClient_BuddyAdd(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		desc    string
+		resp    string
+		wantErr error
+	}{
+		{desc: "added", resp: "255 BUDDY ADDED"},
+		{desc: "already added", resp: "355 BUDDY ALREADY ADDED", wantErr: codes.BUDDY_ALREADY_ADDED},
+		{desc: "no user", resp: "394 NO SUCH USER", wantErr: codes.NO_SUCH_USER},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.desc, func(t *testing.T) {
+			t.Parallel()
+			ctx := testContext(t, time.Second)
+			s := newFakeServer(t, func(cmd string, args url.Values) string {
+				return c.resp
+			})
+			s.client.sessionKey.set("key")
+			err := s.client.BuddyAdd(ctx, 1234)
+			if !errors.Is(err, c.wantErr) {
+				t.Errorf("Got error %v; want %v", err, c.wantErr)
+			}
+			r := s.requests()[0]
+			if r.cmd != "BUDDYADD" || r.args.Get("uid") != "1234" {
+				t.Errorf("Got request %v", r)
+			}
+		})
+	}
+}
+
+func TestClient_BuddyList(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "253 0|1|2 BUDDY LIST\n1234|ionasal|1\n5678|rin|0"
+	})
+	s.client.sessionKey.set("key")
+	p, got, err := s.client.BuddyList(ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (BuddyPage{Start: 0, End: 1, Total: 2}); p != want {
+		t.Errorf("Got page %v; want %v", p, want)
+	}
+	want := []Buddy{
+		{UID: 1234, Name: "ionasal", State: BuddyAccepted},
+		{UID: 5678, Name: "rin", State: BuddyPending},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v; want %v", got, want)
+	}
+}
+
+func TestClient_BuddyState(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "254 0|0|1 BUDDY STATE\n1234|1"
+	})
+	s.client.sessionKey.set("key")
+	_, got, err := s.client.BuddyState(ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []BuddyStateRow{{UID: 1234, State: BuddyOnline}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v; want %v", got, want)
+	}
+}
+
+func TestParseBuddyPage(t *testing.T) {
+	t.Parallel()
+	if _, err := parseBuddyPage("BUDDY LIST"); err == nil {
+		t.Errorf("Expected error")
+	}
+}
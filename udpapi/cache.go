@@ -0,0 +1,281 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DefaultCacheTTLs returns the default TTLs for cached responses per
+// command, used if [Client.CacheTTLs] is nil.
+// AniDB asks clients to cache data and avoid requesting the same
+// data repeatedly.
+//
+// A new map is returned each call, so it can be modified, such as
+// to make a custom [Client.CacheTTLs].
+//
+// FILE responses are not cached if the fmask selects mylist fields,
+// such as "mylist viewed", as those change with the user's mylist.
+func DefaultCacheTTLs() map[string]time.Duration {
+	return maps.Clone(defaultCacheTTLs)
+}
+
+var defaultCacheTTLs = map[string]time.Duration{
+	"FILE":      7 * 24 * time.Hour,
+	"ANIME":     24 * time.Hour,
+	"EPISODE":   24 * time.Hour,
+	"GROUP":     7 * 24 * time.Hour,
+	"CHARACTER": 7 * 24 * time.Hour,
+}
+
+// A ResponseCache stores responses for [Client.Cache].
+// Implementations must be safe for concurrent use.
+type ResponseCache interface {
+	// Get returns a cached response and the time it was stored.
+	// The returned response must not share rows with the cache, as
+	// callers may modify it.
+	Get(key string) (_ Response, stored time.Time, ok bool)
+	// Put stores a response.
+	// The cache must not keep the rows of r, as callers may modify
+	// them.
+	// Implementations may drop responses, such as on errors, as a
+	// cache miss only costs a request.
+	Put(key string, r Response, stored time.Time)
+}
+
+// cacheKey returns the cache key for a request.
+// Session and tag parameters are not part of the key.
+func cacheKey(cmd string, args url.Values) string {
+	v := make(url.Values, len(args))
+	for k, vs := range args {
+		switch k {
+		case "s", "tag":
+		default:
+			v[k] = vs
+		}
+	}
+	return cmd + " " + v.Encode()
+}
+
+// cacheTTL returns the TTL for a command's responses, or 0 if the
+// command is not cached.
+func (c *Client) cacheTTL(cmd string) time.Duration {
+	if c.Cache == nil {
+		return 0
+	}
+	ttls := c.CacheTTLs
+	if ttls == nil {
+		ttls = defaultCacheTTLs
+	}
+	return ttls[cmd]
+}
+
+// fileMylistFields are the FILE fmask fields that depend on the
+// user's mylist.
+var fileMylistFields = []string{
+	"mylist id",
+	"mylist state",
+	"mylist filestate",
+	"mylist viewed",
+	"mylist viewdate",
+	"mylist storage",
+	"mylist source",
+	"mylist other",
+}
+
+// cacheable returns whether the response to a request may be cached.
+// FILE responses selecting mylist fields are not cached, as nothing
+// invalidates them when the mylist is edited.
+func cacheable(cmd string, args url.Values) bool {
+	if cmd != "FILE" {
+		return true
+	}
+	m, err := hex.DecodeString(args.Get("fmask"))
+	if err != nil {
+		return false
+	}
+	for _, f := range fileMylistFields {
+		s := FileFmaskFields[f]
+		if s.byte < len(m) && m[s.byte]&(1<<s.bit) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// cacheInterceptor consults the cache before sending requests for
+// commands with a cache TTL, and caches successful responses.
+func (c *Client) cacheInterceptor(next RequestFunc) RequestFunc {
+	return func(ctx context.Context, cmd string, args url.Values) (Response, error) {
+		ttl := c.cacheTTL(cmd)
+		if ttl <= 0 || !cacheable(cmd, args) {
+			return next(ctx, cmd, args)
+		}
+		key := cacheKey(cmd, args)
+		if r, t, ok := c.Cache.Get(key); ok && time.Since(t) <= ttl {
+			c.logger.Debug("Using cached response", "cmd", cmd)
+			return r, nil
+		}
+		r, err := next(ctx, cmd, args)
+		if err == nil && r.Code >= 200 && r.Code < 300 && !r.Truncated {
+			c.Cache.Put(key, r, time.Now())
+		}
+		return r, err
+	}
+}
+
+// A MemoryCache is an in-memory [ResponseCache] that evicts the
+// least recently used responses.
+type MemoryCache struct {
+	size int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	// lru is ordered from most to least recently used.
+	lru list.List
+}
+
+type memoryEntry struct {
+	key    string
+	r      Response
+	stored time.Time
+}
+
+// NewMemoryCache returns a MemoryCache holding up to size responses.
+func NewMemoryCache(size int) *MemoryCache {
+	return &MemoryCache{
+		size:    size,
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// Get implements [ResponseCache].
+func (c *MemoryCache) Get(key string) (Response, time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return Response{}, time.Time{}, false
+	}
+	c.lru.MoveToFront(e)
+	me := e.Value.(*memoryEntry)
+	return me.r.clone(), me.stored, true
+}
+
+// Put implements [ResponseCache].
+func (c *MemoryCache) Put(key string, r Response, stored time.Time) {
+	r = r.clone()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		e.Value = &memoryEntry{key: key, r: r, stored: stored}
+		c.lru.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&memoryEntry{key: key, r: r, stored: stored})
+	for c.lru.Len() > c.size {
+		e := c.lru.Back()
+		c.lru.Remove(e)
+		delete(c.entries, e.Value.(*memoryEntry).key)
+	}
+}
+
+// clone returns a copy of the response that does not share rows.
+func (r Response) clone() Response {
+	r.Rows = cloneRows(r.Rows)
+	r.RawRows = cloneRows(r.RawRows)
+	return r
+}
+
+func cloneRows(rows [][]string) [][]string {
+	if rows == nil {
+		return nil
+	}
+	c := make([][]string, len(rows))
+	for i, row := range rows {
+		c[i] = slices.Clone(row)
+	}
+	return c
+}
+
+// A DirCache is a [ResponseCache] that stores responses as gob files
+// in a directory, so they persist across runs.
+// Expired responses are not deleted automatically.
+type DirCache struct {
+	// Dir is the cache directory.
+	// It is created if it does not exist.
+	Dir string
+}
+
+type dirEntry struct {
+	Key    string
+	R      Response
+	Stored time.Time
+}
+
+func (c *DirCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:]))
+}
+
+// Get implements [ResponseCache].
+func (c *DirCache) Get(key string) (Response, time.Time, bool) {
+	d, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return Response{}, time.Time{}, false
+	}
+	var e dirEntry
+	if err := gob.NewDecoder(bytes.NewReader(d)).Decode(&e); err != nil || e.Key != key {
+		return Response{}, time.Time{}, false
+	}
+	return e.R, e.Stored, true
+}
+
+// Put implements [ResponseCache].
+// Errors writing the cache are ignored.
+func (c *DirCache) Put(key string, r Response, stored time.Time) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(dirEntry{Key: key, R: r, Stored: stored}); err != nil {
+		return
+	}
+	if err := os.MkdirAll(c.Dir, 0777); err != nil {
+		return
+	}
+	f, err := os.CreateTemp(c.Dir, "tmp")
+	if err != nil {
+		return
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return
+	}
+	if err := f.Close(); err != nil {
+		return
+	}
+	_ = os.Rename(f.Name(), c.path(key))
+}
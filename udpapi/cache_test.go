@@ -0,0 +1,187 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestClient_Cache(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		switch args.Get("fid") {
+		case "1":
+			return "220 FILE\n1|8076"
+		default:
+			return "320 NO SUCH FILE"
+		}
+	})
+	s.client.sessionKey.set("key")
+	s.client.Cache = NewMemoryCache(10)
+	var fmask FileFmask
+	fmask.Set("aid")
+	for i := 0; i < 2; i++ {
+		got, err := s.client.FileByFID(ctx, 1, fmask, FileAmask{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{"1", "8076"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("Got %q; want %q", got, want)
+		}
+	}
+	// Errors are not cached.
+	for i := 0; i < 2; i++ {
+		if _, err := s.client.FileByFID(ctx, 2, fmask, FileAmask{}); err == nil {
+			t.Errorf("Expected error")
+		}
+	}
+	if n := len(s.requests()); n != 3 {
+		t.Errorf("Got %d requests; want 3", n)
+	}
+
+	// Expired responses are requested again.
+	s.client.CacheTTLs = map[string]time.Duration{"FILE": time.Nanosecond}
+	time.Sleep(time.Millisecond)
+	if _, err := s.client.FileByFID(ctx, 1, fmask, FileAmask{}); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(s.requests()); n != 4 {
+		t.Errorf("Got %d requests; want 4", n)
+	}
+}
+
+func TestClient_Cache_mylistFields(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "220 FILE\n1|1"
+	})
+	s.client.sessionKey.set("key")
+	s.client.Cache = NewMemoryCache(10)
+	var fmask FileFmask
+	fmask.Set("mylist viewed")
+	for i := 0; i < 2; i++ {
+		if _, err := s.client.FileByFID(ctx, 1, fmask, FileAmask{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := len(s.requests()); n != 2 {
+		t.Errorf("Got %d requests; want 2", n)
+	}
+}
+
+func TestClient_Cache_defaultParams(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "220 FILE\n1|8076"
+	})
+	s.client.sessionKey.set("key")
+	s.client.Cache = NewMemoryCache(10)
+	var fmask FileFmask
+	fmask.Set("aid")
+	for _, enc := range []string{"utf8", "utf8", "sjis"} {
+		s.client.DefaultParams = url.Values{"enc": {enc}}
+		if _, err := s.client.FileByFID(ctx, 1, fmask, FileAmask{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := len(s.requests()); n != 2 {
+		t.Errorf("Got %d requests; want 2", n)
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	t.Parallel()
+	a := cacheKey("FILE", url.Values{"fid": {"1"}, "s": {"key1"}, "tag": {"t1"}})
+	b := cacheKey("FILE", url.Values{"fid": {"1"}, "s": {"key2"}, "tag": {"t2"}})
+	if a != b {
+		t.Errorf("Got different keys %q and %q", a, b)
+	}
+	if c := cacheKey("FILE", url.Values{"fid": {"2"}}); c == a {
+		t.Errorf("Got same key %q for different args", c)
+	}
+}
+
+func TestMemoryCache(t *testing.T) {
+	t.Parallel()
+	c := NewMemoryCache(2)
+	now := time.Now()
+	c.Put("a", Response{Code: 1}, now)
+	c.Put("b", Response{Code: 2}, now)
+	// Use a so b is evicted.
+	if _, _, ok := c.Get("a"); !ok {
+		t.Errorf("a missing")
+	}
+	c.Put("c", Response{Code: 3}, now)
+	if _, _, ok := c.Get("b"); ok {
+		t.Errorf("b not evicted")
+	}
+	for _, k := range []string{"a", "c"} {
+		if _, _, ok := c.Get(k); !ok {
+			t.Errorf("%s missing", k)
+		}
+	}
+}
+
+func TestMemoryCache_copiesRows(t *testing.T) {
+	t.Parallel()
+	c := NewMemoryCache(2)
+	r := Response{Code: 1, Rows: [][]string{{"a", "b"}}, RawRows: [][]string{{"a", "b"}}}
+	c.Put("a", r, time.Now())
+	r.Rows[0][0] = "x"
+	got, _, _ := c.Get("a")
+	got.Rows[0][1] = "y"
+	got.RawRows[0][1] = "y"
+	got, _, _ = c.Get("a")
+	want := Response{Code: 1, Rows: [][]string{{"a", "b"}}, RawRows: [][]string{{"a", "b"}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v; want %v", got, want)
+	}
+}
+
+func TestDefaultCacheTTLs(t *testing.T) {
+	t.Parallel()
+	m := DefaultCacheTTLs()
+	for k := range m {
+		delete(m, k)
+	}
+	if len(DefaultCacheTTLs()) == 0 {
+		t.Errorf("DefaultCacheTTLs returned shared map")
+	}
+}
+
+func TestDirCache(t *testing.T) {
+	t.Parallel()
+	c := &DirCache{Dir: filepath.Join(t.TempDir(), "cache")}
+	if _, _, ok := c.Get("FILE fid=1"); ok {
+		t.Errorf("Got cached response for empty cache")
+	}
+	now := time.Unix(1600000000, 0)
+	want := Response{Code: 220, Header: "FILE", Rows: [][]string{{"1", "8076"}}, RawRows: [][]string{{"1", "8076"}}}
+	c.Put("FILE fid=1", want, now)
+	got, stored, ok := c.Get("FILE fid=1")
+	if !ok {
+		t.Fatalf("Response not cached")
+	}
+	if !reflect.DeepEqual(got, want) || !stored.Equal(now) {
+		t.Errorf("Got %#v, %v; want %#v, %v", got, stored, want, now)
+	}
+}
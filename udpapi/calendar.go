@@ -0,0 +1,113 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"go.felesatra.moe/anidb/udpapi/codes"
+)
+
+// A CalendarEntry is a row returned by the CALENDAR command.
+type CalendarEntry struct {
+	AID int
+	// StartDate is the start date of the anime.
+	// See Flags for which parts of the date are known.
+	StartDate time.Time
+	Flags     DateFlags
+}
+
+// DateFlags describe which parts of an anime's start and end dates
+// are known.
+type DateFlags int
+
+// StartDayUnknown returns true if the day of the start date is unknown.
+func (f DateFlags) StartDayUnknown() bool { return f&(1<<0) != 0 }
+
+// StartMonthUnknown returns true if the month and day of the start
+// date are unknown.
+func (f DateFlags) StartMonthUnknown() bool { return f&(1<<1) != 0 }
+
+// EndDayUnknown returns true if the day of the end date is unknown.
+func (f DateFlags) EndDayUnknown() bool { return f&(1<<2) != 0 }
+
+// EndMonthUnknown returns true if the month and day of the end date
+// are unknown.
+func (f DateFlags) EndMonthUnknown() bool { return f&(1<<3) != 0 }
+
+// Ended returns true if the anime has ended.
+func (f DateFlags) Ended() bool { return f&(1<<4) != 0 }
+
+// StartYearUnknown returns true if the year of the start date is
+// unknown.
+func (f DateFlags) StartYearUnknown() bool { return f&(1<<5) != 0 }
+
+// EndYearUnknown returns true if the year of the end date is unknown.
+func (f DateFlags) EndYearUnknown() bool { return f&(1<<6) != 0 }
+
+// Calendar calls the CALENDAR command.
+// An empty calendar returns no entries and no error.
+// The returned error wraps a [codes.ReturnCode] if applicable.
+func (c *Client) Calendar(ctx context.Context) ([]CalendarEntry, error) {
+	v, err := c.sessionValues(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("udpapi Calendar: %w", err)
+	}
+	resp, err := c.request(ctx, "CALENDAR", v)
+	if err != nil {
+		return nil, fmt.Errorf("udpapi Calendar: %w", err)
+	}
+	switch resp.Code {
+	case codes.CALENDAR:
+	case codes.CALENDAR_EMPTY:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("udpapi Calendar: %w", codeError("CALENDAR", v, resp))
+	}
+	entries := make([]CalendarEntry, len(resp.Rows))
+	for i, row := range resp.Rows {
+		entries[i], err = decodeCalendarEntry(row)
+		if err != nil {
+			return nil, fmt.Errorf("udpapi Calendar: %w", err)
+		}
+	}
+	return entries, nil
+}
+
+// decodeCalendarEntry decodes a CALENDAR response row.
+func decodeCalendarEntry(row []string) (CalendarEntry, error) {
+	var e CalendarEntry
+	if n := len(row); n != 3 {
+		return e, fmt.Errorf("decode calendar entry: got unexpected number of fields %d", n)
+	}
+	var err error
+	e.AID, err = strconv.Atoi(row[0])
+	if err != nil {
+		return e, fmt.Errorf("decode calendar entry: aid: %w", err)
+	}
+	e.StartDate, err = parseUnixTime(row[1])
+	if err != nil {
+		return e, fmt.Errorf("decode calendar entry: start date: %w", err)
+	}
+	flags, err := strconv.Atoi(row[2])
+	if err != nil {
+		return e, fmt.Errorf("decode calendar entry: date flags: %w", err)
+	}
+	e.Flags = DateFlags(flags)
+	return e, nil
+}
@@ -0,0 +1,92 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestClient_Calendar(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		switch cmd {
+		case "AUTH":
+			return "200 key 1234 LOGIN ACCEPTED"
+		case "CALENDAR":
+			return "297 CALENDAR\n17550|1704067200|0\n17551|1704153600|17"
+		default:
+			return "598 UNKNOWN COMMAND"
+		}
+	})
+	c := s.client
+	if _, err := c.Auth(ctx, UserInfo{UserName: "ionasal", UserPassword: "pass"}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.Calendar(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []CalendarEntry{
+		{AID: 17550, StartDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{AID: 17551, StartDate: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), Flags: 17},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v; want %v", got, want)
+	}
+}
+
+func TestClient_Calendar_empty(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		switch cmd {
+		case "AUTH":
+			return "200 key 1234 LOGIN ACCEPTED"
+		case "CALENDAR":
+			return "397 CALENDAR EMPTY"
+		default:
+			return "598 UNKNOWN COMMAND"
+		}
+	})
+	c := s.client
+	if _, err := c.Auth(ctx, UserInfo{UserName: "ionasal", UserPassword: "pass"}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.Calendar(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("Got %v; want none", got)
+	}
+}
+
+func TestDateFlags(t *testing.T) {
+	t.Parallel()
+	f := DateFlags(1<<0 | 1<<4)
+	if !f.StartDayUnknown() {
+		t.Errorf("Got StartDayUnknown false; want true")
+	}
+	if !f.Ended() {
+		t.Errorf("Got Ended false; want true")
+	}
+	if f.StartMonthUnknown() || f.EndDayUnknown() || f.EndMonthUnknown() || f.StartYearUnknown() || f.EndYearUnknown() {
+		t.Errorf("Got unexpected flags set for %d", f)
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A CharacterInfo is the decoded result of a CHARACTER command.
+type CharacterInfo struct {
+	CharID    int
+	NameKanji string
+	Name      string
+	Pic       string
+	Type      int
+	Gender    string
+}
+
+// Character calls the CHARACTER command.
+// The returned error wraps a [codes.ReturnCode] if applicable.
+func (c *Client) Character(ctx context.Context, charID int) (CharacterInfo, error) {
+	v, err := c.sessionValues(ctx)
+	if err != nil {
+		return CharacterInfo{}, fmt.Errorf("udpapi Character: %w", err)
+	}
+	v.Set("charid", strconv.Itoa(charID))
+	resp, err := c.request(ctx, "CHARACTER", v)
+	if err != nil {
+		return CharacterInfo{}, fmt.Errorf("udpapi Character: %w", err)
+	}
+	if resp.Code != 235 {
+		return CharacterInfo{}, fmt.Errorf("udpapi Character: %w", codeError("CHARACTER", v, resp))
+	}
+	if n := len(resp.Rows); n != 1 {
+		return CharacterInfo{}, fmt.Errorf("udpapi Character: got unexpected number of rows %d", n)
+	}
+	ci, err := decodeCharacter(resp.Rows[0])
+	if err != nil {
+		return CharacterInfo{}, fmt.Errorf("udpapi Character: %w", err)
+	}
+	return ci, nil
+}
+
+// decodeCharacter decodes a CHARACTER response row.
+func decodeCharacter(row []string) (CharacterInfo, error) {
+	if n := len(row); n < 9 {
+		return CharacterInfo{}, fmt.Errorf("decode character: got unexpected number of fields %d", n)
+	}
+	id, err := strconv.Atoi(row[0])
+	if err != nil {
+		return CharacterInfo{}, fmt.Errorf("decode character: %s", err)
+	}
+	typ, err := strconv.Atoi(row[7])
+	if err != nil {
+		return CharacterInfo{}, fmt.Errorf("decode character: %s", err)
+	}
+	return CharacterInfo{
+		CharID:    id,
+		NameKanji: row[1],
+		Name:      row[2],
+		Pic:       row[3],
+		Type:      typ,
+		Gender:    row[8],
+	}, nil
+}
+
+// AnimeCharacters returns the cast of an anime.
+//
+// This calls ANIME to get the character IDs for the anime, then
+// CHARACTER for each character, so it makes one request plus one
+// request per character.
+// As the client is rate limited, this can take a long time for
+// anime with large casts.
+// The returned error wraps a [RequestError] for the failing request
+// and a [codes.ReturnCode] if applicable.
+func (c *Client) AnimeCharacters(ctx context.Context, aid int) ([]CharacterInfo, error) {
+	var m AnimeAmask
+	m.Set("character id list")
+	a, err := c.AnimeByAID(ctx, aid, m)
+	if err != nil {
+		return nil, fmt.Errorf("udpapi AnimeCharacters: %w", err)
+	}
+	chars := make([]CharacterInfo, len(a.CharacterIDs))
+	for i, id := range a.CharacterIDs {
+		chars[i], err = c.Character(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("udpapi AnimeCharacters: %w", err)
+		}
+	}
+	return chars, nil
+}
+
+// parseIntList parses a list of ints.
+// An empty string is parsed as an empty list.
+func parseIntList(s, sep string) ([]int, error) {
+	if s == "" {
+		return nil, nil
+	}
+	parts := strings.Split(s, sep)
+	l := make([]int, len(parts))
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return nil, err
+		}
+		l[i] = n
+	}
+	return l, nil
+}
@@ -0,0 +1,98 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.felesatra.moe/anidb/udpapi/codes"
+)
+
+func TestClient_AnimeCharacters(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		switch cmd {
+		case "ANIME":
+			if args.Get("aid") != "8076" || args.Get("amask") != "00000000008000" {
+				return "505 ILLEGAL INPUT OR ACCESS DENIED"
+			}
+			return "230 ANIME\n81213,81214"
+		case "CHARACTER":
+			switch args.Get("charid") {
+			case "81213":
+				return "235 CHARACTER\n81213|本条楓|Honjou Kaede|230881.jpg|8076,1,2,0|1|1577836800|1|female"
+			case "81214":
+				return "235 CHARACTER\n81214|白峯理沙|Shiramine Risa|230882.jpg|8076,2,3,0|1|1577836800|1|female"
+			}
+		}
+		return "598 UNKNOWN COMMAND"
+	})
+	s.client.sessionKey.set("key")
+	got, err := s.client.AnimeCharacters(ctx, 8076)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []CharacterInfo{
+		{CharID: 81213, NameKanji: "本条楓", Name: "Honjou Kaede", Pic: "230881.jpg", Type: 1, Gender: "female"},
+		{CharID: 81214, NameKanji: "白峯理沙", Name: "Shiramine Risa", Pic: "230882.jpg", Type: 1, Gender: "female"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %#v; want %#v", got, want)
+	}
+	if n := len(s.requests()); n != 3 {
+		t.Errorf("Got %d requests; want 3", n)
+	}
+}
+
+func TestClient_AnimeCharacters_error(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		switch cmd {
+		case "ANIME":
+			return "230 ANIME\n81213,81214"
+		case "CHARACTER":
+			if args.Get("charid") == "81213" {
+				return "235 CHARACTER\n81213|本条楓|Honjou Kaede|230881.jpg|8076,1,2,0|1|1577836800|1|female"
+			}
+			return "505 ILLEGAL INPUT OR ACCESS DENIED"
+		}
+		return "598 UNKNOWN COMMAND"
+	})
+	s.client.sessionKey.set("key")
+	_, err := s.client.AnimeCharacters(ctx, 8076)
+	var re *RequestError
+	if !errors.As(err, &re) {
+		t.Fatalf("Got error %v; want RequestError", err)
+	}
+	if re.Cmd != "CHARACTER" {
+		t.Errorf("Got command %q; want CHARACTER", re.Cmd)
+	}
+	if re.Code != codes.ILLEGAL_INPUT_OR_ACCESS_DENIED {
+		t.Errorf("Got code %v; want %v", re.Code, codes.ILLEGAL_INPUT_OR_ACCESS_DENIED)
+	}
+	reqs := s.requests()
+	if got, want := re.Tag, reqs[len(reqs)-1].args.Get("tag"); got != want {
+		t.Errorf("Got tag %q; want %q", got, want)
+	}
+	if !errors.Is(err, codes.ILLEGAL_INPUT_OR_ACCESS_DENIED) {
+		t.Errorf("Got error %v; want it to wrap return code", err)
+	}
+}
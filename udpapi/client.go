@@ -17,14 +17,18 @@ package udpapi
 import (
 	"context"
 	"crypto/aes"
-	"crypto/md5"
 	"errors"
 	"fmt"
 	"log/slog"
 	"net"
 	"net/url"
+	"slices"
 	"strconv"
-	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.felesatra.moe/anidb/udpapi/codes"
 )
 
 const protoVer = "3"
@@ -32,43 +36,249 @@ const protoVer = "3"
 // A Client is an AniDB UDP API client.
 //
 // The client handles rate limiting.
-// The client does not handle retries.
-// The client does not handle keepalive.
+// The client handles retries if configured with RetryPolicy.
+// The client handles keepalive if configured with AutoKeepAlive.
 type Client struct {
-	conn    net.Conn
+	conn    syncVar[*net.UDPConn]
 	m       *Mux
-	limiter *limiter
-	logger  *slog.Logger
+	limiter Limiter
+	// queue orders requests waiting for the limiter by priority.
+	queue requestQueue
+	// cooldown is shared by all requests after the server is busy.
+	cooldown cooldown
+	// interceptors are added with Use.
+	interceptors syncVar[[]Interceptor]
+	logger       *slog.Logger
 
 	sessionKey syncVar[string]
+	user       syncVar[*UserInfo]
+	// encryptUser is the user for the last ENCRYPT, without the
+	// password, for re-authenticating.
+	encryptUser syncVar[*UserInfo]
+	// encryptKey is the key for the current encryption, for
+	// exporting the session.
+	encryptKey  syncVar[[]byte]
+	lastRequest syncVar[time.Time]
+	budget      requestBudget
+	stats       syncVar[Stats]
+	keepAlive   syncVar[*KeepAlive]
+	lockout     syncVar[time.Time]
+	// addr is the server address passed to Dial.
+	addr string
+	// dialConfig is used for re-dialing.
+	dialConfig DialConfig
+	// redialMu is held while re-dialing.
+	redialMu sync.Mutex
+	// addrIndex is the index of the current address in addrs,
+	// guarded by redialMu.
+	addrIndex int
+	// netFailures counts consecutive requests that failed with
+	// network errors or timeouts.
+	netFailures atomic.Int32
+	// reauthMu is held while re-authenticating, so concurrent
+	// requests that find the session stale re-authenticate once.
+	reauthMu sync.Mutex
 
 	ClientName    string
 	ClientVersion int32
+	// APIKey is the AniDB UDP API key.
+	// It is used by [Client.Encrypt] when the UserInfo passed to it
+	// does not have one.
+	// ENCRYPT is the only UDP API command that uses the API key;
+	// no command requires it without encryption.
+	// Setting APIKey does not enable encryption.
+	APIKey string
+	// EncryptionType is the encryption type requested by
+	// [Client.Encrypt].
+	// If zero, [EncryptionAES128] is used.
+	EncryptionType EncryptionType
+	// IdleTimeout is how long a session may be idle before the
+	// client re-authenticates ahead of the next command.
+	// AniDB expires idle sessions, so this avoids a failed command
+	// after a long idle period.
+	// If zero, the client does not re-authenticate.
+	IdleTimeout time.Duration
+	// Credentials provides the credentials used by
+	// [Client.AuthCredentials] and when re-authenticating.
+	// If set, the client does not keep the password passed to
+	// [Client.Auth] for re-authenticating.
+	Credentials CredentialProvider
+	// MaxRequests is the maximum number of requests the client
+	// makes per RequestWindow, as a safety valve against runaway
+	// loops.
+	// Requests beyond this fail with [ErrRequestBudgetExceeded].
+	// If zero, there is no maximum.
+	MaxRequests int
+	// RequestWindow is the window for MaxRequests.
+	// If zero, MaxRequests applies for the lifetime of the client.
+	RequestWindow time.Duration
+	// Cache, if set, caches responses to idempotent read commands
+	// and is consulted before sending them.
+	// See [MemoryCache] and [DirCache].
+	Cache ResponseCache
+	// CacheTTLs are the TTLs of cached responses per command.
+	// Commands not in the map are not cached.
+	// FILE responses selecting mylist fields are never cached.
+	// If nil, the TTLs returned by [DefaultCacheTTLs] are used.
+	CacheTTLs map[string]time.Duration
+	// DefaultParams are added to every request.
+	// They do not override parameters set by the command.
+	DefaultParams url.Values
+	// RetryPolicy configures automatic retries for requests.
+	// The zero value does not retry.
+	// See [DefaultRetryPolicy].
+	RetryPolicy RetryPolicy
+	// Cooldown configures the cool-down shared by all requests after
+	// the server responds with SERVER_BUSY or TIMEOUT.
+	// The zero value disables the cool-down.
+	// See [DefaultCooldownPolicy].
+	Cooldown CooldownPolicy
+	// AutoKeepAlive enables running a [KeepAlive] for the session.
+	// The KeepAlive is started after a successful AUTH and stopped
+	// by [Client.Logout] or [Client.Close].
+	// See [Client.KeepAliveState].
+	AutoKeepAlive bool
+	// KeepAliveConfig configures the ping intervals of keepalives
+	// started by AutoKeepAlive or [StartKeepAlive].
+	KeepAliveConfig KeepAliveConfig
+	// AutoReauth enables re-authenticating when a command fails
+	// because the session is invalid (LOGIN_FIRST or
+	// INVALID_SESSION).
+	// The client calls AUTH again, and ENCRYPT if it was used, then
+	// retries the command once.
+	// Concurrent commands that fail share a single
+	// re-authentication.
+	// See [Client.Credentials] for the credentials used.
+	AutoReauth bool
+	// DisableCompression disables compressed responses for
+	// sessions started with AUTH.
+	// Compression is a per-session setting in the UDP API, so this
+	// only affects future sessions.
+	// Compression is enabled by default, as it lets larger responses
+	// fit in a packet.
+	DisableCompression bool
+	// DisableNAT stops sessions started with AUTH from requesting
+	// the client's address as seen by the server (nat=1).
+	// If set, [AuthHeader.PublicAddr] is zero and NAT is not
+	// detected.
+	DisableNAT bool
+	// ImageServer requests the image server name for sessions
+	// started with AUTH (imgserver=1).
+	// The name is returned in [AuthHeader.ImageServer].
+	ImageServer bool
+	// Encoding, if set, is the encoding requested for sessions
+	// started with AUTH, and is used to decode responses.
+	// Setting this to [EncodingUTF8] is recommended, as the server
+	// default may mangle non-ASCII text.
+	// See the Encoding constants for supported encodings.
+	Encoding string
+	// BanLockout is how long the client refuses requests after the
+	// server responds with BANNED or CLIENT_BANNED, to avoid making
+	// the ban worse.
+	// If zero, [DefaultBanLockout] is used.
+	// See [Client.LockedOutUntil].
+	BanLockout time.Duration
+	// RedialAfter enables re-dialing the server after this many
+	// consecutive request attempts fail with network errors or
+	// timeouts, such as after a network change.
+	// See [Client.Redial].
+	// If zero, the client does not re-dial automatically.
+	RedialAfter int
+	// FailoverAddrs are server addresses to fail over to when
+	// re-dialing.
+	// The client cycles through the address passed to Dial and
+	// these.
+	FailoverAddrs []string
+}
+
+// A CredentialProvider provides user credentials on demand, such as
+// from a secrets manager.
+type CredentialProvider interface {
+	Credentials(context.Context) (UserInfo, error)
 }
 
 // Dial connects to an AniDB UDP API server.
 // The caller should set ClientName and ClientVersion on the returned Client.
 // The caller should call [Client.SetLogger] as the client may produce
 // asynchronous errors.
+//
+// The server address is resolved once, and the client stays connected
+// to the resolved address for its lifetime, unless re-dialed.
+// See [Client.ServerAddr] and [Client.Redial].
+// Use [DialConfig] to configure the connection.
 func Dial(addr string, l *slog.Logger) (*Client, error) {
-	conn, err := net.Dial("udp", addr)
+	return DialConfig{}.Dial(context.Background(), addr, l)
+}
+
+// DialUDPAddr connects to an AniDB UDP API server at a resolved
+// address.
+// This is useful for reusing the address of another client, so that
+// new clients do not resolve the server to a different address.
+// See [Dial].
+func DialUDPAddr(raddr *net.UDPAddr, l *slog.Logger) (*Client, error) {
+	conn, err := net.DialUDP("udp", nil, raddr)
 	if err != nil {
-		return nil, fmt.Errorf("udpapi NewClient: %w", err)
+		return nil, fmt.Errorf("udpapi Dial: %w", err)
 	}
+	return newClient(conn, DialConfig{}, raddr.String(), l), nil
+}
+
+func newClient(conn *net.UDPConn, cfg DialConfig, addr string, l *slog.Logger) *Client {
 	l = l.With("package", "go.felesatra.moe/anidb/udpapi", "component", "client")
 	c := &Client{
-		conn:    conn,
-		m:       NewMux(conn, l),
-		limiter: newLimiter(),
-		logger:  l,
+		m:          NewMux(conn, l),
+		limiter:    newLimiter(),
+		logger:     l,
+		addr:       addr,
+		dialConfig: cfg,
 	}
-	return c, nil
+	c.conn.set(conn)
+	return c
+}
+
+// SetLimiter sets the rate limiter for the client, replacing the
+// default limiter.
+// This is useful for sharing a limiter between clients, such as
+// across processes.
+// This must be called before making requests.
+func (c *Client) SetLimiter(l Limiter) {
+	c.limiter = l
+}
+
+// SetTagPrefix sets the prefix for request tags.
+// See [Mux.SetTagPrefix].
+func (c *Client) SetTagPrefix(p string) {
+	c.m.SetTagPrefix(p)
+}
+
+// SetTimeout sets the timeout for each request attempt.
+// See [Mux.SetTimeout].
+func (c *Client) SetTimeout(d time.Duration) {
+	c.m.SetTimeout(d)
+}
+
+// SetUnescape sets the function used to unescape response fields.
+// See [Mux.SetUnescape].
+func (c *Client) SetUnescape(f func(string) string) {
+	c.m.SetUnescape(f)
+}
+
+// SetUTF8Mode sets how invalid UTF-8 in response fields is handled.
+// See [Mux.SetUTF8Mode].
+func (c *Client) SetUTF8Mode(mode UTF8Mode) {
+	c.m.SetUTF8Mode(mode)
+}
+
+// ServerAddr returns the resolved server address for the client
+// connection.
+func (c *Client) ServerAddr() *net.UDPAddr {
+	return c.conn.get().RemoteAddr().(*net.UDPAddr)
 }
 
 // LocalPort returns the local port for the client connection.
 // This is useful for detecting NAT.
 func (c *Client) LocalPort() string {
-	addr := c.conn.LocalAddr().String()
+	addr := c.conn.get().LocalAddr().String()
 	_, port, err := net.SplitHostPort(addr)
 	if err != nil {
 		panic(err)
@@ -76,123 +286,399 @@ func (c *Client) LocalPort() string {
 	return port
 }
 
+// ErrConnLost is returned by [Client.Redial] if the client was left
+// without a connection, in which case it should be closed and a new
+// client dialed.
+var ErrConnLost = errors.New("connection lost")
+
+// Redial connects to the next server address, replacing the current
+// connection, such as to recover after a network change.
+// The addresses are the one passed to Dial followed by
+// [Client.FailoverAddrs], cycling.
+//
+// If connecting fails, the current connection is kept.
+// If [DialConfig.LocalAddr] has a fixed port, the current connection
+// has to be closed first to release the port, and it is re-dialed
+// if connecting fails; if that fails too, the returned error wraps
+// [ErrConnLost].
+//
+// If there is a session, it is re-established with the stored
+// credentials, as sessions are tied to the client address.
+// See [Client.AutoReauth] for the credentials used.
+func (c *Client) Redial(ctx context.Context) error {
+	c.redialMu.Lock()
+	defer c.redialMu.Unlock()
+	if err := c.redial(ctx); err != nil {
+		return fmt.Errorf("udpapi Redial: %w", err)
+	}
+	return nil
+}
+
+// redial implements Redial.
+// redialMu must be held.
+func (c *Client) redial(ctx context.Context) error {
+	addrs := append([]string{c.addr}, c.FailoverAddrs...)
+	i := (c.addrIndex + 1) % len(addrs)
+	raddr, err := c.dialConfig.resolve(ctx, addrs[i])
+	if err != nil {
+		return err
+	}
+	prev := c.conn.get()
+	la := c.dialConfig.LocalAddr
+	fixed := la != nil && la.Port != 0
+	if fixed {
+		// The old connection must release the fixed local port.
+		prev.Close()
+	}
+	conn, err := c.dialConfig.dial(ctx, raddr)
+	if err != nil {
+		if fixed {
+			return c.restoreConn(ctx, prev, err)
+		}
+		return err
+	}
+	if err := c.m.ReplaceConn(conn); err != nil {
+		return err
+	}
+	c.addrIndex = i
+	c.conn.set(conn)
+	c.netFailures.Store(0)
+	c.logger.Info("Re-dialed server", "addr", raddr)
+	if c.sessionKey.get() == "" {
+		return nil
+	}
+	// If a re-authentication is in progress, such as the one
+	// whose AUTH failed and triggered this re-dial, the session is
+	// left to be re-established by later requests.
+	if !c.reauthMu.TryLock() {
+		return nil
+	}
+	defer c.reauthMu.Unlock()
+	c.sessionKey.set("")
+	if err := c.reauth(ctx); err != nil {
+		return fmt.Errorf("reauth: %w", err)
+	}
+	return nil
+}
+
+// restoreConn re-dials the previous server address after re-dialing
+// failed with err, as the previous connection was closed to release
+// a fixed local port.
+// It returns err, wrapped with [ErrConnLost] if the connection could
+// not be restored.
+// redialMu must be held.
+func (c *Client) restoreConn(ctx context.Context, prev *net.UDPConn, err error) error {
+	conn, err2 := c.dialConfig.dial(ctx, prev.RemoteAddr().(*net.UDPAddr))
+	if err2 != nil {
+		return fmt.Errorf("%w: %w (restore: %w)", ErrConnLost, err, err2)
+	}
+	if err2 := c.m.ReplaceConn(conn); err2 != nil {
+		return fmt.Errorf("%w: %w (restore: %w)", ErrConnLost, err, err2)
+	}
+	c.conn.set(conn)
+	c.logger.Info("Restored connection after failed re-dial", "addr", prev.RemoteAddr(), "error", err)
+	return err
+}
+
+// noteRequestResult tracks consecutive network failures, re-dialing
+// if [Client.RedialAfter] is reached.
+func (c *Client) noteRequestResult(ctx context.Context, err error) {
+	if err == nil {
+		c.netFailures.Store(0)
+		return
+	}
+	// Errors for the caller's context are not network failures.
+	if ctx.Err() != nil || !isNetworkFailure(err) {
+		return
+	}
+	n := c.netFailures.Add(1)
+	if c.RedialAfter <= 0 || int(n) < c.RedialAfter {
+		return
+	}
+	// Requests made while re-dialing, such as for
+	// re-authenticating, must not re-dial again.
+	if !c.redialMu.TryLock() {
+		return
+	}
+	defer c.redialMu.Unlock()
+	c.logger.Warn("Re-dialing after network failures", "failures", n, "error", err)
+	if err := c.redial(ctx); err != nil {
+		c.logger.Error("Error re-dialing", "error", err)
+	}
+}
+
+// isNetworkFailure returns whether a request error may be caused by
+// a bad connection.
+func isNetworkFailure(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var ne net.Error
+	return errors.As(err, &ne)
+}
+
 // Close closes the Client.
 // This does not call LOGOUT, so you should try to LOGOUT first.
 // The underlying connection is closed.
 // No new requests will be accepted (as the connection is closed).
 // Outstanding requests will be unblocked.
-func (c *Client) Close() {
+// Returns any error from closing the underlying connection.
+func (c *Client) Close() error {
+	c.stopKeepAlive()
 	// The connection is closed by the Mux.
-	c.m.Close()
+	if err := c.m.Close(); err != nil {
+		return fmt.Errorf("udpapi Close: %w", err)
+	}
+	return nil
 }
 
 // A UserInfo contains user information for authentication and encryption.
 type UserInfo struct {
 	UserName     string
 	UserPassword string
-	APIKey       string // required for encryption, optional otherwise
+	// APIKey is the AniDB UDP API key.
+	// This is only needed for ENCRYPT, the only UDP API command
+	// that uses the API key.
+	// If empty, [Client.APIKey] is used instead.
+	APIKey string
 }
 
+// ErrNoAPIKey is returned by [Client.Encrypt], and methods that
+// call it, when no API key is available.
+var ErrNoAPIKey = errors.New("no API key (set UserInfo.APIKey or Client.APIKey)")
+
 // Encrypt calls the ENCRYPT command.
+// This requires an API key.
+// The encryption type is set by [Client.EncryptionType].
+// If the server does not support the type, the returned error wraps
+// [ErrNoSuchEncryptionType].
+//
+// A wrong API key is not detected by ENCRYPT; see
+// [Client.VerifyEncryption].
 func (c *Client) Encrypt(ctx context.Context, u UserInfo) error {
-	if u.APIKey == "" {
-		return errors.New("udpapi encrypt: APIKey required for encryption")
+	key, err := c.apiKey(u)
+	if err != nil {
+		return fmt.Errorf("udpapi Encrypt: %w", err)
+	}
+	t := c.encryptionType()
+	if !t.supported() {
+		return fmt.Errorf("udpapi Encrypt: unsupported encryption type %d", t)
 	}
 	v := url.Values{}
 	v.Set("user", u.UserName)
-	v.Set("type", "1")
+	v.Set("type", strconv.Itoa(int(t)))
 	resp, err := c.request(ctx, "ENCRYPT", v)
 	if err != nil {
-		return fmt.Errorf("udpapi Encrypt: %s", err)
+		return fmt.Errorf("udpapi Encrypt: %w", err)
 	}
 	switch resp.Code {
-	case 209:
-		parts := strings.SplitN(resp.Header, " ", 2)
-		salt := parts[0]
-		sum := md5.Sum([]byte(u.APIKey + salt))
-		b, err := aes.NewCipher(sum[:])
+	case codes.ENCRYPTION_ENABLED:
+		h, err := ParseEncryptHeader(resp)
 		if err != nil {
 			return fmt.Errorf("udpapi Encrypt: %s", err)
 		}
+		k, err := DeriveKey(t, key, h.Salt)
+		if err != nil {
+			return fmt.Errorf("udpapi Encrypt: %w", err)
+		}
+		b, err := aes.NewCipher(k)
+		if err != nil {
+			return fmt.Errorf("udpapi Encrypt: %w", err)
+		}
 		c.m.SetBlock(b)
+		c.encryptKey.set(k)
+		c.encryptUser.set(&UserInfo{UserName: u.UserName, APIKey: u.APIKey})
 		return nil
+	case codes.NO_SUCH_ENCRYPTION_TYPE:
+		return fmt.Errorf("udpapi Encrypt: %w", responseError("ENCRYPT", v, resp, fmt.Errorf("%w (%w)", ErrNoSuchEncryptionType, resp.Code)))
 	default:
-		return fmt.Errorf("udpapi Encrypt: bad code %d %q", resp.Code, resp.Header)
+		return fmt.Errorf("udpapi Encrypt: %w", codeError("ENCRYPT", v, resp))
 	}
 }
 
 // Auth calls the AUTH command.
+// It returns the client's address as seen by the server, as
+// "ip:port", or an empty string if [Client.DisableNAT] is set.
+// See [Client.AuthInfo] for the full response metadata.
 func (c *Client) Auth(ctx context.Context, u UserInfo) (port string, _ error) {
+	h, err := c.auth(ctx, u)
+	if err != nil {
+		return "", fmt.Errorf("udpapi Auth: %w", err)
+	}
+	return formatAuthAddr(h.PublicAddr), nil
+}
+
+// An AuthResult is the result of a successful AUTH command.
+type AuthResult struct {
+	AuthHeader
+	// NATDetected is set if the server sees the client on a
+	// different port than the local port, which means the client is
+	// behind NAT and should keep the session alive with
+	// [KeepAlive].
+	// It is never set if [Client.DisableNAT] is set.
+	NATDetected bool
+}
+
+// AuthInfo calls the AUTH command like [Client.Auth] and returns the
+// full result.
+func (c *Client) AuthInfo(ctx context.Context, u UserInfo) (AuthResult, error) {
+	h, err := c.auth(ctx, u)
+	if err != nil {
+		return AuthResult{}, fmt.Errorf("udpapi AuthInfo: %w", err)
+	}
+	r := AuthResult{AuthHeader: h}
+	if p := h.PublicAddr.Port(); p != 0 {
+		r.NATDetected = strconv.Itoa(int(p)) != c.LocalPort()
+	}
+	return r, nil
+}
+
+func (c *Client) auth(ctx context.Context, u UserInfo) (AuthHeader, error) {
+	if c.Encoding != "" {
+		if _, err := encodingDecoder(c.Encoding); err != nil {
+			return AuthHeader{}, err
+		}
+	}
 	v := url.Values{}
 	v.Set("user", u.UserName)
 	v.Set("pass", u.UserPassword)
 	v.Set("protover", protoVer)
 	v.Set("client", c.ClientName)
 	v.Set("clientver", strconv.Itoa(int(c.ClientVersion)))
-	v.Set("nat", "1")
-	v.Set("comp", "1")
+	if !c.DisableNAT {
+		v.Set("nat", "1")
+	}
+	if c.ImageServer {
+		v.Set("imgserver", "1")
+	}
+	if !c.DisableCompression {
+		v.Set("comp", "1")
+	}
+	if c.Encoding != "" {
+		v.Set("enc", c.Encoding)
+	}
 	resp, err := c.request(ctx, "AUTH", v)
 	if err != nil {
-		return "", fmt.Errorf("udpapi Auth: %s", err)
+		return AuthHeader{}, err
 	}
 	switch resp.Code {
-	case 201:
-		// TODO Handle new anidb UDP API version available
-		fallthrough
-	case 200:
-		parts := strings.SplitN(resp.Header, " ", 3)
-		if len(parts) < 3 {
-			return "", fmt.Errorf("udpapi Auth: invalid response header %q", resp.Header)
+	case codes.LOGIN_ACCEPTED, codes.LOGIN_ACCEPTED_NEW_VERSION:
+		h, err := ParseAuthHeader(resp)
+		if err != nil {
+			return AuthHeader{}, err
+		}
+		if h.NewVersionAvailable {
+			c.logger.Info("New UDP API version available")
+		}
+		c.sessionKey.set(h.SessionKey)
+		if c.Encoding != "" {
+			// Already validated above.
+			_ = c.m.SetEncoding(c.Encoding)
 		}
-		c.sessionKey.set(parts[0])
-		return parts[1], nil
+		if c.Credentials == nil {
+			c.user.set(&u)
+		}
+		if c.AutoKeepAlive {
+			c.startKeepAlive()
+		}
+		return h, nil
 	default:
-		return "", fmt.Errorf("udpapi Auth: bad code %d %q", resp.Code, resp.Header)
+		return AuthHeader{}, codeError("AUTH", v, resp)
+	}
+}
+
+// AuthCredentials calls the AUTH command with credentials from
+// [Client.Credentials].
+func (c *Client) AuthCredentials(ctx context.Context) (port string, _ error) {
+	if c.Credentials == nil {
+		return "", errors.New("udpapi AuthCredentials: no credential provider")
 	}
+	u, err := c.Credentials.Credentials(ctx)
+	if err != nil {
+		return "", fmt.Errorf("udpapi AuthCredentials: %w", err)
+	}
+	return c.Auth(ctx, u)
+}
+
+// LoginEncrypted starts an encrypted session for the user.
+// This calls the ENCRYPT command, verifies the encryption with
+// [Client.VerifyEncryption], calls the AUTH command, and verifies
+// the session with the UPTIME command.
+// Any previous session and encryption is cleared first.
+// If any step fails, the client is left without a session.
+//
+// [Client.Logout] clears the encryption along with the session.
+// If [Client.AutoReauth] is set, re-authentication also
+// re-establishes the encryption.
+func (c *Client) LoginEncrypted(ctx context.Context, u UserInfo) (port string, _ error) {
+	c.stopKeepAlive()
+	c.clearSession()
+	if err := c.Encrypt(ctx, u); err != nil {
+		return "", fmt.Errorf("udpapi LoginEncrypted: %w", err)
+	}
+	if err := c.VerifyEncryption(ctx); err != nil {
+		c.clearSession()
+		return "", fmt.Errorf("udpapi LoginEncrypted: %w", err)
+	}
+	port, err := c.Auth(ctx, u)
+	if err != nil {
+		c.clearSession()
+		return "", fmt.Errorf("udpapi LoginEncrypted: %w", err)
+	}
+	if _, err := c.Uptime(ctx); err != nil {
+		c.stopKeepAlive()
+		c.clearSession()
+		return "", fmt.Errorf("udpapi LoginEncrypted: verify session: %w", err)
+	}
+	return port, nil
+}
+
+// clearSession clears the session and encryption state.
+func (c *Client) clearSession() {
+	c.m.SetBlock(nil)
+	c.m.decoder.set(nil)
+	c.sessionKey.set("")
+	c.user.set(nil)
+	c.encryptUser.set(nil)
+	c.encryptKey.set(nil)
 }
 
 // Logout calls the LOGOUT command.
 func (c *Client) Logout(ctx context.Context) error {
-	v, err := c.sessionValues()
+	v, err := c.sessionKeyValues()
 	if err != nil {
-		return fmt.Errorf("udpapi Logout: %s", err)
+		return fmt.Errorf("udpapi Logout: %w", err)
 	}
+	c.stopKeepAlive()
 	resp, err := c.request(ctx, "LOGOUT", v)
 	if err != nil {
-		return fmt.Errorf("udpapi Logout: %s", err)
+		return fmt.Errorf("udpapi Logout: %w", err)
 	}
-	c.m.SetBlock(nil)
-	c.sessionKey.set("")
+	c.clearSession()
 	switch resp.Code {
 	case 203:
 		return nil
 	default:
-		return fmt.Errorf("udpapi Logout: bad code %d %q", resp.Code, resp.Header)
+		return fmt.Errorf("udpapi Logout: %w", codeError("LOGOUT", v, resp))
 	}
 }
 
-// FileByHash calls the FILE command by size+ed2k hash.
+// FileByHash calls the FILE command by size+ed2k hash and returns the
+// row as received, before unescaping.
+// See [Client.FileInfoByHash] for decoding the result.
 // The returned error wraps a [codes.ReturnCode] if applicable.
 func (c *Client) FileByHash(ctx context.Context, size int64, hash string, fmask FileFmask, amask FileAmask) ([]string, error) {
-	v, err := c.sessionValues()
+	v, err := c.sessionValues(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("udpapi FileByHash: %s", err)
+		return nil, fmt.Errorf("udpapi FileByHash: %w", err)
 	}
 	v.Set("size", fmt.Sprintf("%d", size))
 	v.Set("ed2k", hash)
-	v.Set("fmask", formatMask(fmask[:]))
-	v.Set("amask", formatMask(amask[:]))
-	resp, err := c.request(ctx, "FILE", v)
+	row, err := c.fileRow(ctx, v, fmask, amask)
 	if err != nil {
-		return nil, fmt.Errorf("udpapi FileByHash: %s", err)
-	}
-	if resp.Code != 220 {
-		return nil, fmt.Errorf("udpapi FileByHash: got bad return code %w", resp.Code)
+		return nil, fmt.Errorf("udpapi FileByHash: %w", err)
 	}
-	if n := len(resp.Rows); n != 1 {
-		return nil, fmt.Errorf("udpapi FileByHash: got unexpected number of rows %d", n)
-	}
-	return resp.Rows[0], nil
+	return row, nil
 }
 
 // Ping calls the PING command with nat=1 and returns the port.
@@ -201,10 +687,10 @@ func (c *Client) Ping(ctx context.Context) (port string, _ error) {
 	v.Set("nat", "1")
 	resp, err := c.request(ctx, "PING", v)
 	if err != nil {
-		return "", fmt.Errorf("udpapi Ping: %s", err)
+		return "", fmt.Errorf("udpapi Ping: %w", err)
 	}
 	if resp.Code != 300 {
-		return "", fmt.Errorf("udpapi Ping: got bad return code %s", resp.Code)
+		return "", fmt.Errorf("udpapi Ping: %w", codeError("PING", v, resp))
 	}
 	if n := len(resp.Rows); n != 1 {
 		return "", fmt.Errorf("udpapi Ping: got unexpected number of rows %d", n)
@@ -215,18 +701,32 @@ func (c *Client) Ping(ctx context.Context) (port string, _ error) {
 	return resp.Rows[0][0], nil
 }
 
+// PingSimple calls the PING command without nat=1.
+// This is useful as a plain liveness check.
+func (c *Client) PingSimple(ctx context.Context) error {
+	v := make(url.Values)
+	resp, err := c.request(ctx, "PING", v)
+	if err != nil {
+		return fmt.Errorf("udpapi PingSimple: %w", err)
+	}
+	if resp.Code != 300 {
+		return fmt.Errorf("udpapi PingSimple: %w", codeError("PING", v, resp))
+	}
+	return nil
+}
+
 // Uptime calls the UPTIME command and returns server uptime in milliseconds.
 func (c *Client) Uptime(ctx context.Context) (uptime int, _ error) {
-	v, err := c.sessionValues()
+	v, err := c.sessionValues(ctx)
 	if err != nil {
-		return 0, fmt.Errorf("udpapi Uptime: %s", err)
+		return 0, fmt.Errorf("udpapi Uptime: %w", err)
 	}
 	resp, err := c.request(ctx, "UPTIME", v)
 	if err != nil {
-		return 0, fmt.Errorf("udpapi Uptime: %s", err)
+		return 0, fmt.Errorf("udpapi Uptime: %w", err)
 	}
 	if resp.Code != 208 {
-		return 0, fmt.Errorf("udpapi Uptime: got bad return code %s", resp.Code)
+		return 0, fmt.Errorf("udpapi Uptime: %w", codeError("UPTIME", v, resp))
 	}
 	if n := len(resp.Rows); n != 1 {
 		return 0, fmt.Errorf("udpapi Uptime: got unexpected number of rows %d", n)
@@ -236,21 +736,346 @@ func (c *Client) Uptime(ctx context.Context) (uptime int, _ error) {
 	}
 	time, err := strconv.Atoi(resp.Rows[0][0])
 	if err != nil {
-		return 0, fmt.Errorf("udpapi Uptime: %s", err)
+		return 0, fmt.Errorf("udpapi Uptime: %w", err)
 	}
 	return time, nil
 }
 
-// request sends a request to the underlying mux, with rate limiting.
+// A TopWindow is the time window for the TOP command.
+type TopWindow int
+
+const (
+	TopWeek TopWindow = iota
+	TopMonth
+	TopAll
+)
+
+func (w TopWindow) String() string {
+	switch w {
+	case TopWeek:
+		return "week"
+	case TopMonth:
+		return "month"
+	case TopAll:
+		return "all"
+	default:
+		return fmt.Sprintf("TopWindow(%d)", int(w))
+	}
+}
+
+// A TopEntry is a ranked row returned by the TOP command.
+type TopEntry struct {
+	Rank  int
+	AID   int
+	Value int
+}
+
+// Top calls the TOP command for the given time window.
+// The returned error wraps a [codes.ReturnCode] if applicable.
+func (c *Client) Top(ctx context.Context, w TopWindow) ([]TopEntry, error) {
+	v, err := c.sessionValues(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("udpapi Top: %w", err)
+	}
+	v.Set("window", w.String())
+	resp, err := c.request(ctx, "TOP", v)
+	if err != nil {
+		return nil, fmt.Errorf("udpapi Top: %w", err)
+	}
+	if resp.Code != 207 {
+		return nil, fmt.Errorf("udpapi Top: %w", codeError("TOP", v, resp))
+	}
+	e, err := parseTopRows(resp.Rows)
+	if err != nil {
+		return nil, fmt.Errorf("udpapi Top: %w", err)
+	}
+	return e, nil
+}
+
+// parseTopRows parses the rows of a TOP response.
+func parseTopRows(rows [][]string) ([]TopEntry, error) {
+	e := make([]TopEntry, len(rows))
+	for i, row := range rows {
+		if n := len(row); n != 3 {
+			return nil, fmt.Errorf("parse top row %d: got unexpected number of fields %d", i, n)
+		}
+		var err error
+		for j, p := range []*int{&e[i].Rank, &e[i].AID, &e[i].Value} {
+			*p, err = strconv.Atoi(row[j])
+			if err != nil {
+				return nil, fmt.Errorf("parse top row %d: %s", i, err)
+			}
+		}
+	}
+	return e, nil
+}
+
+// request sends a request to the underlying mux through the
+// interceptors added with Use, followed by the client's own
+// interceptors for caching, re-authentication, retries and rate
+// limiting.
+// DefaultParams are added first, so all interceptors see them.
 func (c *Client) request(ctx context.Context, cmd string, args url.Values) (Response, error) {
-	if err := c.limiter.Wait(ctx); err != nil {
-		return Response{}, err
+	for k, vs := range c.DefaultParams {
+		if args == nil {
+			args = make(url.Values)
+		}
+		if !args.Has(k) {
+			args[k] = append([]string(nil), vs...)
+		}
+	}
+	is := append(slices.Clip(c.interceptors.get()),
+		c.cacheInterceptor,
+		c.reauthInterceptor,
+		c.retryInterceptor,
+		c.limitInterceptor,
+	)
+	return chain(c.m.Request, is)(ctx, cmd, args)
+}
+
+// reauthInterceptor re-authenticates and retries requests that fail
+// due to an invalid session, if AutoReauth is set.
+func (c *Client) reauthInterceptor(next RequestFunc) RequestFunc {
+	return func(ctx context.Context, cmd string, args url.Values) (Response, error) {
+		resp, err := next(ctx, cmd, args)
+		if err != nil || !c.AutoReauth || !args.Has("s") {
+			return resp, err
+		}
+		switch resp.Code {
+		case codes.LOGIN_FIRST, codes.INVALID_SESSION:
+		default:
+			return resp, nil
+		}
+		c.logger.Debug("Session invalid, re-authenticating", "cmd", cmd, "code", resp.Code)
+		if err := c.reauthStale(ctx, args.Get("s")); err != nil {
+			return Response{}, &RequestError{Cmd: cmd, Code: resp.Code, Err: fmt.Errorf("reauth: %w", err)}
+		}
+		args.Set("s", c.sessionKey.get())
+		return next(ctx, cmd, args)
 	}
-	return c.m.Request(ctx, cmd, args)
+}
+
+// retryInterceptor retries requests per RetryPolicy.
+func (c *Client) retryInterceptor(next RequestFunc) RequestFunc {
+	return func(ctx context.Context, cmd string, args url.Values) (Response, error) {
+		p := c.RetryPolicy
+		b := p.backoff()
+		// Retries may be satisfied by late responses to earlier attempts.
+		ctx = withTagChain(ctx, &tagChain{})
+		for attempt := 1; ; attempt++ {
+			resp, err := next(withAttempt(ctx, attempt), cmd, args)
+			if !p.shouldRetry(ctx, cmd, attempt, resp, err) {
+				return resp, err
+			}
+			c.logger.Debug("Retrying request", "cmd", cmd, "attempt", attempt, "code", resp.Code, "error", err)
+			c.m.getMetrics().RequestRetried(cmd)
+			if err := b.wait(ctx); err != nil {
+				return Response{}, &RequestError{Cmd: cmd, Err: err}
+			}
+		}
+	}
+}
+
+// limitInterceptor applies rate limiting, request budgets, lockouts
+// and cool-downs to each request attempt.
+func (c *Client) limitInterceptor(next RequestFunc) RequestFunc {
+	return func(ctx context.Context, cmd string, args url.Values) (Response, error) {
+		if err := c.checkLockout(); err != nil {
+			return Response{}, &RequestError{Cmd: cmd, Err: err}
+		}
+		if !c.budget.take(c.MaxRequests, c.RequestWindow) {
+			return Response{}, &RequestError{Cmd: cmd, Err: ErrRequestBudgetExceeded}
+		}
+		start := time.Now()
+		if err := c.cooldown.wait(ctx); err != nil {
+			return Response{}, &RequestError{Cmd: cmd, Err: err}
+		}
+		p, _ := priorityFromContext(ctx)
+		if err := c.queue.wait(ctx, c.limiter, p); err != nil {
+			return Response{}, &RequestError{Cmd: cmd, Err: err}
+		}
+		sent := time.Now()
+		c.m.getMetrics().ObserveLimiterWait(sent.Sub(start))
+		c.lastRequest.set(sent)
+		resp, err := next(ctx, cmd, args)
+		c.noteRequestResult(ctx, err)
+		c.addStats(sent.Sub(start), time.Since(sent))
+		if err != nil {
+			if resp.Truncated {
+				// Keep the partial response for inspection.
+				return Response{}, responseError(cmd, args, resp, err)
+			}
+			return Response{}, &RequestError{Cmd: cmd, Tag: args.Get("tag"), Err: err}
+		}
+		if err := c.checkBanned(resp); err != nil {
+			return Response{}, responseError(cmd, args, resp, err)
+		}
+		c.noteCooldown(cmd, resp)
+		return resp, nil
+	}
+}
+
+// Stats are cumulative request statistics for a [Client].
+// These are useful for checking if the rate limiter is the bottleneck.
+type Stats struct {
+	// Requests is the number of requests sent.
+	Requests int
+	// LimiterWait is the total time spent waiting for the rate
+	// limiter.
+	LimiterWait time.Duration
+	// NetworkTime is the total time spent waiting for responses.
+	NetworkTime time.Duration
+}
+
+// Stats returns the cumulative request statistics for the client.
+func (c *Client) Stats() Stats {
+	return c.stats.get()
+}
+
+func (c *Client) addStats(wait, network time.Duration) {
+	c.stats.update(func(s *Stats) {
+		s.Requests++
+		s.LimiterWait += wait
+		s.NetworkTime += network
+	})
+}
+
+// A RequestError is an error for a single request.
+// This identifies the failing request in errors from methods that
+// make multiple requests.
+//
+// Client methods return a RequestError for responses with an
+// unexpected return code, which wraps the return code.
+// Use [errors.As] to get the response details, or [errors.Is] to
+// check for a [codes.ReturnCode].
+type RequestError struct {
+	Cmd string
+	// Tag is the request tag, if the request was sent.
+	Tag string
+	// Code is the return code, if a response was received.
+	Code codes.ReturnCode
+	// Header and Rows are the response header and rows, if a
+	// response was received.
+	Header string
+	Rows   [][]string
+	Err    error
+}
+
+func (e *RequestError) Error() string {
+	if e.Tag == "" {
+		return fmt.Sprintf("%s: %s", e.Cmd, e.Err)
+	}
+	return fmt.Sprintf("%s (tag %s): %s", e.Cmd, e.Tag, e.Err)
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// codeError returns an error for a response with an unexpected
+// return code.
+// args should be the args passed to [Client.request].
+// The returned error wraps the return code.
+func codeError(cmd string, args url.Values, resp Response) error {
+	return responseError(cmd, args, resp, fmt.Errorf("got bad return code %w", resp.Code))
+}
+
+// responseError returns an error for a response.
+// args should be the args passed to [Client.request].
+func responseError(cmd string, args url.Values, resp Response, err error) *RequestError {
+	return &RequestError{
+		Cmd:    cmd,
+		Tag:    args.Get("tag"),
+		Code:   resp.Code,
+		Header: resp.Header,
+		Rows:   resp.Rows,
+		Err:    err,
+	}
+}
+
+// apiKey returns the API key to use for ENCRYPT, the only command
+// that uses one.
+func (c *Client) apiKey(u UserInfo) (string, error) {
+	if u.APIKey != "" {
+		return u.APIKey, nil
+	}
+	if c.APIKey != "" {
+		return c.APIKey, nil
+	}
+	return "", ErrNoAPIKey
 }
 
 // sessionValues returns the values to use for the current session.
-func (c *Client) sessionValues() (url.Values, error) {
+// If the session has been idle longer than IdleTimeout, this
+// re-authenticates first.
+func (c *Client) sessionValues(ctx context.Context) (url.Values, error) {
+	if err := c.touchSession(ctx); err != nil {
+		return nil, err
+	}
+	return c.sessionKeyValues()
+}
+
+// touchSession re-authenticates if the session has been idle longer
+// than IdleTimeout.
+func (c *Client) touchSession(ctx context.Context) error {
+	if c.IdleTimeout <= 0 {
+		return nil
+	}
+	// Checking with reauthMu held waits for any re-authentication
+	// in progress, which also counts as a request.
+	c.reauthMu.Lock()
+	defer c.reauthMu.Unlock()
+	if c.sessionKey.get() == "" || time.Since(c.lastRequest.get()) < c.IdleTimeout {
+		return nil
+	}
+	c.logger.Debug("Session idle, re-authenticating")
+	if err := c.reauth(ctx); err != nil {
+		return fmt.Errorf("touch session: %w", err)
+	}
+	return nil
+}
+
+// reauthStale re-authenticates if the session key is still stale.
+// If another request already re-authenticated, this returns without
+// re-authenticating again, and the caller should use the new key.
+func (c *Client) reauthStale(ctx context.Context, stale string) error {
+	c.reauthMu.Lock()
+	defer c.reauthMu.Unlock()
+	if key := c.sessionKey.get(); key != "" && key != stale {
+		return nil
+	}
+	return c.reauth(ctx)
+}
+
+// reauth calls AUTH again for the current session, using
+// [Client.Credentials] if set, or else the last UserInfo passed to
+// [Client.Auth].
+// If ENCRYPT was used for the session, it is called again first.
+// reauthMu must be held.
+func (c *Client) reauth(ctx context.Context) error {
+	if e := c.encryptUser.get(); e != nil {
+		// The old key is no longer valid for the server.
+		c.m.SetBlock(nil)
+		c.encryptKey.set(nil)
+		if err := c.Encrypt(ctx, *e); err != nil {
+			return err
+		}
+	}
+	if c.Credentials != nil {
+		_, err := c.AuthCredentials(ctx)
+		return err
+	}
+	u := c.user.get()
+	if u == nil {
+		return errors.New("no credentials for re-authentication")
+	}
+	_, err := c.Auth(ctx, *u)
+	return err
+}
+
+// sessionKeyValues returns the values to use for the current session
+// without checking whether the session is idle.
+func (c *Client) sessionKeyValues() (url.Values, error) {
 	v := make(url.Values)
 	key := c.sessionKey.get()
 	if key == "" {
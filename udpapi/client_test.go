@@ -0,0 +1,913 @@
+// Copyright (C) 2023 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"go.felesatra.moe/anidb/udpapi/codes"
+)
+
+func TestClient_apiKey(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		desc    string
+		client  string
+		user    string
+		want    string
+		wantErr error
+	}{
+		{desc: "user key", user: "user", want: "user"},
+		{desc: "client key", client: "client", want: "client"},
+		{desc: "user key preferred", client: "client", user: "user", want: "user"},
+		{desc: "no key", wantErr: ErrNoAPIKey},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.desc, func(t *testing.T) {
+			t.Parallel()
+			cl := &Client{APIKey: c.client}
+			got, err := cl.apiKey(UserInfo{APIKey: c.user})
+			if !errors.Is(err, c.wantErr) {
+				t.Fatalf("Got error %v; want %v", err, c.wantErr)
+			}
+			if got != c.want {
+				t.Errorf("Got %q; want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestClient_Encrypt_clientAPIKey(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "209 salt ENCRYPTION ENABLED"
+	})
+	c := s.client
+	c.APIKey = "key"
+	if err := c.Encrypt(ctx, UserInfo{UserName: "ionasal"}); err != nil {
+		t.Fatal(err)
+	}
+	if c.m.block.get() == nil {
+		t.Errorf("Encryption not enabled")
+	}
+	if got := s.requests(); len(got) != 1 || got[0].cmd != "ENCRYPT" {
+		t.Errorf("Got requests %v; want one ENCRYPT", got)
+	}
+}
+
+func TestClient_LoginEncrypted(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		switch cmd {
+		case "ENCRYPT":
+			return "209 salt ENCRYPTION ENABLED"
+		case "PING":
+			return "300 PONG"
+		case "AUTH":
+			return "200 key 1.2.3.4:1234 LOGIN ACCEPTED"
+		case "UPTIME":
+			return "208 UPTIME\n1000"
+		case "LOGOUT":
+			return "203 LOGGED OUT"
+		default:
+			return "598 UNKNOWN COMMAND"
+		}
+	})
+	s.setAPIKey("apikey")
+	c := s.client
+	port, err := c.LoginEncrypted(ctx, UserInfo{UserName: "ionasal", UserPassword: "pass", APIKey: "apikey"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if port != "1.2.3.4:1234" {
+		t.Errorf("Got port %q; want %q", port, "1.2.3.4:1234")
+	}
+	var cmds []string
+	for _, r := range s.requests() {
+		cmds = append(cmds, r.cmd)
+	}
+	if want := []string{"ENCRYPT", "PING", "AUTH", "UPTIME"}; !reflect.DeepEqual(cmds, want) {
+		t.Errorf("Got requests %v; want %v", cmds, want)
+	}
+	if err := c.Logout(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if c.m.block.get() != nil {
+		t.Errorf("Encryption not cleared after logout")
+	}
+}
+
+func TestClient_LoginEncrypted_verifyFailed(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		switch cmd {
+		case "ENCRYPT":
+			return "209 salt ENCRYPTION ENABLED"
+		case "PING":
+			return "300 PONG"
+		case "AUTH":
+			return "200 key 1.2.3.4:1234 LOGIN ACCEPTED"
+		default:
+			return "506 INVALID SESSION"
+		}
+	})
+	s.setAPIKey("apikey")
+	c := s.client
+	if _, err := c.LoginEncrypted(ctx, UserInfo{UserName: "ionasal", APIKey: "apikey"}); !errors.Is(err, codes.INVALID_SESSION) {
+		t.Errorf("Got error %v; want %v", err, codes.INVALID_SESSION)
+	}
+	if c.m.block.get() != nil {
+		t.Errorf("Encryption not cleared")
+	}
+	if c.sessionKey.get() != "" {
+		t.Errorf("Session not cleared")
+	}
+}
+
+func TestClient_Encrypt_noAPIKey(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "209 salt ENCRYPTION ENABLED"
+	})
+	err := s.client.Encrypt(ctx, UserInfo{UserName: "ionasal"})
+	if !errors.Is(err, ErrNoAPIKey) {
+		t.Errorf("Got error %v; want %v", err, ErrNoAPIKey)
+	}
+	if got := s.requests(); len(got) != 0 {
+		t.Errorf("Got requests %v; want none", got)
+	}
+}
+
+func TestClient_ServerAddr(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "300 PONG"
+	})
+	addr := s.client.ServerAddr()
+	if got, want := addr.String(), s.pc.LocalAddr().String(); got != want {
+		t.Errorf("Got server addr %s; want %s", got, want)
+	}
+	c, err := DialUDPAddr(addr, nullLogger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	if got := c.ServerAddr(); !got.IP.Equal(addr.IP) || got.Port != addr.Port {
+		t.Errorf("Got server addr %s; want %s", got, addr)
+	}
+	if err := c.PingSimple(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClient_PingSimple(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "300 PONG"
+	})
+	if err := s.client.PingSimple(ctx); err != nil {
+		t.Fatal(err)
+	}
+	got := s.requests()
+	if len(got) != 1 {
+		t.Fatalf("Got requests %v; want one", got)
+	}
+	if got[0].cmd != "PING" {
+		t.Errorf("Got command %q; want PING", got[0].cmd)
+	}
+	if got[0].args.Has("nat") {
+		t.Errorf("Got args %v; want no nat", got[0].args)
+	}
+}
+
+func TestClient_SetUnescape(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "300 PONG\na/b"
+	})
+	s.client.SetUnescape(func(f string) string {
+		return UnescapePipes(UnescapeField(f))
+	})
+	resp, err := s.client.request(ctx, "PING", url.Values{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"a|b"}}
+	if !reflect.DeepEqual(resp.Rows, want) {
+		t.Errorf("Got %#v; want %#v", resp.Rows, want)
+	}
+}
+
+func TestClient_DefaultParams(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "300 PONG\n1234"
+	})
+	c := s.client
+	c.DefaultParams = url.Values{
+		"trace": {"abc"},
+		"nat":   {"0"},
+	}
+	if _, err := c.Ping(ctx); err != nil {
+		t.Fatal(err)
+	}
+	args := s.requests()[0].args
+	if got := args.Get("trace"); got != "abc" {
+		t.Errorf("Got trace %q; want %q", got, "abc")
+	}
+	if got := args["nat"]; !reflect.DeepEqual(got, []string{"1"}) {
+		t.Errorf("Got nat %q; want %q", got, []string{"1"})
+	}
+}
+
+func TestClient_Stats(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "300 PONG"
+	})
+	c := s.client
+	const every = 20 * time.Millisecond
+	c.limiter = &limiter{
+		short: rate.NewLimiter(rate.Every(every), 1),
+		long:  rate.NewLimiter(rate.Inf, 1),
+	}
+	const n = 4
+	for i := 0; i < n; i++ {
+		if err := c.PingSimple(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got := c.Stats()
+	if got.Requests != n {
+		t.Errorf("Got %d requests; want %d", got.Requests, n)
+	}
+	// The first request does not wait, and network time may count
+	// against the limiter.
+	if want := (n-1)*every - got.NetworkTime; got.LimiterWait < want {
+		t.Errorf("Got limiter wait %v; want at least %v", got.LimiterWait, want)
+	}
+	if got.NetworkTime <= 0 {
+		t.Errorf("Got network time %v; want positive", got.NetworkTime)
+	}
+	if got.NetworkTime >= got.LimiterWait {
+		t.Errorf("Got network time %v; want less than limiter wait %v", got.NetworkTime, got.LimiterWait)
+	}
+}
+
+func TestClient_DisableCompression(t *testing.T) {
+	t.Parallel()
+	for _, disable := range []bool{false, true} {
+		disable := disable
+		t.Run(fmt.Sprint(disable), func(t *testing.T) {
+			t.Parallel()
+			ctx := testContext(t, time.Second)
+			s := newFakeServer(t, func(cmd string, args url.Values) string {
+				return "200 key 1.2.3.4:1234 LOGIN ACCEPTED"
+			})
+			s.client.DisableCompression = disable
+			if _, err := s.client.Auth(ctx, UserInfo{UserName: "ionasal", UserPassword: "pass"}); err != nil {
+				t.Fatal(err)
+			}
+			if got := s.requests()[0].args.Has("comp"); got == disable {
+				t.Errorf("Got comp sent %v with DisableCompression %v", got, disable)
+			}
+		})
+	}
+}
+
+func TestClient_Auth_requestError(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "500 LOGIN FAILED"
+	})
+	_, err := s.client.Auth(ctx, UserInfo{UserName: "ionasal", UserPassword: "pass"})
+	var re *RequestError
+	if !errors.As(err, &re) {
+		t.Fatalf("Got error %v; want RequestError", err)
+	}
+	if re.Cmd != "AUTH" || re.Code != codes.LOGIN_FAILED || re.Header != "LOGIN FAILED" {
+		t.Errorf("Got %#v", re)
+	}
+	if !errors.Is(err, codes.LOGIN_FAILED) {
+		t.Errorf("Got error %v; want %v", err, codes.LOGIN_FAILED)
+	}
+}
+
+func TestClient_RetryPolicy(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	var n int
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		n++
+		if n < 3 {
+			return "602 SERVER BUSY"
+		}
+		return "300 PONG"
+	})
+	c := s.client
+	c.RetryPolicy = RetryPolicy{
+		MaxAttempts: 3,
+		Base:        time.Millisecond,
+		Codes:       []codes.ReturnCode{codes.SERVER_BUSY},
+	}
+	if err := c.PingSimple(ctx); err != nil {
+		t.Fatal(err)
+	}
+	reqs := s.requests()
+	if len(reqs) != 3 {
+		t.Fatalf("Got %d requests; want 3", len(reqs))
+	}
+	if reqs[0].args.Get("tag") == reqs[2].args.Get("tag") {
+		t.Errorf("Got same tag for retried request")
+	}
+}
+
+func TestClient_RetryPolicy_exhausted(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "602 SERVER BUSY"
+	})
+	c := s.client
+	c.RetryPolicy = RetryPolicy{
+		MaxAttempts: 2,
+		Base:        time.Millisecond,
+		Codes:       []codes.ReturnCode{codes.SERVER_BUSY},
+	}
+	if err := c.PingSimple(ctx); err == nil {
+		t.Errorf("Expected error")
+	}
+	if n := len(s.requests()); n != 2 {
+		t.Errorf("Got %d requests; want 2", n)
+	}
+}
+
+func TestClient_RetryPolicy_canceled(t *testing.T) {
+	t.Parallel()
+	ctx, cf := context.WithCancel(context.Background())
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		cf()
+		return "602 SERVER BUSY"
+	})
+	c := s.client
+	c.RetryPolicy = RetryPolicy{
+		MaxAttempts: 3,
+		Base:        time.Hour,
+		Codes:       []codes.ReturnCode{codes.SERVER_BUSY},
+	}
+	if err := c.PingSimple(ctx); err == nil {
+		t.Errorf("Expected error")
+	}
+	if n := len(s.requests()); n != 1 {
+		t.Errorf("Got %d requests; want 1", n)
+	}
+}
+
+func TestRetryPolicy_shouldRetry_timeout(t *testing.T) {
+	t.Parallel()
+	p := RetryPolicy{
+		MaxAttempts:     2,
+		Timeout:         true,
+		TimeoutCommands: []string{"MYLISTDEL"},
+	}
+	timeout := fmt.Errorf("request: %w", context.DeadlineExceeded)
+	cases := []struct {
+		cmd  string
+		want bool
+	}{
+		{cmd: "FILE", want: true},
+		{cmd: "PING", want: true},
+		{cmd: "MYLISTDEL", want: true},
+		{cmd: "AUTH", want: false},
+		{cmd: "ENCRYPT", want: false},
+		{cmd: "MYLISTADD", want: false},
+		{cmd: "VOTE", want: false},
+	}
+	for _, c := range cases {
+		got := p.shouldRetry(context.Background(), c.cmd, 1, Response{}, timeout)
+		if got != c.want {
+			t.Errorf("shouldRetry for %s timeout = %v; want %v", c.cmd, got, c.want)
+		}
+	}
+}
+
+func TestDefaultRetryPolicy(t *testing.T) {
+	t.Parallel()
+	p := DefaultRetryPolicy()
+	p.Codes[0] = codes.BANNED
+	if got := DefaultRetryPolicy().Codes[0]; got != codes.SERVER_BUSY {
+		t.Errorf("Got code %v after modifying a copy; want %v", got, codes.SERVER_BUSY)
+	}
+}
+
+func TestClient_MaxRequests(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "300 PONG"
+	})
+	c := s.client
+	c.MaxRequests = 2
+	for i := 0; i < 2; i++ {
+		if err := c.PingSimple(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := c.PingSimple(ctx)
+	if !errors.Is(err, ErrRequestBudgetExceeded) {
+		t.Errorf("Got error %v; want %v", err, ErrRequestBudgetExceeded)
+	}
+	if n := len(s.requests()); n != 2 {
+		t.Errorf("Got %d requests; want 2", n)
+	}
+}
+
+func TestRequestBudget_window(t *testing.T) {
+	t.Parallel()
+	var b requestBudget
+	if !b.take(1, time.Hour) {
+		t.Fatal("First take failed")
+	}
+	if b.take(1, time.Hour) {
+		t.Fatal("Second take succeeded")
+	}
+	b.start = b.start.Add(-time.Hour)
+	if !b.take(1, time.Hour) {
+		t.Errorf("Take after window failed")
+	}
+}
+
+func TestClient_Top(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		window TopWindow
+		resp   string
+		want   []TopEntry
+	}{
+		{
+			window: TopWeek,
+			resp:   "207 TOP\n1|8076|120\n2|357|98",
+			want: []TopEntry{
+				{Rank: 1, AID: 8076, Value: 120},
+				{Rank: 2, AID: 357, Value: 98},
+			},
+		},
+		{
+			window: TopAll,
+			resp:   "207 TOP\n1|69|40213",
+			want: []TopEntry{
+				{Rank: 1, AID: 69, Value: 40213},
+			},
+		},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.window.String(), func(t *testing.T) {
+			t.Parallel()
+			ctx := testContext(t, time.Second)
+			s := newFakeServer(t, func(cmd string, args url.Values) string {
+				return c.resp
+			})
+			s.client.sessionKey.set("key")
+			got, err := s.client.Top(ctx, c.window)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("Got %#v; want %#v", got, c.want)
+			}
+			reqs := s.requests()
+			if len(reqs) != 1 {
+				t.Fatalf("Got requests %v; want one", reqs)
+			}
+			if got, want := reqs[0].args.Get("window"), c.window.String(); got != want {
+				t.Errorf("Got window %q; want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestParseTopRows_bad(t *testing.T) {
+	t.Parallel()
+	if _, err := parseTopRows([][]string{{"1", "x", "3"}}); err == nil {
+		t.Errorf("Expected error")
+	}
+	if _, err := parseTopRows([][]string{{"1", "2"}}); err == nil {
+		t.Errorf("Expected error")
+	}
+}
+
+func TestClient_IdleTimeout(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	var auths int
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		switch cmd {
+		case "AUTH":
+			auths++
+			return fmt.Sprintf("200 key%d 1234 LOGIN ACCEPTED", auths)
+		case "UPTIME":
+			return "208 UPTIME\n1000"
+		default:
+			return "598 UNKNOWN COMMAND"
+		}
+	})
+	c := s.client
+	c.IdleTimeout = time.Minute
+	if _, err := c.Auth(ctx, UserInfo{UserName: "ionasal", UserPassword: "pass"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Uptime(ctx); err != nil {
+		t.Fatal(err)
+	}
+	c.lastRequest.set(time.Now().Add(-time.Hour))
+	if _, err := c.Uptime(ctx); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range s.requests() {
+		got = append(got, r.cmd+" "+r.args.Get("s"))
+	}
+	want := []string{"AUTH ", "UPTIME key1", "AUTH ", "UPTIME key2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got requests %q; want %q", got, want)
+	}
+}
+
+func TestClient_AutoReauth(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	var auths int
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		switch cmd {
+		case "AUTH":
+			auths++
+			return fmt.Sprintf("200 key%d 1234 LOGIN ACCEPTED", auths)
+		case "UPTIME":
+			if args.Get("s") == "key1" {
+				return "506 INVALID SESSION"
+			}
+			return "208 UPTIME\n1000"
+		default:
+			return "598 UNKNOWN COMMAND"
+		}
+	})
+	c := s.client
+	c.AutoReauth = true
+	if _, err := c.Auth(ctx, UserInfo{UserName: "ionasal", UserPassword: "pass"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Uptime(ctx); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range s.requests() {
+		got = append(got, r.cmd+" "+r.args.Get("s"))
+	}
+	want := []string{"AUTH ", "UPTIME key1", "AUTH ", "UPTIME key2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got requests %q; want %q", got, want)
+	}
+}
+
+func TestClient_AutoReauth_concurrent(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	var auths int
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		switch cmd {
+		case "AUTH":
+			auths++
+			return fmt.Sprintf("200 key%d 1234 LOGIN ACCEPTED", auths)
+		case "UPTIME":
+			if args.Get("s") == "key1" {
+				return "506 INVALID SESSION"
+			}
+			return "208 UPTIME\n1000"
+		default:
+			return "598 UNKNOWN COMMAND"
+		}
+	})
+	c := s.client
+	c.AutoReauth = true
+	if _, err := c.Auth(ctx, UserInfo{UserName: "ionasal", UserPassword: "pass"}); err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.Uptime(ctx); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	var n int
+	for _, r := range s.requests() {
+		if r.cmd == "AUTH" {
+			n++
+		}
+	}
+	if n != 2 {
+		t.Errorf("Got %d AUTH requests; want 2", n)
+	}
+}
+
+func TestClient_IdleTimeout_concurrent(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	var auths int
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		switch cmd {
+		case "AUTH":
+			auths++
+			return fmt.Sprintf("200 key%d 1234 LOGIN ACCEPTED", auths)
+		case "UPTIME":
+			return "208 UPTIME\n1000"
+		default:
+			return "598 UNKNOWN COMMAND"
+		}
+	})
+	c := s.client
+	c.IdleTimeout = time.Minute
+	if _, err := c.Auth(ctx, UserInfo{UserName: "ionasal", UserPassword: "pass"}); err != nil {
+		t.Fatal(err)
+	}
+	c.lastRequest.set(time.Now().Add(-time.Hour))
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.Uptime(ctx); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	var n int
+	for _, r := range s.requests() {
+		switch {
+		case r.cmd == "AUTH":
+			n++
+		case r.args.Get("s") != "key2":
+			t.Errorf("Got %s with session %q; want key2", r.cmd, r.args.Get("s"))
+		}
+	}
+	if n != 2 {
+		t.Errorf("Got %d AUTH requests; want 2", n)
+	}
+}
+
+func TestClient_AutoReauth_once(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		switch cmd {
+		case "AUTH":
+			return "200 key 1234 LOGIN ACCEPTED"
+		case "UPTIME":
+			return "501 LOGIN FIRST"
+		default:
+			return "598 UNKNOWN COMMAND"
+		}
+	})
+	c := s.client
+	c.AutoReauth = true
+	if _, err := c.Auth(ctx, UserInfo{UserName: "ionasal", UserPassword: "pass"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Uptime(ctx); err == nil {
+		t.Errorf("Expected error")
+	}
+	if n := len(s.requests()); n != 4 {
+		t.Errorf("Got %d requests; want 4", n)
+	}
+}
+
+func TestClient_Credentials(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		switch cmd {
+		case "AUTH":
+			return "200 key 1234 LOGIN ACCEPTED"
+		case "UPTIME":
+			return "208 UPTIME\n1000"
+		default:
+			return "598 UNKNOWN COMMAND"
+		}
+	})
+	c := s.client
+	c.IdleTimeout = time.Minute
+	c.Credentials = &rotatingCredentials{}
+	if _, err := c.AuthCredentials(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if u := c.user.get(); u != nil {
+		t.Errorf("Got stored user %+v; want none", u)
+	}
+	c.lastRequest.set(time.Now().Add(-time.Hour))
+	if _, err := c.Uptime(ctx); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range s.requests() {
+		if r.cmd == "AUTH" {
+			got = append(got, r.args.Get("pass"))
+		}
+	}
+	want := []string{"pass1", "pass2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got AUTH passwords %q; want %q", got, want)
+	}
+}
+
+// A rotatingCredentials returns a new password on each call.
+type rotatingCredentials struct {
+	mu sync.Mutex
+	n  int
+}
+
+func (r *rotatingCredentials) Credentials(context.Context) (UserInfo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.n++
+	return UserInfo{
+		UserName:     "ionasal",
+		UserPassword: fmt.Sprintf("pass%d", r.n),
+	}, nil
+}
+
+// A fakeServer is a fake AniDB UDP API server for testing a Client.
+type fakeServer struct {
+	client *Client
+	pc     net.PacketConn
+
+	mu       sync.Mutex
+	handler  func(cmd string, args url.Values) string
+	received []fakeRequest
+	// apiKey, if set, enables encryption after an ENCRYPT request
+	// is accepted.
+	apiKey string
+	block  cipher.Block
+}
+
+// A fakeRequest is a request received by a fakeServer.
+type fakeRequest struct {
+	cmd  string
+	args url.Values
+}
+
+// newFakeServer starts a fakeServer with a connected Client.
+// The handler returns the response without the tag.
+// The Client does no rate limiting.
+func newFakeServer(t *testing.T, h func(cmd string, args url.Values) string) *fakeServer {
+	t.Helper()
+	pc, err := net.ListenPacket("udp", "127.0.0.1:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { pc.Close() })
+	c, err := Dial(pc.LocalAddr().String(), nullLogger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	c.limiter = &limiter{
+		short: rate.NewLimiter(rate.Inf, 1),
+		long:  rate.NewLimiter(rate.Inf, 1),
+	}
+	s := &fakeServer{
+		client:  c,
+		pc:      pc,
+		handler: h,
+	}
+	go s.serve()
+	return s
+}
+
+func (s *fakeServer) serve() {
+	buf := make([]byte, 1400)
+	for {
+		n, addr, err := s.pc.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		block := s.block
+		s.mu.Unlock()
+		data := buf[:n]
+		if block != nil {
+			data, err = decrypt(block, data)
+			if err != nil {
+				// Like the real server, ignore requests
+				// encrypted with the wrong key.
+				continue
+			}
+		}
+		cmd, rest, _ := strings.Cut(string(data), " ")
+		args, err := url.ParseQuery(rest)
+		if err != nil {
+			if block != nil {
+				continue
+			}
+			panic(err)
+		}
+		s.mu.Lock()
+		s.received = append(s.received, fakeRequest{cmd: cmd, args: args})
+		h := s.handler
+		s.mu.Unlock()
+		resp := h(cmd, args)
+		if resp == "" {
+			continue
+		}
+		out := []byte(fmt.Sprintf("%s %s", args.Get("tag"), resp))
+		if block != nil {
+			out = encrypt(block, out)
+		}
+		_, _ = s.pc.WriteTo(out, addr)
+		if cmd == "ENCRYPT" {
+			s.startEncryption(resp)
+		}
+	}
+}
+
+// startEncryption enables encryption if the response to an ENCRYPT
+// request accepted it.
+func (s *fakeServer) startEncryption(resp string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.apiKey == "" || !strings.HasPrefix(resp, "209 ") {
+		return
+	}
+	salt, _, _ := strings.Cut(strings.TrimPrefix(resp, "209 "), " ")
+	sum := md5.Sum([]byte(s.apiKey + salt))
+	b, err := aes.NewCipher(sum[:])
+	if err != nil {
+		panic(err)
+	}
+	s.block = b
+}
+
+// setAPIKey sets the API key used for encryption.
+func (s *fakeServer) setAPIKey(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.apiKey = key
+}
+
+// requests returns a copy of the received requests, which callers
+// may modify.
+func (s *fakeServer) requests() []fakeRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	reqs := make([]fakeRequest, len(s.received))
+	for i, r := range s.received {
+		args := make(url.Values, len(r.args))
+		for k, vs := range r.args {
+			args[k] = append([]string(nil), vs...)
+		}
+		reqs[i] = fakeRequest{cmd: r.cmd, args: args}
+	}
+	return reqs
+}
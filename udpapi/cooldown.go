@@ -0,0 +1,124 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.felesatra.moe/anidb/udpapi/codes"
+)
+
+// A CooldownPolicy configures the cool-down shared by all requests of
+// a [Client] after the server responds with SERVER_BUSY or TIMEOUT.
+// The zero value disables the cool-down.
+//
+// During a cool-down, requests wait before being sent, so a busy
+// server is not hammered by each caller independently.
+// Consecutive cool-downs back off exponentially with jitter, and
+// the backoff is reset by any other response.
+type CooldownPolicy struct {
+	// Base is the first cool-down.
+	// If zero, there is no cool-down.
+	Base time.Duration
+	// Cap is the maximum cool-down, before jitter.
+	// If zero, there is no maximum.
+	Cap time.Duration
+}
+
+// DefaultCooldownPolicy is a reasonable CooldownPolicy.
+var DefaultCooldownPolicy = CooldownPolicy{
+	Base: 5 * time.Second,
+	Cap:  5 * time.Minute,
+}
+
+// A cooldown is the shared cool-down state of a client.
+// This is concurrent safe.
+type cooldown struct {
+	mu    sync.Mutex
+	until time.Time
+	b     backoff
+}
+
+// trigger starts a cool-down, unless one is already in effect, such
+// as for other in-flight requests that got the same response.
+// Returns the cool-down duration, or 0 if none was started.
+func (c *cooldown) trigger(p CooldownPolicy) time.Duration {
+	if p.Base <= 0 {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if now.Before(c.until) {
+		return 0
+	}
+	c.b.base = p.Base
+	c.b.cap = p.Cap
+	c.b.jitter = 0.2
+	d, _ := c.b.next()
+	c.until = now.Add(d)
+	return d
+}
+
+// reset resets the backoff after a normal response.
+func (c *cooldown) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.b.reset()
+}
+
+func (c *cooldown) getUntil() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.until
+}
+
+// wait waits until the cool-down is over.
+func (c *cooldown) wait(ctx context.Context) error {
+	for {
+		d := time.Until(c.getUntil())
+		if d <= 0 {
+			return nil
+		}
+		t := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// CooldownUntil returns the time until which requests wait after a
+// SERVER_BUSY or TIMEOUT response.
+// See [Client.Cooldown].
+func (c *Client) CooldownUntil() time.Time {
+	return c.cooldown.getUntil()
+}
+
+// noteCooldown updates the cool-down state for a response.
+func (c *Client) noteCooldown(cmd string, resp Response) {
+	switch resp.Code {
+	case codes.SERVER_BUSY, codes.TIMEOUT:
+		if d := c.cooldown.trigger(c.Cooldown); d > 0 {
+			c.logger.Warn("Server busy, cooling down", "cmd", cmd, "code", resp.Code, "duration", d)
+		}
+	default:
+		c.cooldown.reset()
+	}
+}
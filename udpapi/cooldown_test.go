@@ -0,0 +1,84 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_Cooldown(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, 5*time.Second)
+	var n atomic.Int32
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		if n.Add(1) == 1 {
+			return "602 SERVER BUSY"
+		}
+		return "300 PONG"
+	})
+	c := s.client
+	c.Cooldown = CooldownPolicy{Base: 100 * time.Millisecond}
+	resp, err := c.request(ctx, "PING", url.Values{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Code != 602 {
+		t.Fatalf("Got code %v; want 602", resp.Code)
+	}
+	until := c.CooldownUntil()
+	if d := time.Until(until); d <= 0 {
+		t.Fatalf("Got cool-down until %v; want in the future", until)
+	}
+	start := time.Now()
+	resp, err = c.request(ctx, "PING", url.Values{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Code != 300 {
+		t.Errorf("Got code %v; want 300", resp.Code)
+	}
+	if time.Now().Before(until) {
+		t.Errorf("Request sent at %v, during cool-down until %v", start, until)
+	}
+}
+
+func TestCooldown_trigger(t *testing.T) {
+	t.Parallel()
+	var c cooldown
+	p := CooldownPolicy{Base: time.Hour, Cap: 2 * time.Hour}
+	if d := c.trigger(p); d < 48*time.Minute || d > 72*time.Minute {
+		t.Errorf("Got first cool-down %v; want about 1h", d)
+	}
+	// Already cooling down, so not extended.
+	if d := c.trigger(p); d != 0 {
+		t.Errorf("Got cool-down %v during cool-down; want 0", d)
+	}
+	// Expire the cool-down; the next one backs off.
+	c.until = time.Time{}
+	if d := c.trigger(p); d < 96*time.Minute {
+		t.Errorf("Got second cool-down %v; want about 2h", d)
+	}
+	c.reset()
+	c.until = time.Time{}
+	if d := c.trigger(p); d > 72*time.Minute {
+		t.Errorf("Got cool-down %v after reset; want about 1h", d)
+	}
+	if d := c.trigger(CooldownPolicy{}); d != 0 {
+		t.Errorf("Got cool-down %v for zero policy; want 0", d)
+	}
+}
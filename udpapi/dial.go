@@ -0,0 +1,100 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+)
+
+// A DialConfig configures how a [Client] connects to the server.
+// The zero value is the same as [Dial].
+type DialConfig struct {
+	// Network is "udp", "udp4" or "udp6".
+	// If empty, "udp" is used.
+	Network string
+	// LocalAddr is the local address to bind, such as a fixed port
+	// for NAT pinning.
+	// If nil, a local address is chosen automatically.
+	LocalAddr *net.UDPAddr
+	// Resolver resolves the server address.
+	// If nil, [net.DefaultResolver] is used.
+	Resolver *net.Resolver
+	// DialUDP connects to the server.
+	// If nil, [net.DialUDP] is used.
+	DialUDP func(ctx context.Context, network string, laddr, raddr *net.UDPAddr) (*net.UDPConn, error)
+}
+
+// Dial connects to an AniDB UDP API server.
+// See [Dial].
+//
+// The configuration is also used for [Client.Redial].
+func (cfg DialConfig) Dial(ctx context.Context, addr string, l *slog.Logger) (*Client, error) {
+	raddr, err := cfg.resolve(ctx, addr)
+	if err != nil {
+		return nil, fmt.Errorf("udpapi Dial: %w", err)
+	}
+	conn, err := cfg.dial(ctx, raddr)
+	if err != nil {
+		return nil, fmt.Errorf("udpapi Dial: %w", err)
+	}
+	return newClient(conn, cfg, addr, l), nil
+}
+
+func (cfg DialConfig) network() string {
+	if cfg.Network == "" {
+		return "udp"
+	}
+	return cfg.Network
+}
+
+// resolve resolves a server address.
+func (cfg DialConfig) resolve(ctx context.Context, addr string) (*net.UDPAddr, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	r := cfg.Resolver
+	if r == nil {
+		r = net.DefaultResolver
+	}
+	network := cfg.network()
+	p, err := r.LookupPort(ctx, network, port)
+	if err != nil {
+		return nil, err
+	}
+	if host == "" {
+		return &net.UDPAddr{Port: p}, nil
+	}
+	ips, err := r.LookupNetIP(ctx, "ip"+strings.TrimPrefix(network, "udp"), host)
+	if err != nil {
+		return nil, err
+	}
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("no addresses for %s", host)
+	}
+	return &net.UDPAddr{IP: ips[0].Unmap().AsSlice(), Port: p, Zone: ips[0].Zone()}, nil
+}
+
+// dial connects to a resolved server address.
+func (cfg DialConfig) dial(ctx context.Context, raddr *net.UDPAddr) (*net.UDPConn, error) {
+	if cfg.DialUDP != nil {
+		return cfg.DialUDP(ctx, cfg.network(), cfg.LocalAddr, raddr)
+	}
+	return net.DialUDP(cfg.network(), cfg.LocalAddr, raddr)
+}
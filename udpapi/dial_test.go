@@ -0,0 +1,77 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestDialConfig_Dial(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	pc, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { pc.Close() })
+	var dialed bool
+	cfg := DialConfig{
+		Network:   "udp4",
+		LocalAddr: &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)},
+		DialUDP: func(ctx context.Context, network string, laddr, raddr *net.UDPAddr) (*net.UDPConn, error) {
+			dialed = true
+			return net.DialUDP(network, laddr, raddr)
+		},
+	}
+	c, err := cfg.Dial(ctx, pc.LocalAddr().String(), nullLogger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	if !dialed {
+		t.Errorf("DialUDP not called")
+	}
+	if got, want := c.ServerAddr().String(), pc.LocalAddr().String(); got != want {
+		t.Errorf("Got server addr %s; want %s", got, want)
+	}
+}
+
+func TestDialConfig_resolve(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	cases := []struct {
+		network string
+		addr    string
+		want    string
+	}{
+		{"", "127.0.0.1:9000", "127.0.0.1:9000"},
+		{"udp6", "[::1]:9000", "[::1]:9000"},
+		{"udp4", "localhost:9000", "127.0.0.1:9000"},
+		{"", ":9000", ":9000"},
+	}
+	for _, c := range cases {
+		got, err := DialConfig{Network: c.network}.resolve(ctx, c.addr)
+		if err != nil {
+			t.Errorf("resolve(%q, %q): %s", c.network, c.addr, err)
+			continue
+		}
+		if got.String() != c.want {
+			t.Errorf("resolve(%q, %q) = %s; want %s", c.network, c.addr, got, c.want)
+		}
+	}
+}
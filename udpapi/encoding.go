@@ -0,0 +1,97 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"go.felesatra.moe/anidb/udpapi/codes"
+)
+
+// Encoding names for [Client.Encoding] and [Client.SetEncoding].
+// Only these encodings can be decoded.
+const (
+	EncodingUTF8   = "UTF8"
+	EncodingLatin1 = "ISO-8859-1"
+)
+
+// A decoder converts response data in some encoding to UTF-8.
+type decoder func([]byte) []byte
+
+// encodingDecoder returns the decoder for an encoding.
+// A nil decoder means the data is already UTF-8.
+func encodingDecoder(name string) (decoder, error) {
+	n := strings.ToUpper(strings.NewReplacer("-", "", "_", "").Replace(name))
+	switch n {
+	case "UTF8":
+		return nil, nil
+	case "ISO88591", "LATIN1":
+		return decodeLatin1, nil
+	default:
+		return nil, fmt.Errorf("unsupported encoding %q", name)
+	}
+}
+
+// decodeLatin1 converts ISO-8859-1 data to UTF-8.
+func decodeLatin1(b []byte) []byte {
+	d := make([]byte, 0, len(b))
+	for _, c := range b {
+		d = utf8.AppendRune(d, rune(c))
+	}
+	return d
+}
+
+// SetEncoding sets the encoding used to decode responses.
+// This does not change the encoding used by the server; see
+// [Client.SetEncoding].
+// An error is returned if the encoding is not supported.
+func (m *Mux) SetEncoding(name string) error {
+	d, err := encodingDecoder(name)
+	if err != nil {
+		return fmt.Errorf("mux SetEncoding: %s", err)
+	}
+	m.decoder.set(d)
+	return nil
+}
+
+// SetEncoding calls the ENCODING command to change the encoding of
+// responses for the session, and decodes future responses with it.
+// See the Encoding constants for supported encodings.
+// The returned error wraps a [codes.ReturnCode] if applicable.
+func (c *Client) SetEncoding(ctx context.Context, name string) error {
+	if _, err := encodingDecoder(name); err != nil {
+		return fmt.Errorf("udpapi SetEncoding: %s", err)
+	}
+	v := make(url.Values)
+	if key := c.sessionKey.get(); key != "" {
+		v.Set("s", key)
+	}
+	v.Set("name", name)
+	resp, err := c.request(ctx, "ENCODING", v)
+	if err != nil {
+		return fmt.Errorf("udpapi SetEncoding: %w", err)
+	}
+	if resp.Code != codes.ENCODING_CHANGED {
+		return fmt.Errorf("udpapi SetEncoding: %w", codeError("ENCODING", v, resp))
+	}
+	if err := c.m.SetEncoding(name); err != nil {
+		return fmt.Errorf("udpapi SetEncoding: %w", err)
+	}
+	return nil
+}
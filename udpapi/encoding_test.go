@@ -0,0 +1,92 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"go.felesatra.moe/anidb/udpapi/codes"
+)
+
+func TestDecodeLatin1(t *testing.T) {
+	t.Parallel()
+	got := string(decodeLatin1([]byte("Caf\xe9 \xbd")))
+	if want := "Café ½"; got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+}
+
+func TestClient_SetEncoding(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		switch cmd {
+		case "ENCODING":
+			return "219 ENCODING CHANGED"
+		default:
+			return "295 USER\n1234|Caf\xe9"
+		}
+	})
+	s.client.sessionKey.set("key")
+	if err := s.client.SetEncoding(ctx, EncodingLatin1); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.requests()[0].args.Get("name"); got != EncodingLatin1 {
+		t.Errorf("Got name %q; want %q", got, EncodingLatin1)
+	}
+	got, err := s.client.User(ctx, "cafe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Café"; got.Name != want {
+		t.Errorf("Got name %q; want %q", got.Name, want)
+	}
+}
+
+func TestClient_SetEncoding_notSupported(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "519 ENCODING NOT SUPPORTED"
+	})
+	err := s.client.SetEncoding(ctx, EncodingUTF8)
+	if !errors.Is(err, codes.ENCODING_NOT_SUPPORTED) {
+		t.Errorf("Got error %v; want %v", err, codes.ENCODING_NOT_SUPPORTED)
+	}
+	if err := s.client.SetEncoding(ctx, "EUC-JP"); err == nil {
+		t.Errorf("Expected error for unsupported encoding")
+	}
+	if n := len(s.requests()); n != 1 {
+		t.Errorf("Got %d requests; want 1", n)
+	}
+}
+
+func TestClient_Auth_encoding(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "200 key 1.2.3.4:1234 LOGIN ACCEPTED"
+	})
+	s.client.Encoding = EncodingUTF8
+	if _, err := s.client.Auth(ctx, UserInfo{UserName: "ionasal", UserPassword: "pass"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.requests()[0].args.Get("enc"); got != EncodingUTF8 {
+		t.Errorf("Got enc %q; want %q", got, EncodingUTF8)
+	}
+}
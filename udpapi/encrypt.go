@@ -0,0 +1,86 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"context"
+	"crypto/md5"
+	"errors"
+	"fmt"
+)
+
+// An EncryptionType is an encryption type for the ENCRYPT command.
+type EncryptionType int
+
+// EncryptionAES128 is AES-128 with a key derived from the API key
+// and salt with MD5.
+// This is the only encryption type supported by AniDB.
+const EncryptionAES128 EncryptionType = 1
+
+// supported returns true if the client can derive keys for the
+// encryption type.
+func (t EncryptionType) supported() bool {
+	return t == EncryptionAES128
+}
+
+// DeriveKey derives the session key for the encryption type from
+// the API key and the salt returned by ENCRYPT.
+// This is useful for setting up encryption with [Mux.SetBlock]
+// directly.
+func DeriveKey(t EncryptionType, apiKey, salt string) ([]byte, error) {
+	switch t {
+	case EncryptionAES128:
+		sum := md5.Sum([]byte(apiKey + salt))
+		return sum[:], nil
+	default:
+		return nil, fmt.Errorf("derive key: unsupported encryption type %d", t)
+	}
+}
+
+// ErrNoSuchEncryptionType is returned by ENCRYPT when the server
+// does not support the requested encryption type.
+// The returned error also wraps [codes.NO_SUCH_ENCRYPTION_TYPE].
+var ErrNoSuchEncryptionType = errors.New("no such encryption type")
+
+// ErrEncryptionFailed is returned by [Client.VerifyEncryption] when
+// the server does not respond to encrypted requests, which usually
+// means the API key is wrong.
+var ErrEncryptionFailed = errors.New("encryption not working (check API key)")
+
+// VerifyEncryption checks that the encryption enabled by
+// [Client.Encrypt] works by calling the PING command, which does not
+// need a session.
+// If the server cannot decrypt the request, such as when the API key
+// is wrong, no usable response is received and the returned error
+// wraps [ErrEncryptionFailed].
+//
+// This is useful before AUTH, as the server does not otherwise
+// report a wrong API key.
+func (c *Client) VerifyEncryption(ctx context.Context) error {
+	if err := c.PingSimple(ctx); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("udpapi VerifyEncryption: %w", err)
+		}
+		return fmt.Errorf("udpapi VerifyEncryption: %w: %w", ErrEncryptionFailed, err)
+	}
+	return nil
+}
+
+func (c *Client) encryptionType() EncryptionType {
+	if c.EncryptionType == 0 {
+		return EncryptionAES128
+	}
+	return c.EncryptionType
+}
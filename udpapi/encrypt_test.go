@@ -0,0 +1,105 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"crypto/md5"
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.felesatra.moe/anidb/udpapi/codes"
+)
+
+func TestDeriveKey(t *testing.T) {
+	t.Parallel()
+	got, err := DeriveKey(EncryptionAES128, "apikey", "salt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := md5.Sum([]byte("apikeysalt"))
+	if !reflect.DeepEqual(got, want[:]) {
+		t.Errorf("Got %x; want %x", got, want)
+	}
+	if _, err := DeriveKey(2, "apikey", "salt"); err == nil {
+		t.Errorf("Expected error for unsupported type")
+	}
+}
+
+func TestClient_Encrypt_noSuchType(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "509 NO SUCH ENCRYPTION TYPE"
+	})
+	err := s.client.Encrypt(ctx, UserInfo{UserName: "ionasal", APIKey: "apikey"})
+	if !errors.Is(err, ErrNoSuchEncryptionType) {
+		t.Errorf("Got error %v; want %v", err, ErrNoSuchEncryptionType)
+	}
+	if !errors.Is(err, codes.NO_SUCH_ENCRYPTION_TYPE) {
+		t.Errorf("Got error %v; want %v", err, codes.NO_SUCH_ENCRYPTION_TYPE)
+	}
+	if got := s.requests(); len(got) != 1 || got[0].args.Get("type") != "1" {
+		t.Errorf("Got requests %v; want one with type=1", got)
+	}
+}
+
+func TestClient_Encrypt_unsupportedType(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "209 salt ENCRYPTION ENABLED"
+	})
+	c := s.client
+	c.EncryptionType = 2
+	if err := c.Encrypt(ctx, UserInfo{UserName: "ionasal", APIKey: "apikey"}); err == nil {
+		t.Errorf("Expected error")
+	}
+	if got := s.requests(); len(got) != 0 {
+		t.Errorf("Got requests %v; want none", got)
+	}
+}
+
+func TestClient_LoginEncrypted_wrongKey(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, 5*time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		switch cmd {
+		case "ENCRYPT":
+			return "209 salt ENCRYPTION ENABLED"
+		default:
+			// Requests encrypted with the wrong key are
+			// usually dropped, but may decrypt to garbage.
+			return ""
+		}
+	})
+	s.setAPIKey("apikey")
+	c := s.client
+	c.SetTimeout(50 * time.Millisecond)
+	_, err := c.LoginEncrypted(ctx, UserInfo{UserName: "ionasal", APIKey: "wrong"})
+	if !errors.Is(err, ErrEncryptionFailed) {
+		t.Errorf("Got error %v; want %v", err, ErrEncryptionFailed)
+	}
+	if c.m.block.get() != nil {
+		t.Errorf("Encryption not cleared")
+	}
+	for _, r := range s.requests() {
+		if r.cmd == "AUTH" {
+			t.Errorf("Got AUTH request after failed encryption")
+		}
+	}
+}
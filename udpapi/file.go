@@ -0,0 +1,443 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.felesatra.moe/anidb/udpapi/codes"
+)
+
+// A FileInfo is the decoded result of a FILE command.
+// Only the fields requested in the masks are set.
+//
+// FileInfo can be encoded as JSON.
+// Length is encoded as a whole number of seconds in length_seconds,
+// and Quality and Source are encoded as strings.
+type FileInfo struct {
+	FID   int `json:"fid" anidb:"fid"`
+	AID   int `json:"aid" anidb:"aid"`
+	EID   int `json:"eid" anidb:"eid"`
+	GID   int `json:"gid" anidb:"gid"`
+	LID   int `json:"lid" anidb:"mylist id"`
+	State int `json:"state" anidb:"state"`
+	// Deprecated is set if the file has been deprecated.
+	Deprecated bool `json:"deprecated" anidb:"is deprecated"`
+
+	Size       int64  `json:"size" anidb:"size"`
+	ED2K       string `json:"ed2k" anidb:"ed2k"`
+	MD5        string `json:"md5" anidb:"md5"`
+	SHA1       string `json:"sha1" anidb:"sha1"`
+	CRC32      string `json:"crc32" anidb:"crc32"`
+	ColorDepth string `json:"color_depth" anidb:"video colour depth"`
+
+	// Quality is the parsed quality.
+	// QualityRaw is the quality as returned by AniDB.
+	Quality    Quality `json:"quality" anidb:"quality"`
+	QualityRaw string  `json:"quality_raw" anidb:"quality"`
+	// Source is the parsed source.
+	// SourceRaw is the source as returned by AniDB.
+	Source    Source `json:"source" anidb:"source"`
+	SourceRaw string `json:"source_raw" anidb:"source"`
+	// AudioCodecs and AudioBitrates are parallel lists for each
+	// audio stream.
+	AudioCodecs     []string `json:"audio_codecs" anidb:"audio codec list"`
+	AudioBitrates   []int    `json:"audio_bitrates" anidb:"audio bitrate list"`
+	VideoCodec      string   `json:"video_codec" anidb:"video codec"`
+	VideoBitrate    int      `json:"video_bitrate" anidb:"video bitrate"`
+	VideoResolution string   `json:"video_resolution" anidb:"video resolution"`
+	FileType        string   `json:"file_type" anidb:"file type"`
+	DubLanguages    []string `json:"dub_languages" anidb:"dub language"`
+	SubLanguages    []string `json:"sub_languages" anidb:"sub language"`
+	// Length is the length of the file.
+	Length      time.Duration `json:"-" anidb:"length in seconds"`
+	Description string        `json:"description" anidb:"description"`
+	// AiredDate is zero if unknown.
+	AiredDate     time.Time `json:"aired_date" anidb:"aired date"`
+	AniDBFileName string    `json:"anidb_file_name" anidb:"anidb file name"`
+
+	MylistState     MylistState `json:"mylist_state" anidb:"mylist state"`
+	MylistFileState int         `json:"mylist_file_state" anidb:"mylist filestate"`
+	MylistViewed    bool        `json:"mylist_viewed" anidb:"mylist viewed"`
+	// MylistViewDate is zero if the file has not been watched.
+	MylistViewDate time.Time `json:"mylist_view_date" anidb:"mylist viewdate"`
+	MylistStorage  string    `json:"mylist_storage" anidb:"mylist storage"`
+	MylistSource   string    `json:"mylist_source" anidb:"mylist source"`
+	MylistOther    string    `json:"mylist_other" anidb:"mylist other"`
+
+	AnimeEpisodes  int    `json:"anime_episodes" anidb:"anime total episodes"`
+	HighestEpisode int    `json:"highest_episode" anidb:"highest episode number"`
+	Year           string `json:"year" anidb:"year"`
+	Type           string `json:"type" anidb:"type"`
+	RomajiName     string `json:"romaji_name" anidb:"romaji name"`
+	KanjiName      string `json:"kanji_name" anidb:"kanji name"`
+	EnglishName    string `json:"english_name" anidb:"english name"`
+	EpNo           string `json:"epno" anidb:"epno"`
+	EpName         string `json:"ep_name" anidb:"ep name"`
+	EpRomajiName   string `json:"ep_romaji_name" anidb:"ep romaji name"`
+	EpKanjiName    string `json:"ep_kanji_name" anidb:"ep kanji name"`
+	GroupName      string `json:"group_name" anidb:"group name"`
+	GroupShortName string `json:"group_short_name" anidb:"group short name"`
+}
+
+// fileInfoJSON is the JSON representation of FileInfo.
+type fileInfoJSON struct {
+	fileInfoAlias
+	LengthSeconds int64 `json:"length_seconds"`
+}
+
+// fileInfoAlias is FileInfo without its JSON methods.
+type fileInfoAlias FileInfo
+
+func (fi FileInfo) MarshalJSON() ([]byte, error) {
+	return json.Marshal(fileInfoJSON{
+		fileInfoAlias: fileInfoAlias(fi),
+		LengthSeconds: int64(fi.Length / time.Second),
+	})
+}
+
+func (fi *FileInfo) UnmarshalJSON(b []byte) error {
+	var j fileInfoJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	*fi = FileInfo(j.fileInfoAlias)
+	fi.Length = time.Duration(j.LengthSeconds) * time.Second
+	return nil
+}
+
+// FileInfoByHash calls the FILE command by size+ed2k hash and decodes
+// the result.
+// The returned error wraps a [codes.ReturnCode] if applicable.
+func (c *Client) FileInfoByHash(ctx context.Context, size int64, hash string, fmask FileFmask, amask FileAmask) (FileInfo, error) {
+	row, err := c.FileByHash(ctx, size, hash, fmask, amask)
+	if err != nil {
+		return FileInfo{}, err
+	}
+	fi, err := DecodeFileInfo(fmask, amask, row)
+	if err != nil {
+		return FileInfo{}, fmt.Errorf("udpapi FileInfoByHash: %w", err)
+	}
+	return fi, nil
+}
+
+// FileByFID calls the FILE command by fid and returns the row as
+// received, before unescaping.
+// See [Client.FileInfoByFID] for decoding the result.
+// The returned error wraps a [codes.ReturnCode] if applicable.
+func (c *Client) FileByFID(ctx context.Context, fid int, fmask FileFmask, amask FileAmask) ([]string, error) {
+	v, err := c.sessionValues(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("udpapi FileByFID: %w", err)
+	}
+	v.Set("fid", strconv.Itoa(fid))
+	row, err := c.fileRow(ctx, v, fmask, amask)
+	if err != nil {
+		return nil, fmt.Errorf("udpapi FileByFID: %w", err)
+	}
+	return row, nil
+}
+
+// FileInfoByFID calls the FILE command by fid and decodes the result.
+// The returned error wraps a [codes.ReturnCode] if applicable.
+func (c *Client) FileInfoByFID(ctx context.Context, fid int, fmask FileFmask, amask FileAmask) (FileInfo, error) {
+	row, err := c.FileByFID(ctx, fid, fmask, amask)
+	if err != nil {
+		return FileInfo{}, err
+	}
+	fi, err := DecodeFileInfo(fmask, amask, row)
+	if err != nil {
+		return FileInfo{}, fmt.Errorf("udpapi FileInfoByFID: %w", err)
+	}
+	return fi, nil
+}
+
+// A FileQuery identifies files by anime, group and episode for
+// [Client.FileByAnime] and [Client.MylistAddByAnime].
+// The anime and group may be given by ID or by name.
+// If both are set, the ID is used.
+type FileQuery struct {
+	AID   int
+	AName string
+	GID   int
+	GName string
+	EpNo  string
+}
+
+func (q FileQuery) setValues(v url.Values) {
+	q.setAnime(v)
+	if q.GID != 0 {
+		v.Set("gid", strconv.Itoa(q.GID))
+	} else {
+		v.Set("gname", q.GName)
+	}
+}
+
+// setAnime sets the anime and episode values.
+func (q FileQuery) setAnime(v url.Values) {
+	if q.AID != 0 {
+		v.Set("aid", strconv.Itoa(q.AID))
+	} else {
+		v.Set("aname", q.AName)
+	}
+	v.Set("epno", q.EpNo)
+}
+
+// A FileResult is the result of a FILE command that may match
+// multiple files.
+// Exactly one of Row or FIDs is set.
+type FileResult struct {
+	// Row is the matched file as received, before unescaping,
+	// which can be decoded with [DecodeFileInfo].
+	Row []string
+	// FIDs are the candidate files if multiple files matched.
+	FIDs []int
+}
+
+// FileByAnime calls the FILE command by anime, group and episode.
+// If multiple files match, their fids are returned in the result.
+// The returned error wraps a [codes.ReturnCode] if applicable.
+func (c *Client) FileByAnime(ctx context.Context, q FileQuery, fmask FileFmask, amask FileAmask) (FileResult, error) {
+	v, err := c.sessionValues(ctx)
+	if err != nil {
+		return FileResult{}, fmt.Errorf("udpapi FileByAnime: %w", err)
+	}
+	q.setValues(v)
+	r, err := c.file(ctx, v, fmask, amask)
+	if err != nil {
+		return FileResult{}, fmt.Errorf("udpapi FileByAnime: %w", err)
+	}
+	return r, nil
+}
+
+// fileRow calls the FILE command, expecting a single file.
+func (c *Client) fileRow(ctx context.Context, v url.Values, fmask FileFmask, amask FileAmask) ([]string, error) {
+	r, err := c.file(ctx, v, fmask, amask)
+	if err != nil {
+		return nil, err
+	}
+	if r.Row == nil {
+		return nil, fmt.Errorf("got multiple files %v", r.FIDs)
+	}
+	return r.Row, nil
+}
+
+func (c *Client) file(ctx context.Context, v url.Values, fmask FileFmask, amask FileAmask) (FileResult, error) {
+	v.Set("fmask", formatMask(fmask[:]))
+	v.Set("amask", formatMask(amask[:]))
+	resp, err := c.request(ctx, "FILE", v)
+	if err != nil {
+		return FileResult{}, err
+	}
+	switch resp.Code {
+	case codes.FILE:
+		if n := len(resp.RawRows); n != 1 {
+			return FileResult{}, fmt.Errorf("got unexpected number of rows %d", n)
+		}
+		return FileResult{Row: resp.RawRows[0]}, nil
+	case codes.MULTIPLE_FILES_FOUND:
+		if n := len(resp.Rows); n != 1 {
+			return FileResult{}, fmt.Errorf("got unexpected number of rows %d", n)
+		}
+		fids := make([]int, len(resp.Rows[0]))
+		for i, f := range resp.Rows[0] {
+			fid, err := strconv.Atoi(f)
+			if err != nil {
+				return FileResult{}, fmt.Errorf("decode fid: %s", err)
+			}
+			fids[i] = fid
+		}
+		return FileResult{FIDs: fids}, nil
+	default:
+		return FileResult{}, codeError("FILE", v, resp)
+	}
+}
+
+// DecodeFileInfo decodes a FILE response row returned for the given
+// masks, such as by [Client.FileByHash].
+// The row should be taken from [Response.RawRows]; see [DecodeRow].
+func DecodeFileInfo(fmask FileFmask, amask FileAmask, row []string) (FileInfo, error) {
+	var fi FileInfo
+	if err := decodeFileRow(fmask, amask, row, &fi); err != nil {
+		return FileInfo{}, fmt.Errorf("decode file info: %w", err)
+	}
+	return fi, nil
+}
+
+// parseSeconds parses a number of seconds as a duration.
+// An empty string is parsed as zero.
+func parseSeconds(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(n) * time.Second, nil
+}
+
+// maskSpecs returns the specs for the bits set in a mask, in the order
+// that their fields are returned by AniDB.
+func maskSpecs(b []byte, m map[string]bitSpec) ([]bitSpec, error) {
+	var specs []bitSpec
+	for i, byt := range b {
+		for bit := 7; bit >= 0; bit-- {
+			if byt&(1<<bit) == 0 {
+				continue
+			}
+			s, ok := findBitSpec(m, i, bit)
+			if !ok {
+				return nil, fmt.Errorf("unknown mask bit %d.%d", i, bit)
+			}
+			specs = append(specs, s)
+		}
+	}
+	return specs, nil
+}
+
+func findBitSpec(m map[string]bitSpec, byt, bit int) (bitSpec, bool) {
+	for _, s := range m {
+		if s.byte == byt && s.bit == bit {
+			return s, true
+		}
+	}
+	return bitSpec{}, false
+}
+
+// A Quality is the quality of a file.
+type Quality int
+
+const (
+	QualityUnknown Quality = iota
+	QualityVeryHigh
+	QualityHigh
+	QualityMed
+	QualityLow
+	QualityVeryLow
+	QualityCorrupted
+	QualityEyeCancer
+)
+
+var qualityNames = []string{
+	QualityUnknown:   "unknown",
+	QualityVeryHigh:  "very high",
+	QualityHigh:      "high",
+	QualityMed:       "med",
+	QualityLow:       "low",
+	QualityVeryLow:   "very low",
+	QualityCorrupted: "corrupted",
+	QualityEyeCancer: "eye cancer",
+}
+
+func (q Quality) String() string {
+	if q < 0 || int(q) >= len(qualityNames) {
+		return fmt.Sprintf("Quality(%d)", int(q))
+	}
+	return qualityNames[q]
+}
+
+func (q Quality) MarshalText() ([]byte, error) {
+	return []byte(q.String()), nil
+}
+
+func (q *Quality) UnmarshalText(b []byte) error {
+	*q = ParseQuality(string(b))
+	return nil
+}
+
+// ParseQuality parses a FILE quality string.
+// Unrecognized strings are parsed as [QualityUnknown].
+func ParseQuality(s string) Quality {
+	return Quality(parseEnum(qualityNames, s))
+}
+
+// A Source is the source of a file.
+type Source int
+
+const (
+	SourceUnknown Source = iota
+	SourceRaw
+	SourceCamcorder
+	SourceTV
+	SourceDTV
+	SourceHDTV
+	SourceVHS
+	SourceVCD
+	SourceSVCD
+	SourceLD
+	SourceDVD
+	SourceHKDVD
+	SourceHDDVD
+	SourceBluray
+	SourceWWW
+)
+
+var sourceNames = []string{
+	SourceUnknown:   "unknown",
+	SourceRaw:       "raw",
+	SourceCamcorder: "camcorder",
+	SourceTV:        "TV",
+	SourceDTV:       "DTV",
+	SourceHDTV:      "HDTV",
+	SourceVHS:       "VHS",
+	SourceVCD:       "VCD",
+	SourceSVCD:      "SVCD",
+	SourceLD:        "LD",
+	SourceDVD:       "DVD",
+	SourceHKDVD:     "HK DVD",
+	SourceHDDVD:     "HD DVD",
+	SourceBluray:    "Blu-ray",
+	SourceWWW:       "www",
+}
+
+func (s Source) String() string {
+	if s < 0 || int(s) >= len(sourceNames) {
+		return fmt.Sprintf("Source(%d)", int(s))
+	}
+	return sourceNames[s]
+}
+
+func (s Source) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *Source) UnmarshalText(b []byte) error {
+	*s = ParseSource(string(b))
+	return nil
+}
+
+// ParseSource parses a FILE source string.
+// Unrecognized strings are parsed as [SourceUnknown].
+func ParseSource(s string) Source {
+	return Source(parseEnum(sourceNames, s))
+}
+
+// parseEnum returns the index of s in names, ignoring case.
+// Returns 0 if not found.
+func parseEnum(names []string, s string) int {
+	for i, n := range names {
+		if strings.EqualFold(n, s) {
+			return i
+		}
+	}
+	return 0
+}
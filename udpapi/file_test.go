@@ -0,0 +1,321 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDecodeFileInfo(t *testing.T) {
+	t.Parallel()
+	var fm FileFmask
+	fm.Set("aid", "is deprecated", "quality", "source", "anidb file name")
+	var am FileAmask
+	am.Set("epno")
+	row := []string{"312498", "8076", "1", "very high", "Blu-ray", "Bofuri - 01.mkv", "01"}
+	got, err := DecodeFileInfo(fm, am, row)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := FileInfo{
+		FID:           312498,
+		AID:           8076,
+		Deprecated:    true,
+		Quality:       QualityVeryHigh,
+		QualityRaw:    "very high",
+		Source:        SourceBluray,
+		SourceRaw:     "Blu-ray",
+		AniDBFileName: "Bofuri - 01.mkv",
+		EpNo:          "01",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %#v; want %#v", got, want)
+	}
+}
+
+func TestClient_FileInfoByHash(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	var fm FileFmask
+	fm.Set("aid", "mylist id", "size", "ed2k", "audio codec list", "audio bitrate list",
+		"video bitrate", "sub language", "aired date", "mylist state", "mylist viewed")
+	var am FileAmask
+	am.Set("english name", "epno", "group short name")
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		if args.Get("fmask") != formatMask(fm[:]) || args.Get("amask") != formatMask(am[:]) {
+			return "505 ILLEGAL INPUT OR ACCESS DENIED"
+		}
+		return "220 FILE\n312498|8076|5678|733438153|abcdef|AAC'Opus|192'128|4000|english'japanese|1578441600|1|1|BOFURI|01|Commie"
+	})
+	s.client.sessionKey.set("key")
+	got, err := s.client.FileInfoByHash(ctx, 733438153, "abcdef", fm, am)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := FileInfo{
+		FID:            312498,
+		AID:            8076,
+		LID:            5678,
+		Size:           733438153,
+		ED2K:           "abcdef",
+		AudioCodecs:    []string{"AAC", "Opus"},
+		AudioBitrates:  []int{192, 128},
+		VideoBitrate:   4000,
+		SubLanguages:   []string{"english", "japanese"},
+		AiredDate:      time.Date(2020, 1, 8, 0, 0, 0, 0, time.UTC),
+		MylistState:    MylistHDD,
+		MylistViewed:   true,
+		EnglishName:    "BOFURI",
+		EpNo:           "01",
+		GroupShortName: "Commie",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %#v; want %#v", got, want)
+	}
+}
+
+func TestClient_FileInfoByFID(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	var fm FileFmask
+	fm.Set("aid")
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "220 FILE\n312498|8076"
+	})
+	s.client.sessionKey.set("key")
+	got, err := s.client.FileInfoByFID(ctx, 312498, fm, FileAmask{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (FileInfo{FID: 312498, AID: 8076}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %#v; want %#v", got, want)
+	}
+	if args := s.requests()[0].args; args.Get("fid") != "312498" {
+		t.Errorf("Got args %v", args)
+	}
+}
+
+func TestClient_FileByAnime(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		desc     string
+		q        FileQuery
+		resp     string
+		want     FileResult
+		wantArgs map[string]string
+	}{
+		{
+			desc:     "single",
+			q:        FileQuery{AID: 8076, GID: 7172, EpNo: "1"},
+			resp:     "220 FILE\n312498",
+			want:     FileResult{Row: []string{"312498"}},
+			wantArgs: map[string]string{"aid": "8076", "gid": "7172", "epno": "1"},
+		},
+		{
+			desc:     "multiple",
+			q:        FileQuery{AName: "BOFURI", GName: "Commie", EpNo: "1"},
+			resp:     "322 MULTIPLE FILES FOUND\n312498|312499",
+			want:     FileResult{FIDs: []int{312498, 312499}},
+			wantArgs: map[string]string{"aname": "BOFURI", "gname": "Commie", "epno": "1"},
+		},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.desc, func(t *testing.T) {
+			t.Parallel()
+			ctx := testContext(t, time.Second)
+			s := newFakeServer(t, func(cmd string, args url.Values) string {
+				return c.resp
+			})
+			s.client.sessionKey.set("key")
+			got, err := s.client.FileByAnime(ctx, c.q, FileFmask{}, FileAmask{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("Got %#v; want %#v", got, c.want)
+			}
+			args := s.requests()[0].args
+			for k, want := range c.wantArgs {
+				if got := args.Get(k); got != want {
+					t.Errorf("Got %s=%q; want %q", k, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_FileByHash_multiple(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "322 MULTIPLE FILES FOUND\n312498|312499"
+	})
+	s.client.sessionKey.set("key")
+	if _, err := s.client.FileByHash(ctx, 1000, "abcdef", FileFmask{}, FileAmask{}); err == nil {
+		t.Errorf("Expected error")
+	}
+}
+
+func TestDecodeFileInfo_wrongFields(t *testing.T) {
+	t.Parallel()
+	var fm FileFmask
+	fm.Set("aid")
+	if _, err := DecodeFileInfo(fm, FileAmask{}, []string{"1"}); err == nil {
+		t.Errorf("Expected error")
+	}
+}
+
+func TestDecodeFileInfo_length(t *testing.T) {
+	t.Parallel()
+	var fm FileFmask
+	fm.Set("length in seconds")
+	cases := []struct {
+		s    string
+		want time.Duration
+	}{
+		{"1425", 23*time.Minute + 45*time.Second},
+		{"0", 0},
+		{"", 0},
+	}
+	for _, c := range cases {
+		got, err := DecodeFileInfo(fm, FileAmask{}, []string{"1", c.s})
+		if err != nil {
+			t.Errorf("Decode %q: %s", c.s, err)
+			continue
+		}
+		if got.Length != c.want {
+			t.Errorf("Decode %q: got %v; want %v", c.s, got.Length, c.want)
+		}
+	}
+}
+
+func TestFileInfo_JSON(t *testing.T) {
+	t.Parallel()
+	fi := FileInfo{
+		FID:           312498,
+		AID:           8076,
+		EID:           213341,
+		GID:           7172,
+		State:         1,
+		Quality:       QualityHigh,
+		QualityRaw:    "high",
+		Source:        SourceHDTV,
+		SourceRaw:     "HDTV",
+		AudioCodecs:   []string{"AAC", "Opus"},
+		AudioBitrates: []int{192, 128},
+		Length:        23*time.Minute + 45*time.Second,
+		AiredDate:     time.Date(2020, 1, 8, 0, 0, 0, 0, time.UTC),
+		AniDBFileName: "Bofuri - 01.mkv",
+		EpNo:          "01",
+		EpName:        "Defense and First Battle",
+	}
+	b, err := json.Marshal(fi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"fid":             312498.0,
+		"aid":             8076.0,
+		"eid":             213341.0,
+		"gid":             7172.0,
+		"state":           1.0,
+		"deprecated":      false,
+		"quality":         "high",
+		"quality_raw":     "high",
+		"source":          "HDTV",
+		"source_raw":      "HDTV",
+		"audio_codecs":    []any{"AAC", "Opus"},
+		"audio_bitrates":  []any{192.0, 128.0},
+		"length_seconds":  1425.0,
+		"aired_date":      "2020-01-08T00:00:00Z",
+		"dub_languages":   nil,
+		"mylist_state":    0.0,
+		"anidb_file_name": "Bofuri - 01.mkv",
+		"epno":            "01",
+		"ep_name":         "Defense and First Battle",
+	}
+	for k, w := range want {
+		g, ok := got[k]
+		if !ok {
+			t.Errorf("Missing key %q", k)
+			continue
+		}
+		if !reflect.DeepEqual(g, w) {
+			t.Errorf("Got %s=%#v; want %#v", k, g, w)
+		}
+	}
+	var fi2 FileInfo
+	if err := json.Unmarshal(b, &fi2); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fi2, fi) {
+		t.Errorf("Round trip got %#v; want %#v", fi2, fi)
+	}
+}
+
+func TestParseQuality(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		s    string
+		want Quality
+	}{
+		{"very high", QualityVeryHigh},
+		{"high", QualityHigh},
+		{"med", QualityMed},
+		{"low", QualityLow},
+		{"very low", QualityVeryLow},
+		{"corrupted", QualityCorrupted},
+		{"eye cancer", QualityEyeCancer},
+		{"High", QualityHigh},
+		{"bogus", QualityUnknown},
+	}
+	for _, c := range cases {
+		if got := ParseQuality(c.s); got != c.want {
+			t.Errorf("ParseQuality(%q) = %v; want %v", c.s, got, c.want)
+		}
+	}
+}
+
+func TestParseSource(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		s    string
+		want Source
+	}{
+		{"raw", SourceRaw},
+		{"TV", SourceTV},
+		{"HDTV", SourceHDTV},
+		{"DVD", SourceDVD},
+		{"HK DVD", SourceHKDVD},
+		{"Blu-ray", SourceBluray},
+		{"www", SourceWWW},
+		{"dvd", SourceDVD},
+		{"bogus", SourceUnknown},
+	}
+	for _, c := range cases {
+		if got := ParseSource(c.s); got != c.want {
+			t.Errorf("ParseSource(%q) = %v; want %v", c.s, got, c.want)
+		}
+	}
+}
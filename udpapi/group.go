@@ -0,0 +1,118 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// A GroupStatusRow is a row returned by the GROUPSTATUS command.
+type GroupStatusRow struct {
+	GID       int
+	GroupName string
+	State     CompletionState
+	// LastEpisode is the last episode number released by the group.
+	LastEpisode  int
+	Rating       int
+	Votes        int
+	EpisodeRange string
+}
+
+// A CompletionState is the completion state of a group's releases for
+// an anime.
+type CompletionState int
+
+const (
+	StateUnknown     CompletionState = 0
+	StateOngoing     CompletionState = 1
+	StateStalled     CompletionState = 2
+	StateComplete    CompletionState = 3
+	StateDropped     CompletionState = 4
+	StateFinished    CompletionState = 5
+	StateSpecialOnly CompletionState = 6
+)
+
+var completionStateNames = []string{
+	StateUnknown:     "unknown",
+	StateOngoing:     "ongoing",
+	StateStalled:     "stalled",
+	StateComplete:    "complete",
+	StateDropped:     "dropped",
+	StateFinished:    "finished",
+	StateSpecialOnly: "specials only",
+}
+
+func (s CompletionState) String() string {
+	if s < 0 || int(s) >= len(completionStateNames) {
+		return fmt.Sprintf("CompletionState(%d)", int(s))
+	}
+	return completionStateNames[s]
+}
+
+// GroupStatus calls the GROUPSTATUS command.
+// The returned error wraps a [codes.ReturnCode] if applicable.
+func (c *Client) GroupStatus(ctx context.Context, aid int) ([]GroupStatusRow, error) {
+	v, err := c.sessionValues(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("udpapi GroupStatus: %w", err)
+	}
+	v.Set("aid", strconv.Itoa(aid))
+	resp, err := c.request(ctx, "GROUPSTATUS", v)
+	if err != nil {
+		return nil, fmt.Errorf("udpapi GroupStatus: %w", err)
+	}
+	if resp.Code != 225 {
+		return nil, fmt.Errorf("udpapi GroupStatus: %w", codeError("GROUPSTATUS", v, resp))
+	}
+	rows := make([]GroupStatusRow, len(resp.Rows))
+	for i, row := range resp.Rows {
+		rows[i], err = decodeGroupStatusRow(row)
+		if err != nil {
+			return nil, fmt.Errorf("udpapi GroupStatus: %w", err)
+		}
+	}
+	return rows, nil
+}
+
+// decodeGroupStatusRow decodes a GROUPSTATUS response row.
+func decodeGroupStatusRow(row []string) (GroupStatusRow, error) {
+	var r GroupStatusRow
+	if n := len(row); n != 7 {
+		return r, fmt.Errorf("decode group status: got unexpected number of fields %d", n)
+	}
+	var state int
+	for _, f := range []struct {
+		p *int
+		s string
+	}{
+		{&r.GID, row[0]},
+		{&state, row[2]},
+		{&r.LastEpisode, row[3]},
+		{&r.Rating, row[4]},
+		{&r.Votes, row[5]},
+	} {
+		var err error
+		*f.p, err = strconv.Atoi(f.s)
+		if err != nil {
+			return r, fmt.Errorf("decode group status: %s", err)
+		}
+	}
+	r.GroupName = row[1]
+	r.State = CompletionState(state)
+	r.EpisodeRange = row[6]
+	return r, nil
+}
@@ -0,0 +1,66 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestDecodeGroupStatusRow(t *testing.T) {
+	t.Parallel()
+	got, err := decodeGroupStatusRow([]string{"7172", "Commie", "3", "12", "850", "12", "1-12"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := GroupStatusRow{
+		GID:          7172,
+		GroupName:    "Commie",
+		State:        StateComplete,
+		LastEpisode:  12,
+		Rating:       850,
+		Votes:        12,
+		EpisodeRange: "1-12",
+	}
+	if got != want {
+		t.Errorf("Got %#v; want %#v", got, want)
+	}
+}
+
+func TestDecodeGroupStatusRow_state(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		code int
+		want CompletionState
+	}{
+		{1, StateOngoing},
+		{2, StateStalled},
+		{3, StateComplete},
+		{4, StateDropped},
+		{5, StateFinished},
+		{6, StateSpecialOnly},
+	}
+	for _, c := range cases {
+		row := []string{"1", "group", strconv.Itoa(c.code), "1", "0", "0", "1"}
+		got, err := decodeGroupStatusRow(row)
+		if err != nil {
+			t.Errorf("State %d: %s", c.code, err)
+			continue
+		}
+		if got.State != c.want {
+			t.Errorf("State %d: got %v; want %v", c.code, got.State, c.want)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"go.felesatra.moe/anidb/udpapi/codes"
+)
+
+// SplitHeaderFields splits the first n space separated data fields
+// from a response header.
+// The rest of the header is the response message, like
+// "LOGIN ACCEPTED".
+// An error is returned if the header has fewer than n fields.
+func SplitHeaderFields(h string, n int) (fields []string, msg string, _ error) {
+	fields = make([]string, 0, n)
+	rest := h
+	for i := 0; i < n; i++ {
+		f, r, _ := strings.Cut(rest, " ")
+		if f == "" {
+			return nil, "", fmt.Errorf("invalid response header %q: want %d fields", h, n)
+		}
+		fields = append(fields, f)
+		rest = r
+	}
+	return fields, rest, nil
+}
+
+// An AuthHeader is the metadata in an AUTH response.
+type AuthHeader struct {
+	SessionKey string
+	// PublicAddr is the client's address as seen by the server.
+	// It is only returned if nat=1 was sent, and is zero otherwise.
+	// Some servers only return the port, in which case the IP is
+	// zero.
+	PublicAddr netip.AddrPort
+	// ImageServer is the image server name.
+	// It is only returned if imgserver=1 was sent.
+	ImageServer string
+	// NewVersionAvailable is set if a new version of the UDP API
+	// is available (code 201).
+	NewVersionAvailable bool
+}
+
+// ParseAuthHeader parses a successful AUTH response.
+func ParseAuthHeader(r Response) (AuthHeader, error) {
+	var h AuthHeader
+	switch r.Code {
+	case codes.LOGIN_ACCEPTED:
+	case codes.LOGIN_ACCEPTED_NEW_VERSION:
+		h.NewVersionAvailable = true
+	default:
+		return h, fmt.Errorf("parse auth header: got bad return code %s", r.Code)
+	}
+	f, msg, err := SplitHeaderFields(r.Header, 1)
+	if err != nil {
+		return h, fmt.Errorf("parse auth header: %s", err)
+	}
+	h.SessionKey = f[0]
+	// Without nat=1, the address is omitted and the message follows
+	// the session key.
+	if a, _, _ := strings.Cut(msg, " "); a != "" {
+		if addr, ok := parseAuthAddr(a); ok {
+			h.PublicAddr = addr
+		}
+	}
+	if len(r.Rows) > 0 && len(r.Rows[0]) > 0 {
+		h.ImageServer = r.Rows[0][0]
+	}
+	return h, nil
+}
+
+// parseAuthAddr parses an AUTH address, either "ip:port" or "port".
+func parseAuthAddr(s string) (netip.AddrPort, bool) {
+	if a, err := netip.ParseAddrPort(s); err == nil {
+		return a, true
+	}
+	port, err := strconv.ParseUint(s, 10, 16)
+	if err != nil {
+		return netip.AddrPort{}, false
+	}
+	return netip.AddrPortFrom(netip.Addr{}, uint16(port)), true
+}
+
+// formatAuthAddr formats an AUTH address like the server.
+func formatAuthAddr(a netip.AddrPort) string {
+	switch {
+	case a.Addr().IsValid():
+		return a.String()
+	case a.Port() != 0:
+		return strconv.Itoa(int(a.Port()))
+	default:
+		return ""
+	}
+}
+
+// An EncryptHeader is the metadata in an ENCRYPT response header.
+type EncryptHeader struct {
+	Salt string
+}
+
+// ParseEncryptHeader parses the header of a successful ENCRYPT
+// response.
+func ParseEncryptHeader(r Response) (EncryptHeader, error) {
+	if r.Code != codes.ENCRYPTION_ENABLED {
+		return EncryptHeader{}, fmt.Errorf("parse encrypt header: got bad return code %s", r.Code)
+	}
+	f, _, err := SplitHeaderFields(r.Header, 1)
+	if err != nil {
+		return EncryptHeader{}, fmt.Errorf("parse encrypt header: %s", err)
+	}
+	return EncryptHeader{Salt: f[0]}, nil
+}
+
+// A NotificationHeader is the metadata in a pushed notification
+// header, like NOTIFICATION - NEW FILE.
+type NotificationHeader struct {
+	// PacketID is the notification packet ID, which should be
+	// acknowledged with PUSHACK.
+	PacketID int
+	// Message is the header message, like
+	// "NOTIFICATION - NEW FILE".
+	Message string
+}
+
+// ParseNotificationHeader parses the header of a pushed
+// notification.
+func ParseNotificationHeader(r Response) (NotificationHeader, error) {
+	f, msg, err := SplitHeaderFields(r.Header, 1)
+	if err != nil {
+		return NotificationHeader{}, fmt.Errorf("parse notification header: %s", err)
+	}
+	id, err := strconv.Atoi(f[0])
+	if err != nil {
+		return NotificationHeader{}, fmt.Errorf("parse notification header: packet id: %s", err)
+	}
+	return NotificationHeader{PacketID: id, Message: msg}, nil
+}
@@ -0,0 +1,172 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"net/netip"
+	"net/url"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSplitHeaderFields(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		h       string
+		n       int
+		fields  []string
+		msg     string
+		wantErr bool
+	}{
+		{h: "key 1.2.3.4:1234 LOGIN ACCEPTED", n: 2, fields: []string{"key", "1.2.3.4:1234"}, msg: "LOGIN ACCEPTED"},
+		{h: "salt ENCRYPTION ENABLED", n: 1, fields: []string{"salt"}, msg: "ENCRYPTION ENABLED"},
+		{h: "PONG", n: 0, fields: []string{}, msg: "PONG"},
+		{h: "salt", n: 1, fields: []string{"salt"}, msg: ""},
+		{h: "LOGIN", n: 2, wantErr: true},
+	}
+	for _, c := range cases {
+		fields, msg, err := SplitHeaderFields(c.h, c.n)
+		if (err != nil) != c.wantErr {
+			t.Errorf("SplitHeaderFields(%q, %d): Got error %v; want error %v", c.h, c.n, err, c.wantErr)
+			continue
+		}
+		if c.wantErr {
+			continue
+		}
+		if !reflect.DeepEqual(fields, c.fields) || msg != c.msg {
+			t.Errorf("SplitHeaderFields(%q, %d) = %q, %q; want %q, %q", c.h, c.n, fields, msg, c.fields, c.msg)
+		}
+	}
+}
+
+func TestParseAuthHeader(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		desc string
+		r    Response
+		want AuthHeader
+	}{
+		{
+			desc: "new version",
+			r:    Response{Code: 201, Header: "key 1.2.3.4:1234 LOGIN ACCEPTED - NEW VERSION AVAILABLE"},
+			want: AuthHeader{SessionKey: "key", PublicAddr: netip.MustParseAddrPort("1.2.3.4:1234"), NewVersionAvailable: true},
+		},
+		{
+			desc: "no nat",
+			r:    Response{Code: 200, Header: "key LOGIN ACCEPTED"},
+			want: AuthHeader{SessionKey: "key"},
+		},
+		{
+			desc: "port only",
+			r:    Response{Code: 200, Header: "key 1234 LOGIN ACCEPTED"},
+			want: AuthHeader{SessionKey: "key", PublicAddr: netip.AddrPortFrom(netip.Addr{}, 1234)},
+		},
+		{
+			desc: "image server",
+			r: Response{
+				Code:   200,
+				Header: "key 1.2.3.4:1234 LOGIN ACCEPTED",
+				Rows:   [][]string{{"img7.anidb.net"}},
+			},
+			want: AuthHeader{SessionKey: "key", PublicAddr: netip.MustParseAddrPort("1.2.3.4:1234"), ImageServer: "img7.anidb.net"},
+		},
+	}
+	for _, c := range cases {
+		got, err := ParseAuthHeader(c.r)
+		if err != nil {
+			t.Errorf("%s: %s", c.desc, err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("%s: Got %+v; want %+v", c.desc, got, c.want)
+		}
+	}
+	if _, err := ParseAuthHeader(Response{Code: 500, Header: "LOGIN FAILED"}); err == nil {
+		t.Errorf("Expected error")
+	}
+}
+
+func TestParseNotificationHeader(t *testing.T) {
+	t.Parallel()
+	got, err := ParseNotificationHeader(Response{Code: 720, Header: "1234 NOTIFICATION - NEW FILE"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := NotificationHeader{PacketID: 1234, Message: "NOTIFICATION - NEW FILE"}
+	if got != want {
+		t.Errorf("Got %+v; want %+v", got, want)
+	}
+}
+
+func TestClient_AuthInfo(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		if args.Get("nat") != "" || args.Get("imgserver") != "1" {
+			return "505 ILLEGAL INPUT OR ACCESS DENIED"
+		}
+		return "200 key LOGIN ACCEPTED\nimg7.anidb.net"
+	})
+	s.client.DisableNAT = true
+	s.client.ImageServer = true
+	got, err := s.client.AuthInfo(ctx, UserInfo{UserName: "ionasal", UserPassword: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := AuthResult{AuthHeader: AuthHeader{SessionKey: "key", ImageServer: "img7.anidb.net"}}
+	if got != want {
+		t.Errorf("Got %+v; want %+v", got, want)
+	}
+	if got := s.client.sessionKey.get(); got != "key" {
+		t.Errorf("Got session key %q; want %q", got, "key")
+	}
+}
+
+func TestClient_AuthInfo_nat(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		desc string
+		port func(local string) string
+		want bool
+	}{
+		{desc: "same port", port: func(local string) string { return local }, want: false},
+		{desc: "different port", port: func(string) string { return "1" }, want: true},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.desc, func(t *testing.T) {
+			t.Parallel()
+			ctx := testContext(t, time.Second)
+			// The local port is only known after the server starts.
+			var local atomic.Value
+			s := newFakeServer(t, func(cmd string, args url.Values) string {
+				return "201 key 1.2.3.4:" + c.port(local.Load().(string)) + " LOGIN ACCEPTED - NEW VERSION AVAILABLE"
+			})
+			local.Store(s.client.LocalPort())
+			got, err := s.client.AuthInfo(ctx, UserInfo{UserName: "ionasal", UserPassword: "pass"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.NATDetected != c.want {
+				t.Errorf("Got NATDetected %v; want %v", got.NATDetected, c.want)
+			}
+			if !got.NewVersionAvailable {
+				t.Errorf("Got NewVersionAvailable false; want true")
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"context"
+	"net/url"
+	"slices"
+)
+
+// A RequestFunc performs an AniDB UDP API request.
+// See [Mux.Request].
+type RequestFunc func(ctx context.Context, cmd string, args url.Values) (Response, error)
+
+// An Interceptor wraps a [RequestFunc] to add behavior to requests,
+// such as logging, metrics, caching or fault injection.
+//
+// An Interceptor returns a RequestFunc that should call next to
+// continue the request, though it may instead return a response or
+// error directly.
+// The returned RequestFunc may be called concurrently.
+type Interceptor func(next RequestFunc) RequestFunc
+
+// chain wraps f with the interceptors.
+// The first interceptor is outermost.
+func chain(f RequestFunc, is []Interceptor) RequestFunc {
+	for i := len(is) - 1; i >= 0; i-- {
+		f = is[i](f)
+	}
+	return f
+}
+
+// Use adds interceptors that wrap each request sent by the Mux.
+// Interceptors added earlier are outermost.
+//
+// Mux interceptors see each request attempt made by a [Client],
+// after rate limiting.
+func (m *Mux) Use(is ...Interceptor) {
+	m.interceptors.update(func(v *[]Interceptor) {
+		*v = append(slices.Clip(*v), is...)
+	})
+}
+
+// Use adds interceptors that wrap each request made by the Client.
+// Interceptors added earlier are outermost.
+//
+// Client interceptors see each request once, before caching,
+// re-authentication, retries and rate limiting, which the Client
+// implements as interceptors of its own.
+// To see each attempt sent to the server, use [Client.UseMux].
+func (c *Client) Use(is ...Interceptor) {
+	c.interceptors.update(func(v *[]Interceptor) {
+		*v = append(slices.Clip(*v), is...)
+	})
+}
+
+// UseMux adds interceptors to the underlying [Mux].
+// See [Mux.Use].
+func (c *Client) UseMux(is ...Interceptor) {
+	c.m.Use(is...)
+}
@@ -0,0 +1,86 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"context"
+	"net/url"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"go.felesatra.moe/anidb/udpapi/codes"
+)
+
+func TestClient_Use(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	var n int
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		n++
+		if n < 2 {
+			return "602 SERVER BUSY"
+		}
+		return "300 PONG"
+	})
+	c := s.client
+	c.RetryPolicy = RetryPolicy{
+		MaxAttempts: 2,
+		Base:        time.Millisecond,
+		Codes:       []codes.ReturnCode{codes.SERVER_BUSY},
+	}
+	var mu sync.Mutex
+	var got []string
+	record := func(name string) Interceptor {
+		return func(next RequestFunc) RequestFunc {
+			return func(ctx context.Context, cmd string, args url.Values) (Response, error) {
+				mu.Lock()
+				got = append(got, name+" "+cmd)
+				mu.Unlock()
+				return next(ctx, cmd, args)
+			}
+		}
+	}
+	c.Use(record("outer"), record("inner"))
+	c.UseMux(record("mux"))
+	if err := c.PingSimple(ctx); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"outer PING", "inner PING", "mux PING", "mux PING"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+}
+
+func TestClient_Use_shortCircuit(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "300 PONG"
+	})
+	c := s.client
+	c.Use(func(next RequestFunc) RequestFunc {
+		return func(ctx context.Context, cmd string, args url.Values) (Response, error) {
+			return Response{Code: codes.PONG, Header: "PONG"}, nil
+		}
+	})
+	if err := c.PingSimple(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if reqs := s.requests(); len(reqs) != 0 {
+		t.Errorf("Got %d requests; want 0", len(reqs))
+	}
+}
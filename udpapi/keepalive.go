@@ -0,0 +1,268 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	keepAliveMin  = 30 * time.Second
+	keepAliveMax  = 5 * time.Minute
+	keepAliveStep = 30 * time.Second
+	// keepAliveRetry is the first delay for retrying failed pings.
+	keepAliveRetry = 5 * time.Second
+)
+
+// A KeepAliveConfig configures the ping intervals of a [KeepAlive].
+// Different NATs, such as carrier-grade NATs, may need different
+// tuning.
+// The zero value uses the default intervals.
+type KeepAliveConfig struct {
+	// Initial is the initial ping interval.
+	// If zero, Min is used.
+	Initial time.Duration
+	// Min is the minimum ping interval.
+	// If zero, 30 seconds is used.
+	Min time.Duration
+	// Max is the maximum ping interval.
+	// If zero, 5 minutes is used.
+	Max time.Duration
+	// Step is how much the ping interval is increased while
+	// probing for the NAT timeout, and decreased after hitting it.
+	// If zero, 30 seconds is used.
+	Step time.Duration
+	// Probe sends a ping and returns the client's port as seen by
+	// the server.
+	// If nil, [Client.Ping] is used.
+	Probe func(ctx context.Context, c *Client) (port string, _ error)
+}
+
+// withDefaults returns the config with defaults filled in.
+func (cfg KeepAliveConfig) withDefaults() KeepAliveConfig {
+	if cfg.Min == 0 {
+		cfg.Min = keepAliveMin
+	}
+	if cfg.Max == 0 {
+		cfg.Max = keepAliveMax
+	}
+	if cfg.Max < cfg.Min {
+		cfg.Max = cfg.Min
+	}
+	if cfg.Step == 0 {
+		cfg.Step = keepAliveStep
+	}
+	if cfg.Initial == 0 {
+		cfg.Initial = cfg.Min
+	}
+	cfg.Initial = min(max(cfg.Initial, cfg.Min), cfg.Max)
+	if cfg.Probe == nil {
+		cfg.Probe = func(ctx context.Context, c *Client) (string, error) {
+			return c.Ping(ctx)
+		}
+	}
+	return cfg
+}
+
+// Start starts a KeepAlive for the Client with the config.
+// You must call Stop after use.
+func (cfg KeepAliveConfig) Start(c *Client) *KeepAlive {
+	ctx, cf := context.WithCancel(context.Background())
+	k := newKeepAlive(c, cfg)
+	k.stop = cf
+	k.wg.Add(1)
+	go func() {
+		defer k.wg.Done()
+		k.run(ctx)
+	}()
+	return k
+}
+
+// A KeepAlive keeps the NAT mapping for a Client alive by periodically
+// sending PING.
+//
+// The ping interval starts low and is increased until the NAT timeout
+// is hit, which is detected by a change in the port returned by PING.
+// After that, the interval is decreased back below the NAT timeout.
+//
+// AniDB ties sessions to the client address, so a port change also
+// loses the session.
+// If [Client.AutoReauth] is set, the KeepAlive re-authenticates;
+// otherwise it clears the session key, so commands fail instead of
+// being sent with the lost session.
+type KeepAlive struct {
+	c     *Client
+	cfg   KeepAliveConfig
+	state syncVar[KeepAliveState]
+	stop  context.CancelFunc
+	wg    sync.WaitGroup
+}
+
+// A KeepAliveState is a snapshot of the state of a [KeepAlive].
+type KeepAliveState struct {
+	// Interval is the current ping interval.
+	Interval time.Duration
+	// Port is the port returned by the last successful PING.
+	Port string
+	// TimeoutHit is set once the NAT timeout has been hit.
+	// Before this, the KeepAlive is still probing for the timeout.
+	TimeoutHit bool
+	// LastPing is the time of the last PING.
+	LastPing time.Time
+}
+
+// StartKeepAlive starts a KeepAlive for the Client, configured by
+// [Client.KeepAliveConfig].
+// You must call Stop after use.
+func StartKeepAlive(c *Client) *KeepAlive {
+	return c.KeepAliveConfig.Start(c)
+}
+
+func newKeepAlive(c *Client, cfg KeepAliveConfig) *KeepAlive {
+	cfg = cfg.withDefaults()
+	k := &KeepAlive{c: c, cfg: cfg}
+	k.state.set(KeepAliveState{Interval: cfg.Initial})
+	return k
+}
+
+// State returns the current state of the KeepAlive.
+func (k *KeepAlive) State() KeepAliveState {
+	return k.state.get()
+}
+
+// Stop stops the KeepAlive.
+func (k *KeepAlive) Stop() {
+	k.stop()
+	k.wg.Wait()
+}
+
+func (k *KeepAlive) run(ctx context.Context) {
+	// Failed pings are retried sooner than the interval.
+	b := backoff{
+		base:   min(keepAliveRetry, k.cfg.Min),
+		cap:    k.cfg.Min,
+		jitter: 0.2,
+	}
+	d := k.State().Interval
+	for {
+		t := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return
+		case <-t.C:
+		}
+		if err := k.tick(ctx); err != nil {
+			d, _ = b.next()
+			continue
+		}
+		b.reset()
+		d = k.State().Interval
+	}
+}
+
+// tick sends one PING and adjusts the interval.
+func (k *KeepAlive) tick(ctx context.Context) error {
+	port, err := k.cfg.Probe(ctx, k.c)
+	s := k.State()
+	s.LastPing = time.Now()
+	if err != nil {
+		k.c.logger.Error("Error pinging for keepalive", "error", err)
+		k.state.set(s)
+		return err
+	}
+	old := s.Port
+	changed := s.observe(port, k.cfg)
+	k.state.set(s)
+	if changed {
+		k.c.logger.Info("NAT port changed", "old", old, "new", port)
+		k.c.natPortChanged(ctx)
+	}
+	return nil
+}
+
+// natPortChanged re-authenticates, or clears the session if
+// AutoReauth is not set, as the session was lost with the old port.
+func (c *Client) natPortChanged(ctx context.Context) {
+	key := c.sessionKey.get()
+	if key == "" {
+		return
+	}
+	if !c.AutoReauth {
+		c.reauthMu.Lock()
+		defer c.reauthMu.Unlock()
+		if c.sessionKey.get() == key {
+			c.logger.Warn("Session lost after NAT port change")
+			c.sessionKey.set("")
+		}
+		return
+	}
+	if err := c.reauthStale(ctx, key); err != nil {
+		c.logger.Error("Error re-authenticating after NAT port change", "error", err)
+	}
+}
+
+// observe updates the state with the port returned by a PING and
+// adjusts the interval.
+// cfg must have defaults filled in.
+// It returns true if the port changed, meaning the NAT timeout was
+// hit.
+func (s *KeepAliveState) observe(port string, cfg KeepAliveConfig) bool {
+	changed := s.Port != "" && port != s.Port
+	switch {
+	case changed:
+		s.TimeoutHit = true
+		s.Interval -= cfg.Step
+	case !s.TimeoutHit:
+		s.Interval += cfg.Step
+	}
+	s.Interval = min(max(s.Interval, cfg.Min), cfg.Max)
+	s.Port = port
+	return changed
+}
+
+// KeepAliveState returns the state of the KeepAlive started by
+// [Client.AutoKeepAlive].
+// ok is false if no KeepAlive is running.
+func (c *Client) KeepAliveState() (s KeepAliveState, ok bool) {
+	k := c.keepAlive.get()
+	if k == nil {
+		return KeepAliveState{}, false
+	}
+	return k.State(), true
+}
+
+// startKeepAlive starts a KeepAlive for the client if one is not
+// already running.
+func (c *Client) startKeepAlive() {
+	c.keepAlive.update(func(k **KeepAlive) {
+		if *k == nil {
+			*k = StartKeepAlive(c)
+		}
+	})
+}
+
+// stopKeepAlive stops the KeepAlive for the client if one is running.
+func (c *Client) stopKeepAlive() {
+	var k *KeepAlive
+	c.keepAlive.update(func(p **KeepAlive) {
+		k, *p = *p, nil
+	})
+	if k != nil {
+		k.Stop()
+	}
+}
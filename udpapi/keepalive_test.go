@@ -0,0 +1,222 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestKeepAlive_tick(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	ports := []string{"1000", "1000", "1000", "2000", "2000"}
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		p := ports[0]
+		ports = ports[1:]
+		return "300 PONG\n" + p
+	})
+	k := newKeepAlive(s.client, KeepAliveConfig{})
+	want := []KeepAliveState{
+		{Interval: keepAliveMin + keepAliveStep, Port: "1000"},
+		{Interval: keepAliveMin + 2*keepAliveStep, Port: "1000"},
+		{Interval: keepAliveMin + 3*keepAliveStep, Port: "1000"},
+		{Interval: keepAliveMin + 2*keepAliveStep, Port: "2000", TimeoutHit: true},
+		{Interval: keepAliveMin + 2*keepAliveStep, Port: "2000", TimeoutHit: true},
+	}
+	for i, w := range want {
+		before := time.Now()
+		if err := k.tick(ctx); err != nil {
+			t.Fatal(err)
+		}
+		got := k.State()
+		if got.LastPing.Before(before) {
+			t.Errorf("Tick %d: got LastPing %v; want after %v", i, got.LastPing, before)
+		}
+		got.LastPing = time.Time{}
+		if got != w {
+			t.Errorf("Tick %d: got %+v; want %+v", i, got, w)
+		}
+	}
+}
+
+func TestKeepAlive_tick_portChanged(t *testing.T) {
+	t.Parallel()
+	for _, reauth := range []bool{false, true} {
+		reauth := reauth
+		t.Run(fmt.Sprintf("AutoReauth=%t", reauth), func(t *testing.T) {
+			t.Parallel()
+			ctx := testContext(t, time.Second)
+			ports := []string{"1000", "2000"}
+			var auths int
+			s := newFakeServer(t, func(cmd string, args url.Values) string {
+				switch cmd {
+				case "AUTH":
+					auths++
+					return fmt.Sprintf("200 key%d 1234 LOGIN ACCEPTED", auths)
+				case "PING":
+					p := ports[0]
+					ports = ports[1:]
+					return "300 PONG\n" + p
+				default:
+					return "598 UNKNOWN COMMAND"
+				}
+			})
+			c := s.client
+			c.AutoReauth = reauth
+			if _, err := c.Auth(ctx, UserInfo{UserName: "ionasal", UserPassword: "pass"}); err != nil {
+				t.Fatal(err)
+			}
+			k := newKeepAlive(c, KeepAliveConfig{})
+			for i := 0; i < 2; i++ {
+				if err := k.tick(ctx); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want := ""
+			if reauth {
+				want = "key2"
+			}
+			if got := c.sessionKey.get(); got != want {
+				t.Errorf("Got session key %q; want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestKeepAlive_tick_config(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	ports := []string{"1000", "1000", "1000", "2000"}
+	var cmds []string
+	cfg := KeepAliveConfig{
+		Initial: 20 * time.Second,
+		Min:     10 * time.Second,
+		Max:     30 * time.Second,
+		Step:    10 * time.Second,
+		Probe: func(ctx context.Context, c *Client) (string, error) {
+			cmds = append(cmds, "probe")
+			p := ports[0]
+			ports = ports[1:]
+			return p, nil
+		},
+	}
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		t.Errorf("Got unexpected request %s", cmd)
+		return ""
+	})
+	k := newKeepAlive(s.client, cfg)
+	if got := k.State().Interval; got != 20*time.Second {
+		t.Errorf("Got initial interval %v; want %v", got, 20*time.Second)
+	}
+	want := []time.Duration{30 * time.Second, 30 * time.Second, 30 * time.Second, 20 * time.Second}
+	for i, w := range want {
+		if err := k.tick(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if got := k.State().Interval; got != w {
+			t.Errorf("Tick %d: got interval %v; want %v", i, got, w)
+		}
+	}
+	if len(cmds) != 4 {
+		t.Errorf("Got %d probes; want 4", len(cmds))
+	}
+}
+
+func TestKeepAliveConfig_withDefaults(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		desc string
+		cfg  KeepAliveConfig
+		want KeepAliveConfig
+	}{
+		{
+			desc: "zero",
+			want: KeepAliveConfig{Initial: keepAliveMin, Min: keepAliveMin, Max: keepAliveMax, Step: keepAliveStep},
+		},
+		{
+			desc: "initial clamped",
+			cfg:  KeepAliveConfig{Initial: time.Hour},
+			want: KeepAliveConfig{Initial: keepAliveMax, Min: keepAliveMin, Max: keepAliveMax, Step: keepAliveStep},
+		},
+		{
+			desc: "max below min",
+			cfg:  KeepAliveConfig{Min: 10 * time.Minute},
+			want: KeepAliveConfig{Initial: 10 * time.Minute, Min: 10 * time.Minute, Max: 10 * time.Minute, Step: keepAliveStep},
+		},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.desc, func(t *testing.T) {
+			t.Parallel()
+			got := c.cfg.withDefaults()
+			if got.Probe == nil {
+				t.Errorf("Got nil Probe")
+			}
+			got.Probe = nil
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("Got %+v; want %+v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestKeepAlive_stop(t *testing.T) {
+	t.Parallel()
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "300 PONG\n1000"
+	})
+	k := StartKeepAlive(s.client)
+	k.Stop()
+	if got := k.State(); got.Interval != keepAliveMin {
+		t.Errorf("Got interval %v; want %v", got.Interval, keepAliveMin)
+	}
+}
+
+func TestClient_AutoKeepAlive(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		switch cmd {
+		case "AUTH":
+			return "200 key 1234 LOGIN ACCEPTED"
+		case "LOGOUT":
+			return "203 LOGGED OUT"
+		default:
+			return "598 UNKNOWN COMMAND"
+		}
+	})
+	c := s.client
+	c.AutoKeepAlive = true
+	if _, ok := c.KeepAliveState(); ok {
+		t.Errorf("Got KeepAlive running before AUTH")
+	}
+	if _, err := c.Auth(ctx, UserInfo{UserName: "ionasal", UserPassword: "pass"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.KeepAliveState(); !ok {
+		t.Errorf("Got no KeepAlive running after AUTH")
+	}
+	if err := c.Logout(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.KeepAliveState(); ok {
+		t.Errorf("Got KeepAlive running after LOGOUT")
+	}
+}
@@ -16,11 +16,20 @@ package udpapi
 
 import (
 	"context"
+	"errors"
+	"sync"
+	"time"
 
 	"golang.org/x/time/rate"
 )
 
-// A Limiter is a rate limiter that complies with AniDB UDP API flood
+// A Limiter implements rate limiting.
+// [golang.org/x/time/rate.Limiter] is a valid implementation.
+type Limiter interface {
+	Wait(context.Context) error
+}
+
+// A limiter is a rate limiter that complies with AniDB UDP API flood
 // prevention recommendations.
 //
 // It functions similarly to [golang.org/x/time/rate.Limiter], except
@@ -48,3 +57,37 @@ func (l limiter) Wait(ctx context.Context) error {
 	}
 	return nil
 }
+
+// ErrRequestBudgetExceeded is returned for requests beyond
+// [Client.MaxRequests].
+var ErrRequestBudgetExceeded = errors.New("request budget exceeded")
+
+// A requestBudget counts requests against a maximum per window.
+// This is concurrent safe.
+type requestBudget struct {
+	mu    sync.Mutex
+	start time.Time
+	n     int
+}
+
+// take takes one request from the budget.
+// Returns false if the budget is exceeded.
+// If limit is zero, there is no maximum.
+// If window is zero, the window never resets.
+func (b *requestBudget) take(limit int, window time.Duration) bool {
+	if limit <= 0 {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	if b.start.IsZero() || (window > 0 && now.Sub(b.start) >= window) {
+		b.start = now
+		b.n = 0
+	}
+	if b.n >= limit {
+		return false
+	}
+	b.n++
+	return true
+}
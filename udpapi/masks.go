@@ -32,12 +32,43 @@ type FileFmask [5]byte
 
 // FileFmaskFields describes the bit fields in a FILE fmask.
 var FileFmaskFields = map[string]bitSpec{
-	"aid":   {0, 6, "int4", "aid"},
-	"eid":   {0, 5, "int4", "eid"},
-	"gid":   {0, 4, "int4", "gid"},
-	"state": {0, 0, "int2", "state"},
+	"aid":           {0, 6, "int4", "aid"},
+	"eid":           {0, 5, "int4", "eid"},
+	"gid":           {0, 4, "int4", "gid"},
+	"mylist id":     {0, 3, "int4", "mylist id"},
+	"is deprecated": {0, 1, "int2", "is deprecated"},
+	"state":         {0, 0, "int2", "state"},
 
-	"anidb file name": {3, 0, "str", "anidb file name"},
+	"size":               {1, 7, "int8", "size"},
+	"ed2k":               {1, 6, "str", "ed2k"},
+	"md5":                {1, 5, "str", "md5"},
+	"sha1":               {1, 4, "str", "sha1"},
+	"crc32":              {1, 3, "str", "crc32"},
+	"video colour depth": {1, 1, "str", "video colour depth"},
+
+	"quality":            {2, 7, "str", "quality"},
+	"source":             {2, 6, "str", "source"},
+	"audio codec list":   {2, 5, "str", "audio codec list"},
+	"audio bitrate list": {2, 4, "int4", "audio bitrate list"},
+	"video codec":        {2, 3, "str", "video codec"},
+	"video bitrate":      {2, 2, "int4", "video bitrate"},
+	"video resolution":   {2, 1, "str", "video resolution"},
+	"file type":          {2, 0, "str", "file type"},
+
+	"dub language":      {3, 7, "str", "dub language"},
+	"sub language":      {3, 6, "str", "sub language"},
+	"length in seconds": {3, 5, "int4", "length in seconds"},
+	"description":       {3, 4, "str", "description"},
+	"aired date":        {3, 3, "int4", "aired date"},
+	"anidb file name":   {3, 0, "str", "anidb file name"},
+
+	"mylist state":     {4, 7, "int4", "mylist state"},
+	"mylist filestate": {4, 6, "int4", "mylist filestate"},
+	"mylist viewed":    {4, 5, "int4", "mylist viewed"},
+	"mylist viewdate":  {4, 4, "int4", "mylist viewdate"},
+	"mylist storage":   {4, 3, "str", "mylist storage"},
+	"mylist source":    {4, 2, "str", "mylist source"},
+	"mylist other":     {4, 1, "str", "mylist other"},
 }
 
 // Set sets a bit in the mask.
@@ -53,8 +84,22 @@ type FileAmask [4]byte
 
 // FileAmaskFields describes the bit fields in a FILE amask.
 var FileAmaskFields = map[string]bitSpec{
-	"epno":    {2, 7, "str", "epno"},
-	"ep name": {2, 6, "str", "ep name"},
+	"anime total episodes":   {0, 7, "int4", "anime total episodes"},
+	"highest episode number": {0, 6, "int4", "highest episode number"},
+	"year":                   {0, 5, "str", "year"},
+	"type":                   {0, 4, "str", "type"},
+
+	"romaji name":  {1, 7, "str", "romaji name"},
+	"kanji name":   {1, 6, "str", "kanji name"},
+	"english name": {1, 5, "str", "english name"},
+
+	"epno":           {2, 7, "str", "epno"},
+	"ep name":        {2, 6, "str", "ep name"},
+	"ep romaji name": {2, 5, "str", "ep romaji name"},
+	"ep kanji name":  {2, 4, "str", "ep kanji name"},
+
+	"group name":       {3, 7, "str", "group name"},
+	"group short name": {3, 6, "str", "group short name"},
 }
 
 // Set sets a bit in the mask.
@@ -65,6 +110,52 @@ func (m *FileAmask) Set(f ...string) {
 	}
 }
 
+// An AnimeAmask is a mask for the ANIME command amask field.
+type AnimeAmask [7]byte
+
+// AnimeAmaskFields describes the bit fields in an ANIME amask.
+var AnimeAmaskFields = map[string]bitSpec{
+	"aid":              {0, 7, "int4", "aid"},
+	"dateflags":        {0, 6, "int", "dateflags"},
+	"year":             {0, 5, "str", "year"},
+	"type":             {0, 4, "str", "type"},
+	"related aid list": {0, 3, "str", "related aid list"},
+	"related aid type": {0, 2, "str", "related aid type"},
+
+	"romaji name":     {1, 7, "str", "romaji name"},
+	"kanji name":      {1, 6, "str", "kanji name"},
+	"english name":    {1, 5, "str", "english name"},
+	"other name":      {1, 4, "str", "other name"},
+	"short name list": {1, 3, "str", "short name list"},
+	"synonym list":    {1, 2, "str", "synonym list"},
+
+	"episodes":               {2, 7, "int4", "episodes"},
+	"highest episode number": {2, 6, "int4", "highest episode number"},
+	"special ep count":       {2, 5, "int4", "special ep count"},
+	"air date":               {2, 4, "int4", "air date"},
+	"end date":               {2, 3, "int4", "end date"},
+	"url":                    {2, 2, "str", "url"},
+	"picname":                {2, 1, "str", "picname"},
+
+	"rating":                {3, 7, "int4", "rating"},
+	"vote count":            {3, 6, "int4", "vote count"},
+	"temp rating":           {3, 5, "int4", "temp rating"},
+	"temp vote count":       {3, 4, "int4", "temp vote count"},
+	"average review rating": {3, 3, "int4", "average review rating"},
+	"review count":          {3, 2, "int4", "review count"},
+	"is 18+ restricted":     {3, 0, "bool", "is 18+ restricted"},
+
+	"character id list": {5, 7, "str", "character id list"},
+}
+
+// Set sets a bit in the mask.
+// See [AnimeAmaskFields] for the field names.
+func (m *AnimeAmask) Set(f ...string) {
+	for _, f := range f {
+		setMaskBit(m[:], AnimeAmaskFields, f)
+	}
+}
+
 func setMaskBit(b []byte, m map[string]bitSpec, name string) {
 	s, ok := m[name]
 	if !ok {
@@ -76,7 +167,7 @@ func setMaskBit(b []byte, m map[string]bitSpec, name string) {
 func formatMask(m []byte) string {
 	var sb strings.Builder
 	for _, b := range m {
-		fmt.Fprintf(&sb, "%x", b)
+		fmt.Fprintf(&sb, "%02x", b)
 	}
 	return sb.String()
 }
@@ -26,6 +26,16 @@ func TestFileFmask_Test(t *testing.T) {
 	}
 }
 
+func TestFormatMask(t *testing.T) {
+	t.Parallel()
+	var m FileFmask
+	m.Set("aid")
+	const want = "4000000000"
+	if got := formatMask(m[:]); got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+}
+
 func TestFileAmask_Test(t *testing.T) {
 	t.Parallel()
 	var m FileAmask
@@ -0,0 +1,74 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"go.felesatra.moe/anidb/udpapi/codes"
+)
+
+// Length limits for SENDMSG, in characters.
+const (
+	MaxMessageTitle = 50
+	MaxMessageBody  = 900
+)
+
+// ErrMessageTooLong is returned by [Client.SendMessage] when the title
+// or body exceeds its length limit.
+var ErrMessageTooLong = errors.New("message too long")
+
+// SendMessage calls the SENDMSG command to send a message to a user.
+// The title and body must not be empty or exceed [MaxMessageTitle]
+// and [MaxMessageBody].
+// The returned error wraps a [codes.ReturnCode] if applicable,
+// such as NO_SUCH_USER.
+func (c *Client) SendMessage(ctx context.Context, to, title, body string) error {
+	if err := checkMessage(title, body); err != nil {
+		return fmt.Errorf("udpapi SendMessage: %w", err)
+	}
+	v, err := c.sessionValues(ctx)
+	if err != nil {
+		return fmt.Errorf("udpapi SendMessage: %w", err)
+	}
+	v.Set("to", to)
+	v.Set("title", title)
+	v.Set("body", body)
+	resp, err := c.request(ctx, "SENDMSG", v)
+	if err != nil {
+		return fmt.Errorf("udpapi SendMessage: %w", err)
+	}
+	if resp.Code != codes.SENDMESSAGE_SUCCESSFUL {
+		return fmt.Errorf("udpapi SendMessage: %w", codeError("SENDMSG", v, resp))
+	}
+	return nil
+}
+
+// checkMessage checks the title and body for SENDMSG.
+func checkMessage(title, body string) error {
+	if title == "" || body == "" {
+		return errors.New("empty message title or body")
+	}
+	if n := utf8.RuneCountInString(title); n > MaxMessageTitle {
+		return fmt.Errorf("title has %d characters (max %d): %w", n, MaxMessageTitle, ErrMessageTooLong)
+	}
+	if n := utf8.RuneCountInString(body); n > MaxMessageBody {
+		return fmt.Errorf("body has %d characters (max %d): %w", n, MaxMessageBody, ErrMessageTooLong)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"go.felesatra.moe/anidb/udpapi/codes"
+)
+
+func TestClient_SendMessage(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "294 SENDMSG SUCCESSFUL"
+	})
+	s.client.sessionKey.set("key")
+	if err := s.client.SendMessage(ctx, "ionasal", "hello", "world"); err != nil {
+		t.Fatal(err)
+	}
+	r := s.requests()[0]
+	if r.cmd != "SENDMSG" || r.args.Get("to") != "ionasal" || r.args.Get("title") != "hello" || r.args.Get("body") != "world" {
+		t.Errorf("Got request %v", r)
+	}
+}
+
+func TestClient_SendMessage_noSuchUser(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "394 NO SUCH USER"
+	})
+	s.client.sessionKey.set("key")
+	err := s.client.SendMessage(ctx, "nobody", "hello", "world")
+	if !errors.Is(err, codes.NO_SUCH_USER) {
+		t.Errorf("Got error %v; want %v", err, codes.NO_SUCH_USER)
+	}
+}
+
+func TestCheckMessage(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		desc        string
+		title, body string
+		wantErr     bool
+	}{
+		{desc: "ok", title: "hello", body: "world"},
+		{desc: "max length", title: strings.Repeat("あ", MaxMessageTitle), body: strings.Repeat("a", MaxMessageBody)},
+		{desc: "empty", title: "", body: "world", wantErr: true},
+		{desc: "long title", title: strings.Repeat("a", MaxMessageTitle+1), body: "world", wantErr: true},
+		{desc: "long body", title: "hello", body: strings.Repeat("a", MaxMessageBody+1), wantErr: true},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.desc, func(t *testing.T) {
+			t.Parallel()
+			err := checkMessage(c.title, c.body)
+			if (err != nil) != c.wantErr {
+				t.Errorf("Got error %v; want error %v", err, c.wantErr)
+			}
+		})
+	}
+}
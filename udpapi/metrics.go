@@ -0,0 +1,109 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"fmt"
+	"time"
+
+	"go.felesatra.moe/anidb/udpapi/codes"
+)
+
+// Metrics receives metrics from a [Mux] and [Client], for exporting
+// to a monitoring system such as Prometheus.
+// The methods may be called concurrently and should be fast.
+//
+// Suggested mappings are given for each method.
+type Metrics interface {
+	// ObserveRequest is called after a request completes or fails.
+	// The code is zero if no response was received.
+	// Map to a counter by command and code, and a latency histogram.
+	ObserveRequest(cmd string, code codes.ReturnCode, err error, d time.Duration)
+	// AddInFlight is called with 1 when a request is sent and -1
+	// when it completes.
+	// Map to a gauge of in-flight requests.
+	AddInFlight(delta int)
+	// PacketDropped is called when a received packet is dropped.
+	// Map to a counter by reason.
+	PacketDropped(r DropReason)
+	// ObserveLimiterWait is called with the time a [Client] request
+	// waited for the rate limiter.
+	// Map to a histogram.
+	ObserveLimiterWait(d time.Duration)
+	// RequestRetried is called when a [Client] retries a request.
+	// Map to a counter by command.
+	RequestRetried(cmd string)
+}
+
+// A DropReason is the reason a received packet was dropped.
+type DropReason int
+
+const (
+	// DropUnknownTag means the packet was not for a pending
+	// request, such as a late response after a timeout.
+	DropUnknownTag DropReason = iota
+	// DropDecrypt means the packet could not be decrypted.
+	DropDecrypt
+	// DropDecompress means the packet could not be decompressed.
+	DropDecompress
+	// DropDuplicate means the packet was a duplicate of an already
+	// received response.
+	DropDuplicate
+)
+
+func (r DropReason) String() string {
+	switch r {
+	case DropUnknownTag:
+		return "unknown tag"
+	case DropDecrypt:
+		return "decrypt"
+	case DropDecompress:
+		return "decompress"
+	case DropDuplicate:
+		return "duplicate"
+	default:
+		return fmt.Sprintf("DropReason(%d)", int(r))
+	}
+}
+
+// nopMetrics is the default Metrics, which does nothing.
+type nopMetrics struct{}
+
+func (nopMetrics) ObserveRequest(string, codes.ReturnCode, error, time.Duration) {}
+func (nopMetrics) AddInFlight(int)                                               {}
+func (nopMetrics) PacketDropped(DropReason)                                      {}
+func (nopMetrics) ObserveLimiterWait(time.Duration)                              {}
+func (nopMetrics) RequestRetried(string)                                         {}
+
+// SetMetrics sets the metrics receiver.
+// Set to nil to disable.
+func (m *Mux) SetMetrics(mt Metrics) {
+	m.metrics.set(mt)
+}
+
+// getMetrics returns the metrics receiver, which is never nil.
+func (m *Mux) getMetrics() Metrics {
+	if mt := m.metrics.get(); mt != nil {
+		return mt
+	}
+	return nopMetrics{}
+}
+
+// SetMetrics sets the metrics receiver for the client and its
+// underlying [Mux].
+// Set to nil to disable.
+func (c *Client) SetMetrics(mt Metrics) {
+	c.m.SetMetrics(mt)
+}
@@ -0,0 +1,119 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"go.felesatra.moe/anidb/udpapi/codes"
+)
+
+// testMetrics records metrics for tests.
+type testMetrics struct {
+	mu          sync.Mutex
+	requests    map[string]int
+	inFlight    int
+	maxInFlight int
+	dropped     map[DropReason]int
+	retries     int
+	waits       int
+}
+
+func newTestMetrics() *testMetrics {
+	return &testMetrics{
+		requests: make(map[string]int),
+		dropped:  make(map[DropReason]int),
+	}
+}
+
+func (m *testMetrics) ObserveRequest(cmd string, code codes.ReturnCode, err error, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests[cmd+" "+code.String()]++
+}
+
+func (m *testMetrics) AddInFlight(delta int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inFlight += delta
+	m.maxInFlight = max(m.maxInFlight, m.inFlight)
+}
+
+func (m *testMetrics) PacketDropped(r DropReason) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.dropped[r]++
+}
+
+func (m *testMetrics) ObserveLimiterWait(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.waits++
+}
+
+func (m *testMetrics) RequestRetried(cmd string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retries++
+}
+
+func TestClient_SetMetrics(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	var n int
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		n++
+		if n < 2 {
+			return "602 SERVER BUSY"
+		}
+		return "300 PONG"
+	})
+	c := s.client
+	c.RetryPolicy = RetryPolicy{
+		MaxAttempts: 2,
+		Base:        time.Millisecond,
+		Codes:       []codes.ReturnCode{codes.SERVER_BUSY},
+	}
+	m := newTestMetrics()
+	c.SetMetrics(m)
+	if err := c.PingSimple(ctx); err != nil {
+		t.Fatal(err)
+	}
+	c.m.handleResponseData([]byte("zzz 300 PONG"))
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if got := m.requests["PING SERVER_BUSY"]; got != 1 {
+		t.Errorf("Got %d busy requests; want 1", got)
+	}
+	if got := m.requests["PING PONG"]; got != 1 {
+		t.Errorf("Got %d pong requests; want 1", got)
+	}
+	if m.inFlight != 0 || m.maxInFlight != 1 {
+		t.Errorf("Got in flight %d (max %d); want 0 (max 1)", m.inFlight, m.maxInFlight)
+	}
+	if m.retries != 1 {
+		t.Errorf("Got %d retries; want 1", m.retries)
+	}
+	if m.waits != 2 {
+		t.Errorf("Got %d limiter waits; want 2", m.waits)
+	}
+	if got := m.dropped[DropUnknownTag]; got != 1 {
+		t.Errorf("Got %d unknown tag drops; want 1", got)
+	}
+}
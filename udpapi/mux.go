@@ -21,18 +21,21 @@ package udpapi
 import (
 	"bytes"
 	"compress/flate"
+	"container/list"
 	"context"
 	"crypto/cipher"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"math/rand"
 	"net"
 	"net/url"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"go.felesatra.moe/anidb/udpapi/codes"
 )
@@ -45,15 +48,28 @@ import (
 // Mux handles the response tag in the UDP API which allows
 // asynchronous, simultaneous requests, as well as decompression and
 // decryption, as those are necessary to read the response tag.
+// Duplicate responses are dropped, and late responses to requests
+// that timed out are kept to satisfy retries made by [Client].
 //
 // The methods can be called concurrently.
 type Mux struct {
 	wg         sync.WaitGroup
 	tagCounter tagCounter
 	block      syncVar[cipher.Block]
+	utf8Mode   syncVar[UTF8Mode]
+	unescape   syncVar[func(string) string]
+	timeout    syncVar[time.Duration]
+	decoder    syncVar[decoder]
+	trace      syncVar[*Trace]
+	metrics    syncVar[Metrics]
+	// interceptors are added with Use.
+	interceptors syncVar[[]Interceptor]
+
+	connMu sync.Mutex
+	conn   net.Conn
+	closed bool
 
 	// Set on init
-	conn      net.Conn
 	logger    *slog.Logger
 	responses responseMap
 }
@@ -72,24 +88,65 @@ func NewMux(conn net.Conn, l *slog.Logger) *Mux {
 			logger: l.With("package", "go.felesatra.moe/anidb/udpapi", "component", "mux"),
 		},
 	}
+	m.startReader(conn)
+	return m
+}
+
+// startReader starts handling responses from a connection.
+func (m *Mux) startReader(conn net.Conn) {
 	m.wg.Add(1)
 	go func() {
 		defer m.wg.Done()
-		m.handleResponses()
+		m.handleResponses(conn)
 	}()
-	return m
+}
+
+// ReplaceConn replaces the underlying connection, such as to
+// reconnect after a network change.
+// The old connection is closed.
+// Pending requests are not affected, but responses sent to the old
+// connection are lost.
+// If the Mux is closed, conn is closed and an error is returned.
+func (m *Mux) ReplaceConn(conn net.Conn) error {
+	m.connMu.Lock()
+	defer m.connMu.Unlock()
+	if m.closed {
+		conn.Close()
+		return errors.New("mux replace conn: mux closed")
+	}
+	old := m.conn
+	m.conn = conn
+	m.startReader(conn)
+	if err := old.Close(); err != nil {
+		m.logger.Debug("Error closing old conn", "error", err)
+	}
+	return nil
+}
+
+func (m *Mux) getConn() net.Conn {
+	m.connMu.Lock()
+	defer m.connMu.Unlock()
+	return m.conn
 }
 
 // Request performs an AniDB UDP API request.
 // args is modified; this method sets a new request tag.
+// Argument values are escaped per the UDP API, with newlines sent as
+// "<br />" and ampersands as "&amp;", so they should not be escaped by
+// the caller.
 //
 // This method DOES NOT handle retries or rate limiting.
 //
 // This method handles decompression and decryption, as they are
 // necessary to parse response tags.
 //
-// Callers must set a deadline; otherwise the request may block
-// indefinitely due to dropped UDP packets.
+// Each request times out after the duration set by [Mux.SetTimeout],
+// in addition to any deadline of ctx.
+// If the timeout is disabled, callers must set a deadline; otherwise
+// the request may block indefinitely due to dropped UDP packets.
+//
+// If the response was truncated by the server, the partial response
+// is returned with an error wrapping [ErrTruncated].
 //
 // See the AniDB UDP API documentation for more information.
 //
@@ -97,33 +154,110 @@ func NewMux(conn net.Conn, l *slog.Logger) *Mux {
 //
 //	context.DeadlineExceeded
 //	net.Error
+//	ErrRequestTooLarge
+//	ErrTruncated
+//
+// Interceptors added with [Mux.Use] wrap each request.
 func (m *Mux) Request(ctx context.Context, cmd string, args url.Values) (Response, error) {
-	ctx, cf := context.WithTimeout(ctx, 5*time.Second)
-	defer cf()
+	return chain(m.send, m.interceptors.get())(ctx, cmd, args)
+}
+
+// send sends a request, after any interceptors.
+func (m *Mux) send(ctx context.Context, cmd string, args url.Values) (Response, error) {
 	t := m.tagCounter.next()
 	args.Set("tag", string(t))
-	req := []byte(cmd + " " + args.Encode())
+	tr := m.trace.get()
+	info := RequestInfo{Cmd: cmd, Tag: string(t), Attempt: attemptFromContext(ctx)}
+	if tr != nil && tr.OnRequest != nil {
+		tr.OnRequest(info)
+	}
+	mt := m.getMetrics()
+	mt.AddInFlight(1)
+	start := time.Now()
+	resp, err := m.request(ctx, t, cmd, args)
+	d := time.Since(start)
+	mt.AddInFlight(-1)
+	mt.ObserveRequest(cmd, resp.Code, err, d)
+	if tr != nil && tr.OnResponse != nil {
+		tr.OnResponse(ResponseInfo{
+			RequestInfo: info,
+			Code:        resp.Code,
+			Duration:    d,
+			Err:         err,
+		})
+	}
+	return resp, err
+}
+
+func (m *Mux) request(ctx context.Context, t responseTag, cmd string, args url.Values) (Response, error) {
+	if d := m.requestTimeout(); d > 0 {
+		var cf context.CancelFunc
+		ctx, cf = context.WithTimeout(ctx, d)
+		defer cf()
+	}
+	req := []byte(cmd + " " + encodeArgs(args))
 	if b := m.block.get(); b != nil {
 		req = encrypt(b, req)
 	}
-	c := m.responses.waitFor(t)
+	if n := len(req); n > MaxPacketSize {
+		return Response{}, fmt.Errorf("mux request: %w (%d bytes)", ErrRequestTooLarge, n)
+	}
+	var prev []responseTag
+	if tc := tagChainFromContext(ctx); tc != nil {
+		prev = tc.tags
+		tc.tags = append(tc.tags[:len(tc.tags):len(tc.tags)], t)
+	}
+	c := m.responses.waitFor(t, prev...)
 	defer m.responses.cancel(t)
-	// Network writes aren't governed by context deadlines.
-	if _, err := m.conn.Write(req); err != nil {
-		return Response{}, fmt.Errorf("mux request: %w", err)
+	// A late response to a previous attempt may already be
+	// available, in which case the request is not sent.
+	if len(c) == 0 {
+		// Network writes aren't governed by context deadlines.
+		if _, err := m.getConn().Write(req); err != nil {
+			return Response{}, fmt.Errorf("mux request: %w", err)
+		}
 	}
 	select {
 	case <-ctx.Done():
 		return Response{}, ctx.Err()
-	case d := <-c:
-		resp, err := parseResponse(d)
+	case p := <-c:
+		resp, err := parseResponse(p.data)
 		if err != nil {
 			return Response{}, fmt.Errorf("mux request: %s", err)
 		}
+		if f := m.unescape.get(); f != nil {
+			resp.Rows = unescapeRows(resp.RawRows, f)
+		}
+		if err := checkUTF8(&resp, m.utf8Mode.get()); err != nil {
+			return Response{}, fmt.Errorf("mux request: %w", err)
+		}
+		if p.truncated {
+			resp.Truncated = true
+			return resp, fmt.Errorf("mux request: %w", ErrTruncated)
+		}
 		return resp, nil
 	}
 }
 
+// MaxPacketSize is the maximum size of a UDP API packet in bytes.
+// Responses that do not fit are truncated by the server.
+const MaxPacketSize = 1400
+
+// maxDatagramSize is the maximum size of a UDP datagram.
+const maxDatagramSize = 65535
+
+// maxDecompressedSize is the maximum size of a decompressed
+// response, to guard against malicious data.
+const maxDecompressedSize = 1 << 20
+
+// ErrRequestTooLarge is returned for requests larger than
+// [MaxPacketSize].
+var ErrRequestTooLarge = errors.New("request too large")
+
+// ErrTruncated is returned for responses that were truncated by the
+// server.
+var ErrTruncated = errors.New("response truncated")
+
 // SetBlock sets the cipher block to use for future requests and responses.
 // Set to nil to disable encryption and decryption.
 //
@@ -132,25 +266,86 @@ func (m *Mux) SetBlock(b cipher.Block) {
 	m.block.set(b)
 }
 
+// SetTagPrefix sets the prefix for the tags of future requests.
+// Responses with tags that do not start with a prefix in use are
+// dropped.
+//
+// By default, a random prefix starting with "go" is used, which
+// avoids collisions with untagged server packets and other clients.
+// The prefix should end with a character that is not a hexadecimal
+// digit, as a hexadecimal counter is appended.
+// The prefix must not be empty or contain spaces.
+func (m *Mux) SetTagPrefix(p string) {
+	if p == "" || strings.ContainsAny(p, " \t\n") {
+		panic(fmt.Sprintf("invalid tag prefix %q", p))
+	}
+	m.tagCounter.setPrefix(p)
+}
+
+// SetUTF8Mode sets how invalid UTF-8 in response fields is handled.
+func (m *Mux) SetUTF8Mode(mode UTF8Mode) {
+	m.utf8Mode.set(mode)
+}
+
+// DefaultRequestTimeout is the default timeout for each request made
+// by a [Mux].
+const DefaultRequestTimeout = 5 * time.Second
+
+// SetTimeout sets the timeout for each request.
+// If d is zero, [DefaultRequestTimeout] is used.
+// If d is negative, no timeout is imposed and only the request
+// context deadline applies.
+func (m *Mux) SetTimeout(d time.Duration) {
+	m.timeout.set(d)
+}
+
+func (m *Mux) requestTimeout() time.Duration {
+	if d := m.timeout.get(); d != 0 {
+		return d
+	}
+	return DefaultRequestTimeout
+}
+
+// SetUnescape sets the function used to unescape response fields
+// for [Response.Rows].
+// Set to nil to use [UnescapeField].
+func (m *Mux) SetUnescape(f func(string) string) {
+	m.unescape.set(f)
+}
+
 // Close immediately closes the Mux.
 // The underlying connection is closed.
 // No new requests will be accepted (as the connection is closed).
 // Any Request calls waiting for responses will be unblocked.
-func (m *Mux) Close() {
-	_ = m.conn.Close()
+// Returns any error from closing the underlying connection.
+func (m *Mux) Close() error {
+	m.connMu.Lock()
+	m.closed = true
+	err := m.conn.Close()
+	m.connMu.Unlock()
 	m.responses.close()
 	m.wg.Wait()
+	if err != nil {
+		return fmt.Errorf("mux close: %w", err)
+	}
+	return nil
 }
 
-// handleResponses handles incoming responses.
+// handleResponses handles incoming responses from conn.
 // Should be called as a goroutine.
 // Will exit when connection is closed.
-func (m *Mux) handleResponses() {
-	buf := make([]byte, 1400) // Max UDP size
+func (m *Mux) handleResponses(conn net.Conn) {
+	// Read into a buffer larger than any packet so reads never
+	// truncate, so server truncation can be detected by size.
+	buf := make([]byte, maxDatagramSize)
 	for {
-		n, readErr := m.conn.Read(buf)
+		n, readErr := conn.Read(buf)
 		if n > 0 {
-			m.handleResponseData(buf[:n])
+			// The data is delivered to requests, so it must not
+			// share the read buffer.
+			data := make([]byte, n)
+			copy(data, buf[:n])
+			m.handleResponseData(data)
 		}
 		if readErr != nil {
 			if errors.Is(readErr, net.ErrClosed) {
@@ -164,89 +359,285 @@ func (m *Mux) handleResponses() {
 // handleResponseData handles one incoming response packet.
 // Does decryption and decompression, as it is needed to match the response tag.
 func (m *Mux) handleResponseData(data []byte) {
+	// A full packet means the server truncated the response.
+	truncated := len(data) >= MaxPacketSize
 	if b := m.block.get(); b != nil {
 		var err error
-		data, err = decrypt(b, data)
+		if truncated {
+			data = decryptPartial(b, data)
+		} else {
+			data, err = decrypt(b, data)
+		}
 		if err != nil {
 			m.logger.Error("Error decrypting response data",
 				"error", err,
 				"data", data)
+			m.getMetrics().PacketDropped(DropDecrypt)
 			return
 		}
 	}
 	if len(data) > 2 && data[0] == 0 && data[1] == 0 {
 		var err error
 		data, err = decompress(data[2:])
-		if err != nil {
+		// A truncated stream can be partially decompressed.
+		if err != nil && !(truncated && len(data) > 0) {
 			m.logger.Error("Error decompressing response data",
 				"error", err,
 				"data", data)
+			m.getMetrics().PacketDropped(DropDecompress)
 			return
 		}
 	}
-	m.responses.deliver(splitTag(data))
+	if d := m.decoder.get(); d != nil {
+		data = d(data)
+	}
+	t, data := splitTag(data)
+	if !m.tagCounter.owns(t) {
+		// Such as untagged notifications, which could otherwise
+		// be mistaken for a tag.
+		m.logger.Debug("Dropping response with foreign tag", "tag", t)
+		m.getMetrics().PacketDropped(DropUnknownTag)
+		return
+	}
+	if r, ok := m.responses.deliver(t, packet{data: data, truncated: truncated}); !ok {
+		m.getMetrics().PacketDropped(r)
+	}
+}
+
+// A packet is the data of a response packet, without the tag.
+type packet struct {
+	data      []byte
+	truncated bool
 }
 
 // A responseMap tracks pending UDP responses by tag, so they can be
 // delivered out of order.
+//
+// Recently finished tags are remembered, so duplicate responses can be
+// dropped quietly and late responses to requests that timed out can be
+// used to satisfy their retries.
 // This is concurrent safe.
 type responseMap struct {
-	m      sync.Map
-	logger *slog.Logger // Must be non-nil
+	mu      sync.Mutex
+	pending map[responseTag]chan packet
+	recent  map[responseTag]*list.Element
+	order   list.List    // of *recentTag, oldest first
+	logger  *slog.Logger // Must be non-nil
+}
+
+// maxRecentTags is the number of recently finished tags remembered
+// by a responseMap.
+const maxRecentTags = 128
+
+// A recentTag is a tag whose request has finished.
+type recentTag struct {
+	tag responseTag
+	// expired is set if the request gave up before receiving a
+	// response.
+	expired bool
+	// late is a response received after the request expired.
+	late *packet
+	// retry is the tag of a retry waiting for this response.
+	retry responseTag
 }
 
 // waitFor registers a response tag.
 // The caller must ensure that [responseMap.cancel] is called so the
 // tag isn't leaked.
-func (m *responseMap) waitFor(t responseTag) <-chan []byte {
-	c := make(chan []byte, 1)
-	_, loaded := m.m.LoadOrStore(t, c)
-	if loaded {
+//
+// prev are the tags of previous attempts of the same request.
+// A late response to a previous attempt is delivered to the returned
+// channel, possibly immediately.
+func (m *responseMap) waitFor(t responseTag, prev ...responseTag) <-chan packet {
+	c := make(chan packet, 1)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.pending[t]; ok {
 		panic(fmt.Sprintf("dupe tag %q", t))
 	}
+	if m.pending == nil {
+		m.pending = make(map[responseTag]chan packet)
+	}
+	for _, pt := range prev {
+		e, ok := m.recent[pt]
+		if !ok {
+			continue
+		}
+		r := e.Value.(*recentTag)
+		if !r.expired {
+			continue
+		}
+		if r.late != nil {
+			m.logger.Debug("Using late response of previous attempt", "tag", t, "prev", pt)
+			c <- *r.late
+			close(c)
+			r.late = nil
+			r.expired = false
+			m.remember(&recentTag{tag: t})
+			return c
+		}
+		r.retry = t
+	}
+	m.pending[t] = c
 	return c
 }
 
-func (m *responseMap) deliver(t responseTag, b []byte) {
-	v, loaded := m.m.LoadAndDelete(t)
-	if !loaded {
+// deliver delivers a packet for a response tag.
+// Returns false and the reason if the packet is dropped.
+func (m *responseMap) deliver(t responseTag, p packet) (DropReason, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.deliverLocked(t, p) {
+		return 0, true
+	}
+	e, ok := m.recent[t]
+	if !ok {
 		m.logger.Warn("Error delivering data for response tag",
 			"error", "unknown tag",
-			"tag", t, "data", b)
-		return
+			"tag", t, "data", p.data)
+		return DropUnknownTag, false
+	}
+	r := e.Value.(*recentTag)
+	if !r.expired {
+		m.logger.Debug("Dropping duplicate response", "tag", t)
+		return DropDuplicate, false
+	}
+	if r.retry != "" && m.deliverLocked(r.retry, p) {
+		m.logger.Debug("Delivered late response to retry", "tag", t, "retry", r.retry)
+		r.expired = false
+		return 0, true
+	}
+	if r.late != nil {
+		m.logger.Debug("Dropping duplicate response", "tag", t)
+		return DropDuplicate, false
+	}
+	m.logger.Debug("Keeping late response", "tag", t)
+	r.late = &p
+	return 0, true
+}
+
+// deliverLocked delivers a packet to a pending tag.
+// Returns false if the tag is not pending.
+func (m *responseMap) deliverLocked(t responseTag, p packet) bool {
+	c, ok := m.pending[t]
+	if !ok {
+		return false
 	}
-	c := v.(chan []byte)
-	c <- b
+	delete(m.pending, t)
+	c <- p
 	close(c)
+	m.remember(&recentTag{tag: t})
+	return true
 }
 
+// cancel unregisters a response tag.
+// If the response was not received, the tag is remembered as expired.
 func (m *responseMap) cancel(t responseTag) {
-	m.m.Delete(t)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.pending[t]; !ok {
+		return
+	}
+	delete(m.pending, t)
+	m.remember(&recentTag{tag: t, expired: true})
+}
+
+// remember adds a recently finished tag, evicting the oldest.
+func (m *responseMap) remember(r *recentTag) {
+	if m.recent == nil {
+		m.recent = make(map[responseTag]*list.Element)
+	}
+	m.recent[r.tag] = m.order.PushBack(r)
+	for m.order.Len() > maxRecentTags {
+		e := m.order.Front()
+		m.order.Remove(e)
+		delete(m.recent, e.Value.(*recentTag).tag)
+	}
 }
 
-// close delivers empty bytes to all pending responses.
+// close delivers empty packets to all pending responses.
 // Doesn't handle any new pending responses created while close is running.
 func (m *responseMap) close() {
-	m.m.Range(func(key, value any) bool {
-		m.deliver(key.(responseTag), nil)
-		return true
-	})
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for t := range m.pending {
+		m.deliverLocked(t, packet{})
+	}
+}
+
+// A tagChain records the tags used by the attempts of a request.
+// This is not concurrency safe, as attempts are sequential.
+type tagChain struct {
+	tags []responseTag
+}
+
+type tagChainKey struct{}
+
+// withTagChain returns a context carrying a tagChain, so retries of a
+// request can be satisfied by late responses to earlier attempts.
+func withTagChain(ctx context.Context, c *tagChain) context.Context {
+	return context.WithValue(ctx, tagChainKey{}, c)
+}
+
+// tagChainFromContext returns the tagChain of a request, or nil.
+func tagChainFromContext(ctx context.Context) *tagChain {
+	c, _ := ctx.Value(tagChainKey{}).(*tagChain)
+	return c
 }
 
 type responseTag string
 
-// A tagCounter generates sequential responseTags.
+// A tagCounter generates sequential responseTags with a prefix.
 // This is concurrency safe.
 type tagCounter struct {
 	mu sync.Mutex
 	c  uint
+	// prefixes are all prefixes used, the current one last.
+	prefixes []string
+}
+
+// defaultTagPrefix returns a random tag prefix.
+// The prefix ends with a letter that is not a hex digit, so it is
+// unambiguous when followed by the hex counter.
+func defaultTagPrefix() string {
+	const letters = "ghijklmnopqrstuvwxyz"
+	b := []byte("go")
+	for i := 0; i < 3; i++ {
+		b = append(b, letters[rand.Intn(len(letters))])
+	}
+	return string(b)
 }
 
 func (c *tagCounter) next() responseTag {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.c++
-	return responseTag(fmt.Sprintf("%x", c.c))
+	return responseTag(c.prefixLocked() + fmt.Sprintf("%x", c.c))
+}
+
+func (c *tagCounter) setPrefix(p string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.prefixes = append(c.prefixes, p)
+}
+
+func (c *tagCounter) prefixLocked() string {
+	if len(c.prefixes) == 0 {
+		c.prefixes = append(c.prefixes, defaultTagPrefix())
+	}
+	return c.prefixes[len(c.prefixes)-1]
+}
+
+// owns returns whether a tag may have been generated by the counter.
+func (c *tagCounter) owns(t responseTag) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, p := range c.prefixes {
+		if strings.HasPrefix(string(t), p) {
+			return true
+		}
+	}
+	return false
 }
 
 // splitTag splits the tag off a UDP response body.
@@ -267,7 +658,15 @@ func splitTag(b []byte) (responseTag, []byte) {
 type Response struct {
 	Code   codes.ReturnCode
 	Header string
-	Rows   [][]string
+	// Rows are the response fields, unescaped.
+	Rows [][]string
+	// RawRows are the response fields as received, before
+	// unescaping.
+	// They are not checked for invalid UTF-8.
+	RawRows [][]string
+	// Truncated is set if the response was truncated by the server.
+	// The last row is likely incomplete.
+	Truncated bool
 }
 
 // parseResponse parses UDP responses, without the tag.
@@ -288,22 +687,84 @@ func parseResponse(b []byte) (Response, error) {
 		if line == "" {
 			continue
 		}
-		row := strings.Split(line, "|")
-		for i, f := range row {
-			row[i] = unescapeField(f)
-		}
-		r.Rows = append(r.Rows, row)
+		r.RawRows = append(r.RawRows, strings.Split(line, "|"))
 	}
+	r.Rows = unescapeRows(r.RawRows, UnescapeField)
 	return r, nil
 }
 
+// unescapeRows returns a copy of rows with the fields unescaped by f.
+func unescapeRows(rows [][]string, f func(string) string) [][]string {
+	if rows == nil {
+		return nil
+	}
+	u := make([][]string, len(rows))
+	for i, row := range rows {
+		u[i] = make([]string, len(row))
+		for j, s := range row {
+			u[i][j] = f(s)
+		}
+	}
+	return u
+}
+
+// A UTF8Mode controls how invalid UTF-8 in response fields is handled.
+// This matters if the response encoding is not UTF-8.
+type UTF8Mode int
+
+const (
+	// UTF8Ignore passes fields through as is.
+	UTF8Ignore UTF8Mode = iota
+	// UTF8Replace replaces invalid UTF-8 with the Unicode
+	// replacement character.
+	UTF8Replace
+	// UTF8Error returns an error wrapping [ErrInvalidUTF8].
+	UTF8Error
+)
+
+// ErrInvalidUTF8 is returned for responses with invalid UTF-8 when
+// using [UTF8Error].
+var ErrInvalidUTF8 = errors.New("invalid UTF-8 in response")
+
+// checkUTF8 validates the header and fields of a response, in place.
+func checkUTF8(r *Response, mode UTF8Mode) error {
+	if mode == UTF8Ignore {
+		return nil
+	}
+	check := func(s *string) error {
+		if utf8.ValidString(*s) {
+			return nil
+		}
+		if mode == UTF8Error {
+			return fmt.Errorf("%w: %q", ErrInvalidUTF8, *s)
+		}
+		*s = strings.ToValidUTF8(*s, string(utf8.RuneError))
+		return nil
+	}
+	if err := check(&r.Header); err != nil {
+		return err
+	}
+	for _, row := range r.Rows {
+		for i := range row {
+			if err := check(&row[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // DEFLATE
 func decompress(b []byte) ([]byte, error) {
 	r := flate.NewReader(bytes.NewReader(b))
 	defer r.Close()
 	var buf bytes.Buffer
-	if _, err := io.Copy(&buf, r); err != nil {
-		return nil, fmt.Errorf("decompress: %s", err)
+	if _, err := io.Copy(&buf, io.LimitReader(r, maxDecompressedSize+1)); err != nil {
+		// Return what was decompressed for truncated data.
+		return buf.Bytes(), fmt.Errorf("decompress: %s", err)
+	}
+	if buf.Len() > maxDecompressedSize {
+		return nil, fmt.Errorf("decompress: data larger than %d bytes", maxDecompressedSize)
 	}
 	return buf.Bytes(), nil
 }
@@ -337,15 +798,70 @@ func decrypt(c cipher.Block, b []byte) ([]byte, error) {
 	for i := 0; i < len(b); i += bs {
 		c.Decrypt(b[i:], b[i:])
 	}
+	if len(b) == 0 {
+		return b, nil
+	}
 	// PKCS#5 padding
-	pad := b[len(b)-1]
-	return b[:len(b)-int(pad)], nil
+	pad := int(b[len(b)-1])
+	if pad == 0 || pad > bs {
+		// Usually the data was encrypted with a different key.
+		return nil, fmt.Errorf("decrypt blocks: invalid padding")
+	}
+	return b[:len(b)-pad], nil
+}
+
+// decryptPartial decrypts the complete blocks of truncated data in
+// place.
+// Padding is not removed, as the data is incomplete.
+func decryptPartial(c cipher.Block, b []byte) []byte {
+	bs := c.BlockSize()
+	b = b[:len(b)-len(b)%bs]
+	for i := 0; i < len(b); i += bs {
+		c.Decrypt(b[i:], b[i:])
+	}
+	return b
+}
+
+// encodeArgs encodes request arguments, escaping the values per the
+// UDP API.
+// args is not modified, so it can be reused for retries.
+func encodeArgs(args url.Values) string {
+	v := make(url.Values, len(args))
+	for k, vs := range args {
+		e := make([]string, len(vs))
+		for i, s := range vs {
+			e[i] = escapeField(s)
+		}
+		v[k] = e
+	}
+	return v.Encode()
 }
 
-// unescape UDP field
-func unescapeField(s string) string {
+// escape UDP field
+func escapeField(s string) string {
+	s = strings.ReplaceAll(s, "&", "&amp;")
+	s = strings.ReplaceAll(s, "\r\n", "<br />")
+	s = strings.ReplaceAll(s, "\n", "<br />")
+	return s
+}
+
+// UnescapeField unescapes a UDP API response field.
+// This is the default used by [Mux].
+//
+// The API returns newlines as "<br />" and apostrophes as "`", as
+// apostrophes separate list items.
+// Pipes are returned as "/", but they are left as is since they
+// cannot be distinguished from actual slashes, which are far more
+// common, such as in titles.
+// Use [UnescapePipes] for fields known to not contain slashes.
+func UnescapeField(s string) string {
 	s = strings.ReplaceAll(s, "<br />", "\n")
 	s = strings.ReplaceAll(s, "`", "'")
-	s = strings.ReplaceAll(s, "/", "|")
 	return s
 }
+
+// UnescapePipes converts slashes in a response field to pipes, which
+// the API returns as slashes.
+func UnescapePipes(s string) string {
+	return strings.ReplaceAll(s, "/", "|")
+}
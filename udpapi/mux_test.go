@@ -20,6 +20,7 @@ import (
 	"context"
 	"crypto/aes"
 	"crypto/rand"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net"
@@ -38,7 +39,7 @@ func TestMux(t *testing.T) {
 	ctx := testContext(t, time.Second)
 	pc, c := newUDPPipe(t, time.Second)
 	m := NewMux(c, nullLogger)
-	t.Cleanup(m.Close)
+	t.Cleanup(func() { m.Close() })
 
 	t.Run("first request", func(t *testing.T) {
 		t.Parallel()
@@ -47,9 +48,10 @@ func TestMux(t *testing.T) {
 			t.Fatal(err)
 		}
 		want := Response{
-			Code:   300,
-			Header: "PONG",
-			Rows:   [][]string{{"123"}},
+			Code:    300,
+			Header:  "PONG",
+			Rows:    [][]string{{"123"}},
+			RawRows: [][]string{{"123"}},
 		}
 		if !reflect.DeepEqual(resp, want) {
 			t.Errorf("Got %#v; want %#v", resp, want)
@@ -104,7 +106,7 @@ func TestMux_close_requests(t *testing.T) {
 	ctx := testContext(t, time.Second)
 	pc, c := newUDPPipe(t, time.Second)
 	m := NewMux(c, nullLogger)
-	t.Cleanup(m.Close)
+	t.Cleanup(func() { m.Close() })
 
 	t.Run("first request", func(t *testing.T) {
 		t.Parallel()
@@ -133,6 +135,27 @@ func TestMux_close_requests(t *testing.T) {
 	})
 }
 
+func TestMux_Close_error(t *testing.T) {
+	t.Parallel()
+	_, c := newUDPPipe(t, time.Second)
+	m := NewMux(errCloseConn{c}, nullLogger)
+	if err := m.Close(); !errors.Is(err, errClose) {
+		t.Errorf("Got error %v; want %v", err, errClose)
+	}
+}
+
+var errClose = errors.New("close failed")
+
+// An errCloseConn is a net.Conn whose Close always returns errClose.
+type errCloseConn struct {
+	net.Conn
+}
+
+func (c errCloseConn) Close() error {
+	_ = c.Conn.Close()
+	return errClose
+}
+
 // TODO Add test for Mux decryption.
 
 func TestMux_compression(t *testing.T) {
@@ -140,7 +163,7 @@ func TestMux_compression(t *testing.T) {
 	ctx := testContext(t, time.Second)
 	pc, c := newUDPPipe(t, time.Second)
 	m := NewMux(c, nullLogger)
-	t.Cleanup(m.Close)
+	t.Cleanup(func() { m.Close() })
 
 	t.Run("request", func(t *testing.T) {
 		t.Parallel()
@@ -173,6 +196,173 @@ func TestMux_compression(t *testing.T) {
 	})
 }
 
+func TestMux_compressionLarge(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	pc, c := newUDPPipe(t, time.Second)
+	m := NewMux(c, nullLogger)
+	t.Cleanup(func() { m.Close() })
+
+	row := strings.Repeat("a", 4*MaxPacketSize)
+	t.Run("request", func(t *testing.T) {
+		t.Parallel()
+		resp, err := m.Request(ctx, "ANIMEDESC", url.Values{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Rows) != 1 || resp.Rows[0][0] != row {
+			t.Errorf("Got rows %v", resp.Rows)
+		}
+	})
+	t.Run("test server", func(t *testing.T) {
+		t.Parallel()
+		data := make([]byte, 200)
+		n, _, err := pc.ReadFrom(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tag := parseRequestTag(data[:n])
+		resp := []byte(fmt.Sprintf("%s 233 ANIMEDESC\n%s", tag, row))
+		resp = append([]byte{0, 0}, compress(resp)...)
+		if len(resp) >= MaxPacketSize {
+			t.Fatalf("Compressed response is %d bytes", len(resp))
+		}
+		if _, err := pc.WriteTo(resp, c.LocalAddr()); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestDecompress_limit(t *testing.T) {
+	t.Parallel()
+	d := compress(make([]byte, maxDecompressedSize+1))
+	if _, err := decompress(d); err == nil {
+		t.Errorf("Expected error")
+	}
+}
+
+func TestMux_truncated(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	pc, c := newUDPPipe(t, time.Second)
+	m := NewMux(c, nullLogger)
+	t.Cleanup(func() { m.Close() })
+
+	t.Run("request", func(t *testing.T) {
+		t.Parallel()
+		resp, err := m.Request(ctx, "MYLISTEXPORT", url.Values{})
+		if !errors.Is(err, ErrTruncated) {
+			t.Errorf("Got error %v; want %v", err, ErrTruncated)
+		}
+		if !resp.Truncated || resp.Code != 200 {
+			t.Errorf("Got %#v", resp)
+		}
+	})
+	t.Run("test server", func(t *testing.T) {
+		t.Parallel()
+		data := make([]byte, 200)
+		n, _, err := pc.ReadFrom(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tag := parseRequestTag(data[:n])
+		resp := []byte(fmt.Sprintf("%s 200 DATA\n", tag))
+		resp = append(resp, bytes.Repeat([]byte("a|"), MaxPacketSize)...)
+		if _, err := pc.WriteTo(resp[:MaxPacketSize], c.LocalAddr()); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestMux_requestTooLarge(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	_, c := newUDPPipe(t, time.Second)
+	m := NewMux(c, nullLogger)
+	t.Cleanup(func() { m.Close() })
+	v := url.Values{}
+	v.Set("body", strings.Repeat("a", MaxPacketSize))
+	if _, err := m.Request(ctx, "SENDMSG", v); !errors.Is(err, ErrRequestTooLarge) {
+		t.Errorf("Got error %v; want %v", err, ErrRequestTooLarge)
+	}
+}
+
+func TestMux_SetTimeout(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, 2*time.Second)
+	_, c := newUDPPipe(t, 2*time.Second)
+	m := NewMux(c, nullLogger)
+	t.Cleanup(func() { m.Close() })
+	m.SetTimeout(50 * time.Millisecond)
+	start := time.Now()
+	if _, err := m.Request(ctx, "PING", url.Values{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Got error %v; want %v", err, context.DeadlineExceeded)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("Request took %s; want timeout after 50ms", d)
+	}
+}
+
+func TestMux_SetTagPrefix(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	pc, c := newUDPPipe(t, time.Second)
+	m := NewMux(c, nullLogger)
+	t.Cleanup(func() { m.Close() })
+	m.SetTagPrefix("test")
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		data := make([]byte, 200)
+		n, _, err := pc.ReadFrom(data)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		tag := parseRequestTag(data[:n])
+		if !strings.HasPrefix(string(tag), "test") {
+			t.Errorf("Got tag %q; want prefix test", tag)
+		}
+		addr := c.LocalAddr()
+		// Untagged packets must not be mistaken for responses.
+		if _, err := pc.WriteTo([]byte("1 270 NOTIFICATION"), addr); err != nil {
+			t.Error(err)
+		}
+		if _, err := pc.WriteTo([]byte(fmt.Sprintf("%s 300 PONG", tag)), addr); err != nil {
+			t.Error(err)
+		}
+	}()
+	resp, err := m.Request(ctx, "PING", url.Values{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Code != 300 {
+		t.Errorf("Got code %d; want 300", resp.Code)
+	}
+	<-done
+}
+
+func TestTagCounter(t *testing.T) {
+	t.Parallel()
+	var c tagCounter
+	t1 := c.next()
+	if !strings.HasPrefix(string(t1), "go") {
+		t.Errorf("Got tag %q; want prefix go", t1)
+	}
+	c.setPrefix("x")
+	if got, want := c.next(), responseTag("x2"); got != want {
+		t.Errorf("Got tag %q; want %q", got, want)
+	}
+	for _, tag := range []responseTag{t1, "x2"} {
+		if !c.owns(tag) {
+			t.Errorf("Got owns(%q) false; want true", tag)
+		}
+	}
+	if c.owns("1") {
+		t.Errorf("Got owns(%q) true; want false", "1")
+	}
+}
+
 func TestResponseMap(t *testing.T) {
 	t.Parallel()
 	t.Run("happy path", func(t *testing.T) {
@@ -185,8 +375,8 @@ func TestResponseMap(t *testing.T) {
 			select {
 			case got := <-c:
 				const want = "shifuna"
-				if string(got) != want {
-					t.Errorf("Got %q, want %q", got, want)
+				if string(got.data) != want {
+					t.Errorf("Got %q, want %q", got.data, want)
 				}
 			case <-ctx.Done():
 				t.Fatal(ctx.Err())
@@ -198,15 +388,15 @@ func TestResponseMap(t *testing.T) {
 			select {
 			case got := <-c:
 				const want = "kiruya"
-				if string(got) != want {
-					t.Errorf("Got %q, want %q", got, want)
+				if string(got.data) != want {
+					t.Errorf("Got %q, want %q", got.data, want)
 				}
 			case <-ctx.Done():
 				t.Fatal(ctx.Err())
 			}
 		})
-		m.deliver("kyaru", []byte("kiruya"))
-		m.deliver("shefi", []byte("shifuna"))
+		m.deliver("kyaru", packet{data: []byte("kiruya")})
+		m.deliver("shefi", packet{data: []byte("shifuna")})
 	})
 	t.Run("close", func(t *testing.T) {
 		t.Parallel()
@@ -218,8 +408,8 @@ func TestResponseMap(t *testing.T) {
 			select {
 			case got := <-c:
 				const want = ""
-				if string(got) != want {
-					t.Errorf("Got %q, want %q", got, want)
+				if string(got.data) != want {
+					t.Errorf("Got %q, want %q", got.data, want)
 				}
 			case <-ctx.Done():
 				t.Fatal(ctx.Err())
@@ -229,6 +419,69 @@ func TestResponseMap(t *testing.T) {
 	})
 }
 
+func TestResponseMap_duplicate(t *testing.T) {
+	t.Parallel()
+	m := responseMap{logger: nullLogger}
+	c := m.waitFor("shefi")
+	if _, ok := m.deliver("shefi", packet{data: []byte("shifuna")}); !ok {
+		t.Fatal("Got packet dropped; want delivered")
+	}
+	m.cancel("shefi")
+	if got := string((<-c).data); got != "shifuna" {
+		t.Errorf("Got %q; want %q", got, "shifuna")
+	}
+	r, ok := m.deliver("shefi", packet{data: []byte("shifuna")})
+	if ok || r != DropDuplicate {
+		t.Errorf("Got %v, %v; want %v, false", r, ok, DropDuplicate)
+	}
+	r, ok = m.deliver("kyaru", packet{data: []byte("kiruya")})
+	if ok || r != DropUnknownTag {
+		t.Errorf("Got %v, %v; want %v, false", r, ok, DropUnknownTag)
+	}
+}
+
+func TestResponseMap_lateBeforeRetry(t *testing.T) {
+	t.Parallel()
+	m := responseMap{logger: nullLogger}
+	m.waitFor("1")
+	m.cancel("1")
+	if _, ok := m.deliver("1", packet{data: []byte("late")}); !ok {
+		t.Fatal("Got late packet dropped; want kept")
+	}
+	c := m.waitFor("2", "1")
+	defer m.cancel("2")
+	if len(c) == 0 {
+		t.Fatal("Got no response; want late response")
+	}
+	if got := string((<-c).data); got != "late" {
+		t.Errorf("Got %q; want %q", got, "late")
+	}
+	if _, ok := m.deliver("1", packet{data: []byte("late")}); ok {
+		t.Errorf("Got duplicate late packet delivered; want dropped")
+	}
+}
+
+func TestResponseMap_lateDuringRetry(t *testing.T) {
+	t.Parallel()
+	m := responseMap{logger: nullLogger}
+	m.waitFor("1")
+	m.cancel("1")
+	c := m.waitFor("2", "1")
+	defer m.cancel("2")
+	if len(c) != 0 {
+		t.Fatal("Got response; want none yet")
+	}
+	if _, ok := m.deliver("1", packet{data: []byte("late")}); !ok {
+		t.Fatal("Got late packet dropped; want delivered to retry")
+	}
+	if got := string((<-c).data); got != "late" {
+		t.Errorf("Got %q; want %q", got, "late")
+	}
+	if r, ok := m.deliver("2", packet{data: []byte("own")}); ok || r != DropDuplicate {
+		t.Errorf("Got %v, %v; want %v, false", r, ok, DropDuplicate)
+	}
+}
+
 func TestParseResponse(t *testing.T) {
 	t.Parallel()
 	const data = `720 1234 NOTIFICATION - NEW FILE
@@ -243,12 +496,113 @@ func TestParseResponse(t *testing.T) {
 		Rows: [][]string{
 			{"1234", "12", "34"},
 		},
+		RawRows: [][]string{
+			{"1234", "12", "34"},
+		},
 	}
 	if !reflect.DeepEqual(got, want) {
 		t.Errorf("Got %#v, want %#v", got, want)
 	}
 }
 
+func TestParseResponse_escaping(t *testing.T) {
+	t.Parallel()
+	const data = "230 ANIME\nFate/stay night|line 1<br />line 2|Kino`s Journey"
+	got, err := parseResponse([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"Fate/stay night", "line 1\nline 2", "Kino's Journey"}}
+	if !reflect.DeepEqual(got.Rows, want) {
+		t.Errorf("Got %#v; want %#v", got.Rows, want)
+	}
+	wantRaw := [][]string{{"Fate/stay night", "line 1<br />line 2", "Kino`s Journey"}}
+	if !reflect.DeepEqual(got.RawRows, wantRaw) {
+		t.Errorf("Got raw %#v; want %#v", got.RawRows, wantRaw)
+	}
+}
+
+func TestUnescapePipes(t *testing.T) {
+	t.Parallel()
+	if got, want := UnescapePipes("a/b"), "a|b"; got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+}
+
+func TestEncodeArgs(t *testing.T) {
+	t.Parallel()
+	args := url.Values{}
+	args.Set("other", "a & b\nc\r\nd")
+	got, err := url.ParseQuery(encodeArgs(args))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := got.Get("other"), "a &amp; b<br />c<br />d"; got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+	if got, want := args.Get("other"), "a & b\nc\r\nd"; got != want {
+		t.Errorf("args modified: got %q; want %q", got, want)
+	}
+}
+
+func TestCheckUTF8(t *testing.T) {
+	t.Parallel()
+	const data = "220 FILE\n1|Bofuri \xff01"
+	t.Run("ignore", func(t *testing.T) {
+		t.Parallel()
+		r, err := parseResponse([]byte(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := checkUTF8(&r, UTF8Ignore); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := r.Rows[0][1], "Bofuri \xff01"; got != want {
+			t.Errorf("Got %q; want %q", got, want)
+		}
+	})
+	t.Run("replace", func(t *testing.T) {
+		t.Parallel()
+		r, err := parseResponse([]byte(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := checkUTF8(&r, UTF8Replace); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := r.Rows[0][1], "Bofuri \uFFFD01"; got != want {
+			t.Errorf("Got %q; want %q", got, want)
+		}
+	})
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		r, err := parseResponse([]byte(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := checkUTF8(&r, UTF8Error); !errors.Is(err, ErrInvalidUTF8) {
+			t.Errorf("Got error %v; want %v", err, ErrInvalidUTF8)
+		}
+	})
+}
+
+func TestDecrypt_wrongKey(t *testing.T) {
+	t.Parallel()
+	enc, err := aes.NewCipher([]byte("0123456789abcdef"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	block := encrypt(enc, []byte("PING"))
+	for i := 0; i < 256; i++ {
+		dec, err := aes.NewCipher([]byte(fmt.Sprintf("wrongkey%08d", i)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Must not panic.
+		_, _ = decrypt(dec, append([]byte(nil), block...))
+	}
+}
+
 func TestEncryptDecrypt(t *testing.T) {
 	t.Parallel()
 	// AES-128, 16 bytes
@@ -295,7 +649,7 @@ func TestEncryptDecrypt(t *testing.T) {
 	}
 }
 
-var tagRegexp = regexp.MustCompile(`tag=([0-9]+)`)
+var tagRegexp = regexp.MustCompile(`tag=([^& ]+)`)
 
 func parseRequestTag(b []byte) responseTag {
 	m := tagRegexp.FindSubmatch(b)
@@ -0,0 +1,729 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"go.felesatra.moe/anidb/udpapi/codes"
+)
+
+// A MylistState is the storage state of a mylist entry.
+type MylistState int
+
+const (
+	MylistUnknown MylistState = 0
+	MylistHDD     MylistState = 1
+	MylistCD      MylistState = 2
+	MylistDeleted MylistState = 3
+	MylistRemote  MylistState = 4
+)
+
+// A MylistEntry is a mylist entry.
+type MylistEntry struct {
+	LID   int
+	FID   int
+	EID   int
+	AID   int
+	GID   int
+	Date  time.Time
+	State MylistState
+	// ViewDate is zero if the entry has not been watched.
+	ViewDate  time.Time
+	Storage   string
+	Source    string
+	Other     string
+	FileState int
+}
+
+// decodeMylistEntry decodes a mylist entry response row.
+func decodeMylistEntry(row []string) (MylistEntry, error) {
+	var e MylistEntry
+	if n := len(row); n != 12 {
+		return e, fmt.Errorf("decode mylist entry: got unexpected number of fields %d", n)
+	}
+	var state int
+	for _, f := range []struct {
+		p *int
+		s string
+	}{
+		{&e.LID, row[0]},
+		{&e.FID, row[1]},
+		{&e.EID, row[2]},
+		{&e.AID, row[3]},
+		{&e.GID, row[4]},
+		{&state, row[6]},
+		{&e.FileState, row[11]},
+	} {
+		var err error
+		*f.p, err = strconv.Atoi(f.s)
+		if err != nil {
+			return e, fmt.Errorf("decode mylist entry: %s", err)
+		}
+	}
+	var err error
+	if e.Date, err = parseUnixTime(row[5]); err != nil {
+		return e, fmt.Errorf("decode mylist entry: %s", err)
+	}
+	if e.ViewDate, err = parseUnixTime(row[7]); err != nil {
+		return e, fmt.Errorf("decode mylist entry: %s", err)
+	}
+	e.State = MylistState(state)
+	e.Storage = row[8]
+	e.Source = row[9]
+	e.Other = row[10]
+	return e, nil
+}
+
+// ErrNoSuchEntry is returned by MYLIST queries when there is no
+// matching mylist entry.
+// The returned error also wraps [codes.NO_SUCH_ENTRY].
+var ErrNoSuchEntry = errors.New("no such mylist entry")
+
+// A MylistResult is the result of a MYLIST command.
+// Exactly one of Entry and Multiple is set.
+type MylistResult struct {
+	Entry    *MylistEntry
+	Multiple *MylistMultiEntry
+}
+
+// A MylistMultiEntry summarizes multiple mylist entries for an anime.
+// It is returned by MYLIST when the query matches more than one entry.
+// Episode fields are lists of episode ranges, like "1-12".
+type MylistMultiEntry struct {
+	AnimeTitle      string
+	Episodes        string
+	UnknownEpisodes string
+	HDDEpisodes     string
+	CDEpisodes      string
+	DeletedEpisodes string
+	WatchedEpisodes string
+	Groups          []MylistGroupEpisodes
+}
+
+// A MylistGroupEpisodes is the episodes in mylist for a group.
+type MylistGroupEpisodes struct {
+	ShortName string
+	Episodes  string
+}
+
+// decodeMylistMultiEntry decodes a MULTIPLE MYLIST ENTRIES response row.
+func decodeMylistMultiEntry(row []string) (MylistMultiEntry, error) {
+	var e MylistMultiEntry
+	if n := len(row); n < 7 || (n-7)%2 != 0 {
+		return e, fmt.Errorf("decode mylist multi entry: got unexpected number of fields %d", n)
+	}
+	e.AnimeTitle = row[0]
+	e.Episodes = row[1]
+	e.UnknownEpisodes = row[2]
+	e.HDDEpisodes = row[3]
+	e.CDEpisodes = row[4]
+	e.DeletedEpisodes = row[5]
+	e.WatchedEpisodes = row[6]
+	for i := 7; i < len(row); i += 2 {
+		e.Groups = append(e.Groups, MylistGroupEpisodes{
+			ShortName: row[i],
+			Episodes:  row[i+1],
+		})
+	}
+	return e, nil
+}
+
+// MylistByLID calls the MYLIST command by lid.
+// The returned error wraps a [codes.ReturnCode] if applicable.
+func (c *Client) MylistByLID(ctx context.Context, lid int) (MylistEntry, error) {
+	v, err := c.sessionValues(ctx)
+	if err != nil {
+		return MylistEntry{}, fmt.Errorf("udpapi MylistByLID: %w", err)
+	}
+	v.Set("lid", strconv.Itoa(lid))
+	e, err := c.mylistEntry(ctx, v)
+	if err != nil {
+		return MylistEntry{}, fmt.Errorf("udpapi MylistByLID: %w", err)
+	}
+	return e, nil
+}
+
+// MylistByFID calls the MYLIST command by fid.
+// The returned error wraps a [codes.ReturnCode] if applicable.
+func (c *Client) MylistByFID(ctx context.Context, fid int) (MylistEntry, error) {
+	v, err := c.sessionValues(ctx)
+	if err != nil {
+		return MylistEntry{}, fmt.Errorf("udpapi MylistByFID: %w", err)
+	}
+	v.Set("fid", strconv.Itoa(fid))
+	e, err := c.mylistEntry(ctx, v)
+	if err != nil {
+		return MylistEntry{}, fmt.Errorf("udpapi MylistByFID: %w", err)
+	}
+	return e, nil
+}
+
+// MylistByHash calls the MYLIST command by size+ed2k hash.
+// The returned error wraps a [codes.ReturnCode] if applicable.
+func (c *Client) MylistByHash(ctx context.Context, size int64, hash string) (MylistEntry, error) {
+	v, err := c.sessionValues(ctx)
+	if err != nil {
+		return MylistEntry{}, fmt.Errorf("udpapi MylistByHash: %w", err)
+	}
+	v.Set("size", strconv.FormatInt(size, 10))
+	v.Set("ed2k", hash)
+	e, err := c.mylistEntry(ctx, v)
+	if err != nil {
+		return MylistEntry{}, fmt.Errorf("udpapi MylistByHash: %w", err)
+	}
+	return e, nil
+}
+
+// MylistByEpisode calls the MYLIST command by aid and episode number.
+// If epno is empty, all entries for the anime are queried.
+// The returned error wraps a [codes.ReturnCode] if applicable.
+func (c *Client) MylistByEpisode(ctx context.Context, aid int, epno string) (MylistResult, error) {
+	v, err := c.sessionValues(ctx)
+	if err != nil {
+		return MylistResult{}, fmt.Errorf("udpapi MylistByEpisode: %w", err)
+	}
+	v.Set("aid", strconv.Itoa(aid))
+	if epno != "" {
+		v.Set("epno", epno)
+	}
+	r, err := c.mylist(ctx, v)
+	if err != nil {
+		return MylistResult{}, fmt.Errorf("udpapi MylistByEpisode: %w", err)
+	}
+	return r, nil
+}
+
+// mylistEntry sends a MYLIST request that should match a single entry.
+func (c *Client) mylistEntry(ctx context.Context, v url.Values) (MylistEntry, error) {
+	r, err := c.mylist(ctx, v)
+	if err != nil {
+		return MylistEntry{}, err
+	}
+	if r.Entry == nil {
+		return MylistEntry{}, errors.New("got multiple mylist entries")
+	}
+	return *r.Entry, nil
+}
+
+// mylist sends a MYLIST request.
+func (c *Client) mylist(ctx context.Context, v url.Values) (MylistResult, error) {
+	resp, err := c.request(ctx, "MYLIST", v)
+	if err != nil {
+		return MylistResult{}, err
+	}
+	switch resp.Code {
+	case codes.MYLIST:
+		if len(resp.Rows) != 1 {
+			return MylistResult{}, fmt.Errorf("got unexpected number of rows %d", len(resp.Rows))
+		}
+		e, err := decodeMylistEntry(resp.Rows[0])
+		if err != nil {
+			return MylistResult{}, err
+		}
+		return MylistResult{Entry: &e}, nil
+	case codes.MULTIPLE_MYLIST_ENTRIES:
+		if len(resp.Rows) != 1 {
+			return MylistResult{}, fmt.Errorf("got unexpected number of rows %d", len(resp.Rows))
+		}
+		e, err := decodeMylistMultiEntry(resp.Rows[0])
+		if err != nil {
+			return MylistResult{}, err
+		}
+		return MylistResult{Multiple: &e}, nil
+	case codes.NO_SUCH_ENTRY:
+		return MylistResult{}, responseError("MYLIST", v, resp, fmt.Errorf("%w (%w)", ErrNoSuchEntry, resp.Code))
+	default:
+		return MylistResult{}, codeError("MYLIST", v, resp)
+	}
+}
+
+// MylistAddOptions are the optional fields for MYLISTADD.
+// Zero values are not sent.
+type MylistAddOptions struct {
+	State MylistState
+	// Viewed sets whether the entry has been watched, if not nil.
+	Viewed *bool
+	// ViewDate is the time the entry was watched.
+	ViewDate time.Time
+	Storage  string
+	Source   string
+	Other    string
+}
+
+func (o MylistAddOptions) set(v url.Values) {
+	if o.State != MylistUnknown {
+		v.Set("state", strconv.Itoa(int(o.State)))
+	}
+	if o.Viewed != nil {
+		v.Set("viewed", formatBool(*o.Viewed))
+	}
+	if !o.ViewDate.IsZero() {
+		v.Set("viewdate", strconv.FormatInt(o.ViewDate.Unix(), 10))
+	}
+	if o.Storage != "" {
+		v.Set("storage", o.Storage)
+	}
+	if o.Source != "" {
+		v.Set("source", o.Source)
+	}
+	if o.Other != "" {
+		v.Set("other", o.Other)
+	}
+}
+
+// A MylistAddResult is the result of a MYLISTADD command.
+type MylistAddResult struct {
+	// LID is the mylist ID of the new or existing entry.
+	LID int
+	// AlreadyAdded is set if the file was already in mylist.
+	// Existing is set to the existing entry in that case.
+	AlreadyAdded bool
+	Existing     MylistEntry
+}
+
+// MylistAdd calls the MYLISTADD command by fid.
+// The returned error wraps a [codes.ReturnCode] if applicable.
+func (c *Client) MylistAdd(ctx context.Context, fid int, o MylistAddOptions) (MylistAddResult, error) {
+	v, err := c.sessionValues(ctx)
+	if err != nil {
+		return MylistAddResult{}, fmt.Errorf("udpapi MylistAdd: %w", err)
+	}
+	v.Set("fid", strconv.Itoa(fid))
+	o.set(v)
+	r, err := c.mylistAdd(ctx, v)
+	if err != nil {
+		return MylistAddResult{}, fmt.Errorf("udpapi MylistAdd: %w", err)
+	}
+	return r, nil
+}
+
+// MylistAddByHash calls the MYLISTADD command by size+ed2k hash.
+// The returned error wraps a [codes.ReturnCode] if applicable.
+func (c *Client) MylistAddByHash(ctx context.Context, size int64, hash string, o MylistAddOptions) (MylistAddResult, error) {
+	v, err := c.sessionValues(ctx)
+	if err != nil {
+		return MylistAddResult{}, fmt.Errorf("udpapi MylistAddByHash: %w", err)
+	}
+	v.Set("size", strconv.FormatInt(size, 10))
+	v.Set("ed2k", hash)
+	o.set(v)
+	r, err := c.mylistAdd(ctx, v)
+	if err != nil {
+		return MylistAddResult{}, fmt.Errorf("udpapi MylistAddByHash: %w", err)
+	}
+	return r, nil
+}
+
+// EpNoAll is the epno for selecting all episodes in
+// [Client.MylistAddByAnime] and [Client.MylistAddGeneric].
+const EpNoAll = "0"
+
+// EpNoUpTo returns the epno for selecting all regular episodes up to
+// and including episode n in [Client.MylistAddByAnime] and
+// [Client.MylistAddGeneric].
+func EpNoUpTo(n int) string {
+	return strconv.Itoa(-n)
+}
+
+// MylistAddByAnime calls the MYLISTADD command by anime, group and
+// episode, adding all matching files.
+// The episode may be a single episode, [EpNoAll] or [EpNoUpTo].
+// The number of entries added is returned.
+// The returned error wraps a [codes.ReturnCode] if applicable.
+func (c *Client) MylistAddByAnime(ctx context.Context, q FileQuery, o MylistAddOptions) (added int, _ error) {
+	v, err := c.sessionValues(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("udpapi MylistAddByAnime: %w", err)
+	}
+	q.setValues(v)
+	o.set(v)
+	n, err := c.mylistAddMulti(ctx, v)
+	if err != nil {
+		return 0, fmt.Errorf("udpapi MylistAddByAnime: %w", err)
+	}
+	return n, nil
+}
+
+// MylistAddGeneric calls the MYLISTADD command to add generic files
+// for an anime and episode, for files not known to AniDB.
+// The group in the query is ignored.
+// The episode may be a single episode, [EpNoAll] or [EpNoUpTo].
+// The number of entries added is returned.
+// The returned error wraps a [codes.ReturnCode] if applicable.
+func (c *Client) MylistAddGeneric(ctx context.Context, q FileQuery, o MylistAddOptions) (added int, _ error) {
+	v, err := c.sessionValues(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("udpapi MylistAddGeneric: %w", err)
+	}
+	q.setAnime(v)
+	v.Set("generic", "1")
+	o.set(v)
+	n, err := c.mylistAddMulti(ctx, v)
+	if err != nil {
+		return 0, fmt.Errorf("udpapi MylistAddGeneric: %w", err)
+	}
+	return n, nil
+}
+
+// mylistAddMulti sends a MYLISTADD request that may add multiple
+// entries, returning the number of entries added.
+func (c *Client) mylistAddMulti(ctx context.Context, v url.Values) (int, error) {
+	resp, err := c.request(ctx, "MYLISTADD", v)
+	if err != nil {
+		return 0, err
+	}
+	switch resp.Code {
+	case codes.MYLIST_ENTRY_ADDED:
+		if len(resp.Rows) != 1 || len(resp.Rows[0]) != 1 {
+			return 0, fmt.Errorf("unexpected response rows %q", resp.Rows)
+		}
+		return strconv.Atoi(resp.Rows[0][0])
+	default:
+		return 0, codeError("MYLISTADD", v, resp)
+	}
+}
+
+// mylistAdd sends a MYLISTADD request for adding a new entry.
+func (c *Client) mylistAdd(ctx context.Context, v url.Values) (MylistAddResult, error) {
+	resp, err := c.request(ctx, "MYLISTADD", v)
+	if err != nil {
+		return MylistAddResult{}, err
+	}
+	switch resp.Code {
+	case codes.MYLIST_ENTRY_ADDED:
+		if len(resp.Rows) != 1 || len(resp.Rows[0]) != 1 {
+			return MylistAddResult{}, fmt.Errorf("unexpected response rows %q", resp.Rows)
+		}
+		lid, err := strconv.Atoi(resp.Rows[0][0])
+		if err != nil {
+			return MylistAddResult{}, err
+		}
+		return MylistAddResult{LID: lid}, nil
+	case codes.FILE_ALREADY_IN_MYLIST:
+		if len(resp.Rows) != 1 {
+			return MylistAddResult{}, fmt.Errorf("got unexpected number of rows %d", len(resp.Rows))
+		}
+		e, err := decodeMylistEntry(resp.Rows[0])
+		if err != nil {
+			return MylistAddResult{}, err
+		}
+		return MylistAddResult{LID: e.LID, AlreadyAdded: true, Existing: e}, nil
+	default:
+		return MylistAddResult{}, codeError("MYLISTADD", v, resp)
+	}
+}
+
+// formatBool formats a bool as an API bool.
+func formatBool(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+// A MylistEditResult is the outcome of a successful mylist edit.
+type MylistEditResult int
+
+const (
+	// MylistEdited means the entry was edited.
+	MylistEdited MylistEditResult = iota
+	// MylistWatched means the entry was marked watched.
+	MylistWatched
+)
+
+func (r MylistEditResult) String() string {
+	switch r {
+	case MylistEdited:
+		return "edited"
+	case MylistWatched:
+		return "watched"
+	default:
+		return fmt.Sprintf("MylistEditResult(%d)", int(r))
+	}
+}
+
+// MylistSetWatched calls the MYLISTADD command to edit the watched
+// state of a mylist entry.
+// The returned error wraps a [codes.ReturnCode] if applicable.
+func (c *Client) MylistSetWatched(ctx context.Context, lid int, watched bool) (MylistEditResult, error) {
+	v, err := c.sessionValues(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("udpapi MylistSetWatched: %w", err)
+	}
+	v.Set("lid", strconv.Itoa(lid))
+	v.Set("edit", "1")
+	v.Set("viewed", formatBool(watched))
+	r, err := c.mylistEdit(ctx, v)
+	if err != nil {
+		return 0, fmt.Errorf("udpapi MylistSetWatched: %w", err)
+	}
+	return r, nil
+}
+
+// MylistEdit calls the MYLISTADD command in edit mode to edit a
+// mylist entry by lid.
+// Only the fields set in o are changed.
+// The returned error wraps a [codes.ReturnCode] if applicable, such
+// as [codes.NO_SUCH_MYLIST_ENTRY].
+func (c *Client) MylistEdit(ctx context.Context, lid int, o MylistAddOptions) (MylistEditResult, error) {
+	v, err := c.sessionValues(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("udpapi MylistEdit: %w", err)
+	}
+	v.Set("lid", strconv.Itoa(lid))
+	v.Set("edit", "1")
+	o.set(v)
+	r, err := c.mylistEdit(ctx, v)
+	if err != nil {
+		return 0, fmt.Errorf("udpapi MylistEdit: %w", err)
+	}
+	return r, nil
+}
+
+// MylistEditByHash calls the MYLISTADD command in edit mode to edit a
+// mylist entry by size+ed2k hash.
+// Only the fields set in o are changed.
+// The returned error wraps a [codes.ReturnCode] if applicable, such
+// as [codes.NO_SUCH_MYLIST_ENTRY].
+func (c *Client) MylistEditByHash(ctx context.Context, size int64, hash string, o MylistAddOptions) (MylistEditResult, error) {
+	v, err := c.sessionValues(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("udpapi MylistEditByHash: %w", err)
+	}
+	v.Set("size", strconv.FormatInt(size, 10))
+	v.Set("ed2k", hash)
+	v.Set("edit", "1")
+	o.set(v)
+	r, err := c.mylistEdit(ctx, v)
+	if err != nil {
+		return 0, fmt.Errorf("udpapi MylistEditByHash: %w", err)
+	}
+	return r, nil
+}
+
+// mylistEdit sends a MYLISTADD edit request.
+func (c *Client) mylistEdit(ctx context.Context, v url.Values) (MylistEditResult, error) {
+	resp, err := c.request(ctx, "MYLISTADD", v)
+	if err != nil {
+		return 0, err
+	}
+	switch resp.Code {
+	case codes.MYLIST_ENTRY_EDITED:
+		return MylistEdited, nil
+	case codes.WATCHED:
+		return MylistWatched, nil
+	default:
+		return 0, codeError("MYLISTADD", v, resp)
+	}
+}
+
+// MylistDel calls the MYLISTDEL command by lid.
+// It returns the number of entries deleted.
+// The returned error wraps a [codes.ReturnCode] if applicable.
+func (c *Client) MylistDel(ctx context.Context, lid int) (deleted int, _ error) {
+	v, err := c.sessionValues(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("udpapi MylistDel: %w", err)
+	}
+	v.Set("lid", strconv.Itoa(lid))
+	n, err := c.mylistDel(ctx, v)
+	if err != nil {
+		return 0, fmt.Errorf("udpapi MylistDel: %w", err)
+	}
+	return n, nil
+}
+
+// MylistDelByFID calls the MYLISTDEL command by fid.
+// It returns the number of entries deleted.
+// The returned error wraps a [codes.ReturnCode] if applicable.
+func (c *Client) MylistDelByFID(ctx context.Context, fid int) (deleted int, _ error) {
+	v, err := c.sessionValues(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("udpapi MylistDelByFID: %w", err)
+	}
+	v.Set("fid", strconv.Itoa(fid))
+	n, err := c.mylistDel(ctx, v)
+	if err != nil {
+		return 0, fmt.Errorf("udpapi MylistDelByFID: %w", err)
+	}
+	return n, nil
+}
+
+// MylistDelByHash calls the MYLISTDEL command by size+ed2k hash.
+// It returns the number of entries deleted.
+// The returned error wraps a [codes.ReturnCode] if applicable.
+func (c *Client) MylistDelByHash(ctx context.Context, size int64, hash string) (deleted int, _ error) {
+	v, err := c.sessionValues(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("udpapi MylistDelByHash: %w", err)
+	}
+	v.Set("size", strconv.FormatInt(size, 10))
+	v.Set("ed2k", hash)
+	n, err := c.mylistDel(ctx, v)
+	if err != nil {
+		return 0, fmt.Errorf("udpapi MylistDelByHash: %w", err)
+	}
+	return n, nil
+}
+
+// mylistDel sends a MYLISTDEL request.
+func (c *Client) mylistDel(ctx context.Context, v url.Values) (int, error) {
+	resp, err := c.request(ctx, "MYLISTDEL", v)
+	if err != nil {
+		return 0, err
+	}
+	if resp.Code != codes.MYLIST_ENTRY_DELETED {
+		return 0, codeError("MYLISTDEL", v, resp)
+	}
+	if len(resp.Rows) != 1 || len(resp.Rows[0]) != 1 {
+		return 0, fmt.Errorf("unexpected response rows %q", resp.Rows)
+	}
+	return strconv.Atoi(resp.Rows[0][0])
+}
+
+// MylistStats are the statistics returned by the MYLISTSTATS command.
+type MylistStats struct {
+	Anime    int
+	Episodes int
+	Files    int
+	// SizeMB is the total size of files in MB.
+	SizeMB int64
+
+	AddedAnime    int
+	AddedEpisodes int
+	AddedFiles    int
+	AddedGroups   int
+
+	// Percentages are integers from 0 to 100.
+	LeechPercent        int
+	GloryPercent        int
+	ViewedPercent       int
+	MylistPercent       int
+	ViewedMylistPercent int
+
+	ViewedEpisodes int
+	Votes          int
+	Reviews        int
+	ViewedMinutes  int
+}
+
+// MylistStats calls the MYLISTSTATS command.
+// The returned error wraps a [codes.ReturnCode] if applicable.
+func (c *Client) MylistStats(ctx context.Context) (MylistStats, error) {
+	v, err := c.sessionValues(ctx)
+	if err != nil {
+		return MylistStats{}, fmt.Errorf("udpapi MylistStats: %w", err)
+	}
+	resp, err := c.request(ctx, "MYLISTSTATS", v)
+	if err != nil {
+		return MylistStats{}, fmt.Errorf("udpapi MylistStats: %w", err)
+	}
+	if resp.Code != codes.MYLIST_STATS {
+		return MylistStats{}, fmt.Errorf("udpapi MylistStats: %w", codeError("MYLISTSTATS", v, resp))
+	}
+	if len(resp.Rows) != 1 {
+		return MylistStats{}, fmt.Errorf("udpapi MylistStats: got unexpected number of rows %d", len(resp.Rows))
+	}
+	st, err := decodeMylistStats(resp.Rows[0])
+	if err != nil {
+		return MylistStats{}, fmt.Errorf("udpapi MylistStats: %w", err)
+	}
+	return st, nil
+}
+
+// decodeMylistStats decodes a MYLISTSTATS response row.
+func decodeMylistStats(row []string) (MylistStats, error) {
+	var st MylistStats
+	if n := len(row); n != 17 {
+		return st, fmt.Errorf("decode mylist stats: got unexpected number of fields %d", n)
+	}
+	var err error
+	if st.SizeMB, err = strconv.ParseInt(row[3], 10, 64); err != nil {
+		return st, fmt.Errorf("decode mylist stats: %s", err)
+	}
+	for _, f := range []struct {
+		p *int
+		s string
+	}{
+		{&st.Anime, row[0]},
+		{&st.Episodes, row[1]},
+		{&st.Files, row[2]},
+		{&st.AddedAnime, row[4]},
+		{&st.AddedEpisodes, row[5]},
+		{&st.AddedFiles, row[6]},
+		{&st.AddedGroups, row[7]},
+		{&st.LeechPercent, row[8]},
+		{&st.GloryPercent, row[9]},
+		{&st.ViewedPercent, row[10]},
+		{&st.MylistPercent, row[11]},
+		{&st.ViewedMylistPercent, row[12]},
+		{&st.ViewedEpisodes, row[13]},
+		{&st.Votes, row[14]},
+		{&st.Reviews, row[15]},
+		{&st.ViewedMinutes, row[16]},
+	} {
+		*f.p, err = strconv.Atoi(f.s)
+		if err != nil {
+			return st, fmt.Errorf("decode mylist stats: %s", err)
+		}
+	}
+	return st, nil
+}
+
+// MylistExport calls the MYLISTEXPORT command to queue a mylist
+// export with the named template.
+// The returned error wraps a [codes.ReturnCode] if applicable,
+// such as EXPORT_NO_SUCH_TEMPLATE or EXPORT_ALREADY_IN_QUEUE.
+func (c *Client) MylistExport(ctx context.Context, template string) error {
+	v, err := c.sessionValues(ctx)
+	if err != nil {
+		return fmt.Errorf("udpapi MylistExport: %w", err)
+	}
+	v.Set("template", template)
+	resp, err := c.request(ctx, "MYLISTEXPORT", v)
+	if err != nil {
+		return fmt.Errorf("udpapi MylistExport: %w", err)
+	}
+	if resp.Code != codes.EXPORT_QUEUED {
+		return fmt.Errorf("udpapi MylistExport: %w", codeError("MYLISTEXPORT", v, resp))
+	}
+	return nil
+}
+
+// MylistExportCancel calls the MYLISTEXPORT command to cancel a queued
+// mylist export.
+// The returned error wraps a [codes.ReturnCode] if applicable,
+// such as EXPORT_NO_EXPORT_QUEUED_OR_IS_PROCESSING.
+func (c *Client) MylistExportCancel(ctx context.Context) error {
+	v, err := c.sessionValues(ctx)
+	if err != nil {
+		return fmt.Errorf("udpapi MylistExportCancel: %w", err)
+	}
+	v.Set("cancel", "1")
+	resp, err := c.request(ctx, "MYLISTEXPORT", v)
+	if err != nil {
+		return fmt.Errorf("udpapi MylistExportCancel: %w", err)
+	}
+	if resp.Code != codes.EXPORT_CANCELLED {
+		return fmt.Errorf("udpapi MylistExportCancel: %w", codeError("MYLISTEXPORT", v, resp))
+	}
+	return nil
+}
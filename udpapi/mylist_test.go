@@ -0,0 +1,453 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.felesatra.moe/anidb/udpapi/codes"
+)
+
+func TestClient_MylistSetWatched(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		desc    string
+		resp    string
+		want    MylistEditResult
+		wantErr error
+	}{
+		{desc: "edited", resp: "311 MYLIST ENTRY EDITED\n1", want: MylistEdited},
+		{desc: "watched", resp: "313 WATCHED", want: MylistWatched},
+		{desc: "no entry", resp: "411 NO SUCH MYLIST ENTRY", wantErr: codes.ReturnCode(411)},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.desc, func(t *testing.T) {
+			t.Parallel()
+			ctx := testContext(t, time.Second)
+			s := newFakeServer(t, func(cmd string, args url.Values) string {
+				return c.resp
+			})
+			s.client.sessionKey.set("key")
+			got, err := s.client.MylistSetWatched(ctx, 1234, true)
+			if !errors.Is(err, c.wantErr) {
+				t.Fatalf("Got error %v; want %v", err, c.wantErr)
+			}
+			if got != c.want {
+				t.Errorf("Got %v; want %v", got, c.want)
+			}
+			args := s.requests()[0].args
+			if args.Get("edit") != "1" || args.Get("viewed") != "1" || args.Get("lid") != "1234" {
+				t.Errorf("Got args %v", args)
+			}
+		})
+	}
+}
+
+func TestClient_MylistEdit(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "311 MYLIST ENTRY EDITED\n1"
+	})
+	s.client.sessionKey.set("key")
+	viewed := true
+	got, err := s.client.MylistEdit(ctx, 1234, MylistAddOptions{
+		State:    MylistHDD,
+		Viewed:   &viewed,
+		ViewDate: time.Unix(1600000000, 0),
+		Storage:  "nas",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != MylistEdited {
+		t.Errorf("Got %v; want %v", got, MylistEdited)
+	}
+	r := s.requests()[0]
+	if r.cmd != "MYLISTADD" {
+		t.Errorf("Got cmd %q; want MYLISTADD", r.cmd)
+	}
+	want := url.Values{
+		"s":        {"key"},
+		"lid":      {"1234"},
+		"edit":     {"1"},
+		"state":    {"1"},
+		"viewed":   {"1"},
+		"viewdate": {"1600000000"},
+		"storage":  {"nas"},
+	}
+	delete(r.args, "tag")
+	if !reflect.DeepEqual(r.args, want) {
+		t.Errorf("Got args %v; want %v", r.args, want)
+	}
+}
+
+func TestClient_MylistEditByHash(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "411 NO SUCH MYLIST ENTRY"
+	})
+	s.client.sessionKey.set("key")
+	_, err := s.client.MylistEditByHash(ctx, 1234, "abcd", MylistAddOptions{Storage: "nas"})
+	if !errors.Is(err, codes.NO_SUCH_MYLIST_ENTRY) {
+		t.Errorf("Got error %v; want %v", err, codes.NO_SUCH_MYLIST_ENTRY)
+	}
+	args := s.requests()[0].args
+	if args.Get("edit") != "1" || args.Get("size") != "1234" || args.Get("ed2k") != "abcd" || args.Get("storage") != "nas" {
+		t.Errorf("Got args %v", args)
+	}
+}
+
+func TestClient_MylistAdd(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "210 MYLIST ENTRY ADDED\n5678"
+	})
+	s.client.sessionKey.set("key")
+	viewed := true
+	got, err := s.client.MylistAdd(ctx, 1234, MylistAddOptions{
+		State:    MylistHDD,
+		Viewed:   &viewed,
+		ViewDate: time.Unix(1600000000, 0),
+		Storage:  "nas",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (MylistAddResult{LID: 5678}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %#v; want %#v", got, want)
+	}
+	args := s.requests()[0].args
+	for k, want := range map[string]string{
+		"fid":      "1234",
+		"state":    "1",
+		"viewed":   "1",
+		"viewdate": "1600000000",
+		"storage":  "nas",
+	} {
+		if got := args.Get(k); got != want {
+			t.Errorf("Got %s=%q; want %q", k, got, want)
+		}
+	}
+	for _, k := range []string{"source", "other", "edit"} {
+		if args.Has(k) {
+			t.Errorf("Got unexpected arg %s", k)
+		}
+	}
+}
+
+func TestClient_MylistAddByHash_alreadyAdded(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "310 FILE ALREADY IN MYLIST\n5678|1234|213341|8076|7172|1600000000|1|0|nas|||0"
+	})
+	s.client.sessionKey.set("key")
+	got, err := s.client.MylistAddByHash(ctx, 1000, "abcdef", MylistAddOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := MylistAddResult{
+		LID:          5678,
+		AlreadyAdded: true,
+		Existing: MylistEntry{
+			LID:     5678,
+			FID:     1234,
+			EID:     213341,
+			AID:     8076,
+			GID:     7172,
+			Date:    time.Unix(1600000000, 0).UTC(),
+			State:   MylistHDD,
+			Storage: "nas",
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %#v; want %#v", got, want)
+	}
+	args := s.requests()[0].args
+	if args.Get("size") != "1000" || args.Get("ed2k") != "abcdef" {
+		t.Errorf("Got args %v", args)
+	}
+}
+
+func TestClient_MylistAddByAnime(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "210 MYLIST ENTRY ADDED\n12"
+	})
+	s.client.sessionKey.set("key")
+	got, err := s.client.MylistAddByAnime(ctx, FileQuery{AID: 8076, GName: "Commie", EpNo: EpNoUpTo(12)}, MylistAddOptions{State: MylistHDD})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 12 {
+		t.Errorf("Got %d; want 12", got)
+	}
+	args := s.requests()[0].args
+	for k, want := range map[string]string{
+		"aid":   "8076",
+		"gname": "Commie",
+		"epno":  "-12",
+		"state": "1",
+	} {
+		if got := args.Get(k); got != want {
+			t.Errorf("Got %s=%q; want %q", k, got, want)
+		}
+	}
+	if args.Has("generic") {
+		t.Errorf("Got unexpected arg generic")
+	}
+}
+
+func TestClient_MylistAddGeneric(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "210 MYLIST ENTRY ADDED\n1"
+	})
+	s.client.sessionKey.set("key")
+	got, err := s.client.MylistAddGeneric(ctx, FileQuery{AName: "BOFURI", GID: 7172, EpNo: EpNoAll}, MylistAddOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 1 {
+		t.Errorf("Got %d; want 1", got)
+	}
+	args := s.requests()[0].args
+	if args.Get("aname") != "BOFURI" || args.Get("epno") != "0" || args.Get("generic") != "1" {
+		t.Errorf("Got args %v", args)
+	}
+	if args.Has("gid") || args.Has("gname") {
+		t.Errorf("Got unexpected group args %v", args)
+	}
+}
+
+func TestClient_MylistByLID(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "221 MYLIST\n5678|1234|213341|8076|7172|1600000000|1|0|nas|||0"
+	})
+	s.client.sessionKey.set("key")
+	got, err := s.client.MylistByLID(ctx, 5678)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.LID != 5678 || got.FID != 1234 || got.State != MylistHDD {
+		t.Errorf("Got %#v", got)
+	}
+	if args := s.requests()[0].args; args.Get("lid") != "5678" {
+		t.Errorf("Got args %v", args)
+	}
+}
+
+func TestClient_MylistByFID_noSuchEntry(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "321 NO SUCH ENTRY"
+	})
+	s.client.sessionKey.set("key")
+	_, err := s.client.MylistByFID(ctx, 1234)
+	if !errors.Is(err, ErrNoSuchEntry) {
+		t.Errorf("Got error %v; want %v", err, ErrNoSuchEntry)
+	}
+	if !errors.Is(err, codes.NO_SUCH_ENTRY) {
+		t.Errorf("Got error %v; want %v", err, codes.NO_SUCH_ENTRY)
+	}
+}
+
+func TestClient_MylistByEpisode_multiple(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "312 MULTIPLE MYLIST ENTRIES\nSeitokai Yakuindomo|1-13||1-13|||1-5|Commie|1-13|HorribleSubs|1-2"
+	})
+	s.client.sessionKey.set("key")
+	got, err := s.client.MylistByEpisode(ctx, 8076, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := MylistResult{
+		Multiple: &MylistMultiEntry{
+			AnimeTitle:      "Seitokai Yakuindomo",
+			Episodes:        "1-13",
+			HDDEpisodes:     "1-13",
+			WatchedEpisodes: "1-5",
+			Groups: []MylistGroupEpisodes{
+				{ShortName: "Commie", Episodes: "1-13"},
+				{ShortName: "HorribleSubs", Episodes: "1-2"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %#v; want %#v", got, want)
+	}
+	args := s.requests()[0].args
+	if args.Get("aid") != "8076" || args.Has("epno") {
+		t.Errorf("Got args %v", args)
+	}
+}
+
+func TestClient_MylistByHash_multiple(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "312 MULTIPLE MYLIST ENTRIES\nSeitokai Yakuindomo|1-13||1-13|||1-5"
+	})
+	s.client.sessionKey.set("key")
+	if _, err := s.client.MylistByHash(ctx, 1000, "abcdef"); err == nil {
+		t.Errorf("Expected error")
+	}
+}
+
+func TestClient_MylistDelByHash(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "211 MYLIST ENTRY DELETED\n1"
+	})
+	s.client.sessionKey.set("key")
+	got, err := s.client.MylistDelByHash(ctx, 1000, "abcdef")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 1 {
+		t.Errorf("Got %d; want 1", got)
+	}
+	r := s.requests()[0]
+	if r.cmd != "MYLISTDEL" || r.args.Get("size") != "1000" || r.args.Get("ed2k") != "abcdef" {
+		t.Errorf("Got request %v", r)
+	}
+}
+
+func TestClient_MylistDel_noEntry(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "411 NO SUCH MYLIST ENTRY"
+	})
+	s.client.sessionKey.set("key")
+	if _, err := s.client.MylistDel(ctx, 5678); !errors.Is(err, codes.NO_SUCH_MYLIST_ENTRY) {
+		t.Errorf("Got error %v; want %v", err, codes.NO_SUCH_MYLIST_ENTRY)
+	}
+}
+
+func TestDecodeMylistStats(t *testing.T) {
+	t.Parallel()
+	got, err := decodeMylistStats([]string{
+		"120", "1500", "1600", "512000",
+		"10", "100", "110", "5",
+		"3", "20", "1", "2", "80",
+		"1200", "30", "2", "28800",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := MylistStats{
+		Anime:               120,
+		Episodes:            1500,
+		Files:               1600,
+		SizeMB:              512000,
+		AddedAnime:          10,
+		AddedEpisodes:       100,
+		AddedFiles:          110,
+		AddedGroups:         5,
+		LeechPercent:        3,
+		GloryPercent:        20,
+		ViewedPercent:       1,
+		MylistPercent:       2,
+		ViewedMylistPercent: 80,
+		ViewedEpisodes:      1200,
+		Votes:               30,
+		Reviews:             2,
+		ViewedMinutes:       28800,
+	}
+	if got != want {
+		t.Errorf("Got %#v; want %#v", got, want)
+	}
+	if _, err := decodeMylistStats([]string{"1"}); err == nil {
+		t.Errorf("Expected error")
+	}
+}
+
+func TestClient_MylistExport(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		desc    string
+		resp    string
+		wantErr error
+	}{
+		{desc: "queued", resp: "217 EXPORT QUEUED"},
+		{desc: "no template", resp: "317 EXPORT NO SUCH TEMPLATE", wantErr: codes.EXPORT_NO_SUCH_TEMPLATE},
+		{desc: "already queued", resp: "318 EXPORT ALREADY IN QUEUE", wantErr: codes.EXPORT_ALREADY_IN_QUEUE},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.desc, func(t *testing.T) {
+			t.Parallel()
+			ctx := testContext(t, time.Second)
+			s := newFakeServer(t, func(cmd string, args url.Values) string {
+				return c.resp
+			})
+			s.client.sessionKey.set("key")
+			err := s.client.MylistExport(ctx, "xml-plain-cs")
+			if !errors.Is(err, c.wantErr) {
+				t.Errorf("Got error %v; want %v", err, c.wantErr)
+			}
+			if got := s.requests()[0].args.Get("template"); got != "xml-plain-cs" {
+				t.Errorf("Got template %q; want %q", got, "xml-plain-cs")
+			}
+		})
+	}
+}
+
+func TestClient_MylistExportCancel(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		desc    string
+		resp    string
+		wantErr error
+	}{
+		{desc: "cancelled", resp: "218 EXPORT CANCELLED"},
+		{desc: "none queued", resp: "319 EXPORT NO EXPORT QUEUED OR IS PROCESSING", wantErr: codes.EXPORT_NO_EXPORT_QUEUED_OR_IS_PROCESSING},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.desc, func(t *testing.T) {
+			t.Parallel()
+			ctx := testContext(t, time.Second)
+			s := newFakeServer(t, func(cmd string, args url.Values) string {
+				return c.resp
+			})
+			s.client.sessionKey.set("key")
+			err := s.client.MylistExportCancel(ctx)
+			if !errors.Is(err, c.wantErr) {
+				t.Errorf("Got error %v; want %v", err, c.wantErr)
+			}
+			if got := s.requests()[0].args.Get("cancel"); got != "1" {
+				t.Errorf("Got cancel %q; want 1", got)
+			}
+		})
+	}
+}
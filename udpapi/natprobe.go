@@ -0,0 +1,123 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DefaultNATProbeDuration is the maximum duration of [NATProbe] if
+// the context has no deadline.
+const DefaultNATProbeDuration = 30 * time.Minute
+
+// A NATProbeResult is the result of [NATProbe].
+type NATProbeResult struct {
+	// NAT is true if the client is behind NAT, as the port
+	// returned by PING differs from the local port.
+	NAT bool
+	// Port is the external port returned by the last PING.
+	Port string
+	// Timeout is the longest ping interval that kept the NAT
+	// mapping alive.
+	// This is zero if NAT is false.
+	Timeout time.Duration
+	// TimeoutHit is set if the NAT mapping expired during the
+	// probe, in which case the NAT timeout is between Timeout and
+	// the next interval.
+	// Otherwise, Timeout is only a lower bound on the NAT timeout.
+	TimeoutHit bool
+}
+
+// NATProbe probes the NAT between the client and the server with
+// PING, using the same adaptive interval as [KeepAlive], configured
+// by [Client.KeepAliveConfig].
+// The ping interval is increased until the external port changes or
+// the maximum interval is reached.
+// The probe ends early with the results so far when ctx is done;
+// if ctx has no deadline, the probe runs for at most
+// [DefaultNATProbeDuration].
+//
+// The probe can take many minutes, and other requests made by the
+// client during the probe, including by a [KeepAlive], refresh the
+// NAT mapping and skew the result.
+// PING does not need a session.
+func NATProbe(ctx context.Context, c *Client) (NATProbeResult, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cf context.CancelFunc
+		ctx, cf = context.WithTimeout(ctx, DefaultNATProbeDuration)
+		defer cf()
+	}
+	r, err := natProbe(ctx, c, sleepBefore)
+	if err != nil {
+		return r, fmt.Errorf("udpapi NATProbe: %w", err)
+	}
+	return r, nil
+}
+
+// natProbe implements [NATProbe], using sleep to wait between pings.
+func natProbe(ctx context.Context, c *Client, sleep func(context.Context, time.Duration) error) (NATProbeResult, error) {
+	cfg := c.KeepAliveConfig.withDefaults()
+	port, err := cfg.Probe(ctx, c)
+	if err != nil {
+		return NATProbeResult{}, err
+	}
+	r := NATProbeResult{Port: port, NAT: port != c.LocalPort()}
+	if !r.NAT {
+		return r, nil
+	}
+	s := KeepAliveState{Interval: cfg.Initial, Port: port}
+	for {
+		d := s.Interval
+		if err := sleep(ctx, d); err != nil {
+			return r, nil
+		}
+		port, err := cfg.Probe(ctx, c)
+		if err != nil {
+			if ctx.Err() != nil {
+				return r, nil
+			}
+			return r, err
+		}
+		r.Port = port
+		if s.observe(port, cfg) {
+			r.TimeoutHit = true
+			return r, nil
+		}
+		r.Timeout = d
+		if s.Interval == d {
+			// Reached the maximum interval.
+			return r, nil
+		}
+	}
+}
+
+// sleepBefore sleeps for d.
+// If ctx would be done before then, it returns the context error
+// without sleeping.
+func sleepBefore(ctx context.Context, d time.Duration) error {
+	if dl, ok := ctx.Deadline(); ok && time.Until(dl) < d {
+		return context.DeadlineExceeded
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"context"
+	"net/url"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNATProbe(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	ports := []string{"1000", "1000", "1000", "2000"}
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		p := ports[0]
+		ports = ports[1:]
+		return "300 PONG\n" + p
+	})
+	var slept []time.Duration
+	sleep := func(ctx context.Context, d time.Duration) error {
+		slept = append(slept, d)
+		return nil
+	}
+	got, err := natProbe(ctx, s.client, sleep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := NATProbeResult{
+		NAT:        true,
+		Port:       "2000",
+		Timeout:    keepAliveMin + keepAliveStep,
+		TimeoutHit: true,
+	}
+	if got != want {
+		t.Errorf("Got %+v; want %+v", got, want)
+	}
+	wantSlept := []time.Duration{keepAliveMin, keepAliveMin + keepAliveStep, keepAliveMin + 2*keepAliveStep}
+	if !reflect.DeepEqual(slept, wantSlept) {
+		t.Errorf("Got sleeps %v; want %v", slept, wantSlept)
+	}
+}
+
+func TestNATProbe_noNAT(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	// The local port is only known after the server starts.
+	var local atomic.Value
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "300 PONG\n" + local.Load().(string)
+	})
+	local.Store(s.client.LocalPort())
+	got, err := NATProbe(ctx, s.client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := NATProbeResult{Port: s.client.LocalPort()}
+	if got != want {
+		t.Errorf("Got %+v; want %+v", got, want)
+	}
+	if n := len(s.requests()); n != 1 {
+		t.Errorf("Got %d requests; want 1", n)
+	}
+}
+
+func TestNATProbe_deadline(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "300 PONG\n1000"
+	})
+	// The deadline is before the first interval, so the probe
+	// ends without waiting.
+	got, err := NATProbe(ctx, s.client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := NATProbeResult{NAT: true, Port: "1000"}
+	if got != want {
+		t.Errorf("Got %+v; want %+v", got, want)
+	}
+}
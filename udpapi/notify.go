@@ -0,0 +1,72 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// A NewFileNotification is a decoded NOTIFICATION - NEW FILE push
+// packet (code 720).
+type NewFileNotification struct {
+	// PacketID is the notify packet ID, used for PUSHACK.
+	PacketID int
+	FID      int
+	// Type is the type of the notification, which determines what
+	// RelatedID refers to.
+	Type NewFileType
+	// RelatedID is the AID or GID that the notification is for.
+	RelatedID int
+}
+
+// A NewFileType is the type of a [NewFileNotification].
+type NewFileType int
+
+const (
+	NewFileAnime      NewFileType = 0
+	NewFileGroup      NewFileType = 1
+	NewFileGroupAnime NewFileType = 2
+)
+
+// DecodeNewFileNotification decodes a NOTIFICATION - NEW FILE push
+// packet.
+func DecodeNewFileNotification(r Response) (NewFileNotification, error) {
+	var n NewFileNotification
+	if r.Code != 720 {
+		return n, fmt.Errorf("decode new file notification: got bad return code %s", r.Code)
+	}
+	h, err := ParseNotificationHeader(r)
+	if err != nil {
+		return n, fmt.Errorf("decode new file notification: %s", err)
+	}
+	n.PacketID = h.PacketID
+	if len(r.Rows) != 1 {
+		return n, fmt.Errorf("decode new file notification: got unexpected number of rows %d", len(r.Rows))
+	}
+	row := r.Rows[0]
+	if len(row) != 3 {
+		return n, fmt.Errorf("decode new file notification: got unexpected number of fields %d", len(row))
+	}
+	var typ int
+	for i, p := range []*int{&n.FID, &typ, &n.RelatedID} {
+		*p, err = strconv.Atoi(row[i])
+		if err != nil {
+			return n, fmt.Errorf("decode new file notification: %s", err)
+		}
+	}
+	n.Type = NewFileType(typ)
+	return n, nil
+}
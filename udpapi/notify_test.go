@@ -0,0 +1,48 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import "testing"
+
+func TestDecodeNewFileNotification(t *testing.T) {
+	t.Parallel()
+	const data = `720 1234 NOTIFICATION - NEW FILE
+1234|1|34`
+	r, err := parseResponse([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := DecodeNewFileNotification(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := NewFileNotification{
+		PacketID:  1234,
+		FID:       1234,
+		Type:      NewFileGroup,
+		RelatedID: 34,
+	}
+	if got != want {
+		t.Errorf("Got %#v; want %#v", got, want)
+	}
+}
+
+func TestDecodeNewFileNotification_badCode(t *testing.T) {
+	t.Parallel()
+	r := Response{Code: 300, Header: "PONG"}
+	if _, err := DecodeNewFileNotification(r); err == nil {
+		t.Errorf("Expected error")
+	}
+}
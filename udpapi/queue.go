@@ -0,0 +1,167 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// A Priority is the priority of a request waiting for the rate
+// limiter of a [Client].
+// See [WithPriority].
+type Priority int
+
+const (
+	// PriorityInteractive is for requests a user is waiting on.
+	// This is the default.
+	PriorityInteractive Priority = iota
+	// PriorityBatch is for bulk requests, such as mylist imports.
+	// This is the default for requests made in [Batch].
+	PriorityBatch
+
+	numPriorities = iota
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityInteractive:
+		return "interactive"
+	case PriorityBatch:
+		return "batch"
+	default:
+		return fmt.Sprintf("Priority(%d)", int(p))
+	}
+}
+
+type priorityKey struct{}
+
+// WithPriority returns a context for making requests with the given
+// priority.
+//
+// Requests waiting for the rate limiter of a [Client] are queued by
+// priority, so interactive requests are not starved by bulk
+// requests.
+// Interactive requests go first, but a batch request is let through
+// after every [InteractiveBurst] interactive requests so batches
+// still make progress.
+// Requests with the same priority are sent in the order they were
+// made.
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// priorityFromContext returns the priority of a request.
+func priorityFromContext(ctx context.Context) (Priority, bool) {
+	p, ok := ctx.Value(priorityKey{}).(Priority)
+	if !ok || p < 0 || p >= numPriorities {
+		return PriorityInteractive, ok
+	}
+	return p, true
+}
+
+// InteractiveBurst is the number of interactive requests let through
+// the rate limiter ahead of a waiting batch request.
+const InteractiveBurst = 4
+
+// A requestQueue orders requests waiting for a rate limiter by
+// priority.
+// Only one request waits on the limiter at a time.
+// The zero value is ready to use.
+type requestQueue struct {
+	mu sync.Mutex
+	// busy is set while a request holds the turn to wait on the
+	// limiter.
+	busy   bool
+	queues [numPriorities][]*queueWaiter
+	// streak counts consecutive interactive turns while batch
+	// requests are waiting.
+	streak int
+}
+
+type queueWaiter struct {
+	// ready is closed when the waiter is given the turn.
+	ready chan struct{}
+}
+
+// wait waits for the turn for priority p, then waits on the limiter.
+func (q *requestQueue) wait(ctx context.Context, l Limiter, p Priority) error {
+	if err := q.acquire(ctx, p); err != nil {
+		return err
+	}
+	defer q.release()
+	return l.Wait(ctx)
+}
+
+// acquire waits for the turn to wait on the limiter.
+func (q *requestQueue) acquire(ctx context.Context, p Priority) error {
+	q.mu.Lock()
+	if !q.busy {
+		q.busy = true
+		q.mu.Unlock()
+		return nil
+	}
+	w := &queueWaiter{ready: make(chan struct{})}
+	q.queues[p] = append(q.queues[p], w)
+	q.mu.Unlock()
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+	}
+	q.mu.Lock()
+	select {
+	case <-w.ready:
+		// Given the turn while canceling, so pass it on.
+		q.mu.Unlock()
+		q.release()
+		return ctx.Err()
+	default:
+	}
+	for i, w2 := range q.queues[p] {
+		if w2 == w {
+			q.queues[p] = append(q.queues[p][:i], q.queues[p][i+1:]...)
+			break
+		}
+	}
+	q.mu.Unlock()
+	return ctx.Err()
+}
+
+// release gives the turn to the next waiter, if any.
+func (q *requestQueue) release() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	interactive := q.queues[PriorityInteractive]
+	batch := q.queues[PriorityBatch]
+	var next *queueWaiter
+	switch {
+	case len(interactive) > 0 && (len(batch) == 0 || q.streak < InteractiveBurst):
+		next = interactive[0]
+		q.queues[PriorityInteractive] = interactive[1:]
+		if len(batch) > 0 {
+			q.streak++
+		}
+	case len(batch) > 0:
+		next = batch[0]
+		q.queues[PriorityBatch] = batch[1:]
+		q.streak = 0
+	default:
+		q.busy = false
+		return
+	}
+	close(next.ready)
+}
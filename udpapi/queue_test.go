@@ -0,0 +1,148 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type labelKey struct{}
+
+// A recordLimiter records the labels of requests passing through.
+type recordLimiter struct {
+	mu     sync.Mutex
+	labels []string
+}
+
+func (l *recordLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.labels = append(l.labels, ctx.Value(labelKey{}).(string))
+	return nil
+}
+
+// queued returns the number of waiters in the queue.
+func (q *requestQueue) queued() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	n := 0
+	for _, ws := range q.queues {
+		n += len(ws)
+	}
+	return n
+}
+
+// waitQueued waits until the queue has n waiters.
+func waitQueued(t *testing.T, q *requestQueue, n int) {
+	t.Helper()
+	for i := 0; q.queued() != n; i++ {
+		if i > 1000 {
+			t.Fatalf("Timed out waiting for %d queued requests", n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestRequestQueue(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	var q requestQueue
+	l := &recordLimiter{}
+	// Hold the turn while requests are queued.
+	if err := q.acquire(ctx, PriorityInteractive); err != nil {
+		t.Fatal(err)
+	}
+	reqs := []struct {
+		label string
+		p     Priority
+	}{
+		{"b1", PriorityBatch}, {"b2", PriorityBatch}, {"b3", PriorityBatch},
+		{"i1", PriorityInteractive}, {"i2", PriorityInteractive},
+		{"i3", PriorityInteractive}, {"i4", PriorityInteractive},
+		{"i5", PriorityInteractive}, {"i6", PriorityInteractive},
+	}
+	var wg sync.WaitGroup
+	for i, r := range reqs {
+		r := r
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx := context.WithValue(ctx, labelKey{}, r.label)
+			if err := q.wait(ctx, l, r.p); err != nil {
+				t.Error(err)
+			}
+		}()
+		waitQueued(t, &q, i+1)
+	}
+	q.release()
+	wg.Wait()
+	want := []string{"i1", "i2", "i3", "i4", "b1", "i5", "i6", "b2", "b3"}
+	if !reflect.DeepEqual(l.labels, want) {
+		t.Errorf("Got %v; want %v", l.labels, want)
+	}
+	if q.busy {
+		t.Errorf("Queue still busy after all requests")
+	}
+}
+
+func TestRequestQueue_cancel(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	var q requestQueue
+	if err := q.acquire(ctx, PriorityInteractive); err != nil {
+		t.Fatal(err)
+	}
+	cctx, cancel := context.WithCancel(ctx)
+	errc := make(chan error)
+	go func() {
+		errc <- q.wait(cctx, &recordLimiter{}, PriorityBatch)
+	}()
+	waitQueued(t, &q, 1)
+	cancel()
+	if err := <-errc; !errors.Is(err, context.Canceled) {
+		t.Errorf("Got error %v; want %v", err, context.Canceled)
+	}
+	if n := q.queued(); n != 0 {
+		t.Errorf("Got %d queued requests after cancel; want 0", n)
+	}
+	q.release()
+	if q.busy {
+		t.Errorf("Queue still busy after release")
+	}
+}
+
+func TestBatch_priority(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	var got []Priority
+	Batch(ctx, []int{1}, func(ctx context.Context, _ int) (int, error) {
+		p, _ := priorityFromContext(ctx)
+		got = append(got, p)
+		return 0, nil
+	})
+	Batch(WithPriority(ctx, PriorityInteractive), []int{1}, func(ctx context.Context, _ int) (int, error) {
+		p, _ := priorityFromContext(ctx)
+		got = append(got, p)
+		return 0, nil
+	})
+	if want := []Priority{PriorityBatch, PriorityInteractive}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v; want %v", got, want)
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"go.felesatra.moe/anidb/udpapi/codes"
+)
+
+// ErrEmpty is returned by the random commands when the server has
+// nothing to return.
+// The returned error also wraps the _EMPTY [codes.ReturnCode].
+var ErrEmpty = errors.New("empty result")
+
+// A RandomAnimeType selects where RANDOMANIME picks an anime from.
+type RandomAnimeType int
+
+const (
+	RandomFromDB        RandomAnimeType = 0
+	RandomFromWatched   RandomAnimeType = 1
+	RandomFromUnwatched RandomAnimeType = 2
+	RandomFromMylist    RandomAnimeType = 3
+)
+
+// RandomAnime calls the RANDOMANIME command.
+// It returns the aid of the random anime; see [Client.AnimeByAID] for
+// more information.
+// The returned error wraps a [codes.ReturnCode] if applicable.
+func (c *Client) RandomAnime(ctx context.Context, t RandomAnimeType) (aid int, _ error) {
+	v, err := c.sessionValues(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("udpapi RandomAnime: %w", err)
+	}
+	v.Set("type", strconv.Itoa(int(t)))
+	row, err := c.randomRow(ctx, "RANDOMANIME", v, codes.RANDOM_ANIME, codes.NO_SUCH_ANIME)
+	if err != nil {
+		return 0, fmt.Errorf("udpapi RandomAnime: %w", err)
+	}
+	aid, err = strconv.Atoi(row[0])
+	if err != nil {
+		return 0, fmt.Errorf("udpapi RandomAnime: %s", err)
+	}
+	return aid, nil
+}
+
+// A Recommendation is a result of the RANDOMRECOMMENDATION command.
+type Recommendation struct {
+	AID int
+	// Fields are the remaining fields of the response row.
+	Fields []string
+}
+
+// RandomRecommendation calls the RANDOMRECOMMENDATION command.
+// If there is no recommendation, the returned error wraps [ErrEmpty].
+// The returned error wraps a [codes.ReturnCode] if applicable.
+func (c *Client) RandomRecommendation(ctx context.Context) (Recommendation, error) {
+	v, err := c.sessionValues(ctx)
+	if err != nil {
+		return Recommendation{}, fmt.Errorf("udpapi RandomRecommendation: %w", err)
+	}
+	row, err := c.randomRow(ctx, "RANDOMRECOMMENDATION", v, codes.RANDOM_RECOMMENDATION, codes.RANDOM_RECOMMENDATION_EMPTY)
+	if err != nil {
+		return Recommendation{}, fmt.Errorf("udpapi RandomRecommendation: %w", err)
+	}
+	aid, err := strconv.Atoi(row[0])
+	if err != nil {
+		return Recommendation{}, fmt.Errorf("udpapi RandomRecommendation: %s", err)
+	}
+	return Recommendation{AID: aid, Fields: row[1:]}, nil
+}
+
+// A SimilarAnime is a result of the RANDOMSIMILAR command.
+type SimilarAnime struct {
+	SourceAID     int
+	SourcePicname string
+	AID           int
+	Picname       string
+}
+
+// RandomSimilar calls the RANDOMSIMILAR command.
+// If there is no similar anime, the returned error wraps [ErrEmpty].
+// The returned error wraps a [codes.ReturnCode] if applicable.
+func (c *Client) RandomSimilar(ctx context.Context) (SimilarAnime, error) {
+	v, err := c.sessionValues(ctx)
+	if err != nil {
+		return SimilarAnime{}, fmt.Errorf("udpapi RandomSimilar: %w", err)
+	}
+	row, err := c.randomRow(ctx, "RANDOMSIMILAR", v, codes.RANDOM_SIMILAR, codes.RANDOM_SIMILAR_EMPTY)
+	if err != nil {
+		return SimilarAnime{}, fmt.Errorf("udpapi RandomSimilar: %w", err)
+	}
+	if n := len(row); n < 4 {
+		return SimilarAnime{}, fmt.Errorf("udpapi RandomSimilar: got unexpected number of fields %d", n)
+	}
+	var s SimilarAnime
+	if s.SourceAID, err = strconv.Atoi(row[0]); err != nil {
+		return SimilarAnime{}, fmt.Errorf("udpapi RandomSimilar: %s", err)
+	}
+	if s.AID, err = strconv.Atoi(row[2]); err != nil {
+		return SimilarAnime{}, fmt.Errorf("udpapi RandomSimilar: %s", err)
+	}
+	s.SourcePicname = row[1]
+	s.Picname = row[3]
+	return s, nil
+}
+
+// randomRow sends a random command and returns the single response
+// row.
+// empty is the return code for an empty result, which is returned as
+// [ErrEmpty].
+func (c *Client) randomRow(ctx context.Context, cmd string, v url.Values, want, empty codes.ReturnCode) ([]string, error) {
+	resp, err := c.request(ctx, cmd, v)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.Code {
+	case want:
+	case empty:
+		return nil, responseError(cmd, v, resp, fmt.Errorf("%w (%w)", ErrEmpty, resp.Code))
+	default:
+		return nil, codeError(cmd, v, resp)
+	}
+	if len(resp.Rows) != 1 || len(resp.Rows[0]) == 0 {
+		return nil, fmt.Errorf("unexpected response rows %q", resp.Rows)
+	}
+	return resp.Rows[0], nil
+}
+
+// An UpdatedEntity is an entity type for the UPDATED command.
+type UpdatedEntity int
+
+const (
+	UpdatedAnime UpdatedEntity = 1
+)
+
+// An Updates is the result of the UPDATED command.
+type Updates struct {
+	Entity UpdatedEntity
+	// Total is the total number of updated entities, which may be
+	// more than the number of IDs returned.
+	Total int
+	// LastUpdate is the time of the last update.
+	LastUpdate time.Time
+	IDs        []int
+}
+
+// Updated calls the UPDATED command for entities updated since the
+// given time.
+// If there are no updates, an empty Updates is returned without error.
+// The returned error wraps a [codes.ReturnCode] if applicable.
+func (c *Client) Updated(ctx context.Context, e UpdatedEntity, since time.Time) (Updates, error) {
+	v, err := c.sessionValues(ctx)
+	if err != nil {
+		return Updates{}, fmt.Errorf("udpapi Updated: %w", err)
+	}
+	v.Set("entity", strconv.Itoa(int(e)))
+	v.Set("time", strconv.FormatInt(since.Unix(), 10))
+	resp, err := c.request(ctx, "UPDATED", v)
+	if err != nil {
+		return Updates{}, fmt.Errorf("udpapi Updated: %w", err)
+	}
+	switch resp.Code {
+	case codes.UPDATED:
+	case codes.NO_SUCH_UPDATES:
+		return Updates{Entity: e}, nil
+	default:
+		return Updates{}, fmt.Errorf("udpapi Updated: %w", codeError("UPDATED", v, resp))
+	}
+	if len(resp.Rows) != 1 {
+		return Updates{}, fmt.Errorf("udpapi Updated: got unexpected number of rows %d", len(resp.Rows))
+	}
+	u, err := decodeUpdates(resp.Rows[0])
+	if err != nil {
+		return Updates{}, fmt.Errorf("udpapi Updated: %w", err)
+	}
+	return u, nil
+}
+
+// decodeUpdates decodes an UPDATED response row.
+func decodeUpdates(row []string) (Updates, error) {
+	var u Updates
+	if n := len(row); n != 4 {
+		return u, fmt.Errorf("decode updates: got unexpected number of fields %d", n)
+	}
+	e, err := strconv.Atoi(row[0])
+	if err != nil {
+		return u, fmt.Errorf("decode updates: %s", err)
+	}
+	u.Entity = UpdatedEntity(e)
+	if u.Total, err = strconv.Atoi(row[1]); err != nil {
+		return u, fmt.Errorf("decode updates: %s", err)
+	}
+	if u.LastUpdate, err = parseUnixTime(row[2]); err != nil {
+		return u, fmt.Errorf("decode updates: %s", err)
+	}
+	if u.IDs, err = parseIntList(row[3], ","); err != nil {
+		return u, fmt.Errorf("decode updates: %s", err)
+	}
+	return u, nil
+}
@@ -0,0 +1,118 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.felesatra.moe/anidb/udpapi/codes"
+)
+
+func TestClient_RandomAnime(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "232 RANDOMANIME\n8076|2010|TV Series"
+	})
+	s.client.sessionKey.set("key")
+	got, err := s.client.RandomAnime(ctx, RandomFromUnwatched)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 8076 {
+		t.Errorf("Got %d; want 8076", got)
+	}
+	if got := s.requests()[0].args.Get("type"); got != "2" {
+		t.Errorf("Got type %q; want 2", got)
+	}
+}
+
+func TestClient_RandomSimilar(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "267 RANDOMSIMILAR\n8076|a.jpg|9000|b.jpg"
+	})
+	s.client.sessionKey.set("key")
+	got, err := s.client.RandomSimilar(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := SimilarAnime{SourceAID: 8076, SourcePicname: "a.jpg", AID: 9000, Picname: "b.jpg"}
+	if got != want {
+		t.Errorf("Got %#v; want %#v", got, want)
+	}
+}
+
+func TestClient_RandomRecommendation_empty(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "366 RANDOMRECOMMENDATION EMPTY"
+	})
+	s.client.sessionKey.set("key")
+	_, err := s.client.RandomRecommendation(ctx)
+	if !errors.Is(err, ErrEmpty) {
+		t.Errorf("Got error %v; want %v", err, ErrEmpty)
+	}
+	if !errors.Is(err, codes.RANDOM_RECOMMENDATION_EMPTY) {
+		t.Errorf("Got error %v; want %v", err, codes.RANDOM_RECOMMENDATION_EMPTY)
+	}
+}
+
+func TestClient_Updated(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "243 UPDATED\n1|3|1700000000|8076,9000,9001"
+	})
+	s.client.sessionKey.set("key")
+	got, err := s.client.Updated(ctx, UpdatedAnime, time.Unix(1600000000, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Updates{
+		Entity:     UpdatedAnime,
+		Total:      3,
+		LastUpdate: time.Unix(1700000000, 0).UTC(),
+		IDs:        []int{8076, 9000, 9001},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %#v; want %#v", got, want)
+	}
+	if got := s.requests()[0].args.Get("time"); got != "1600000000" {
+		t.Errorf("Got time %q; want 1600000000", got)
+	}
+}
+
+func TestClient_Updated_none(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "343 NO UPDATES"
+	})
+	s.client.sessionKey.set("key")
+	got, err := s.client.Updated(ctx, UpdatedAnime, time.Unix(1600000000, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.IDs) != 0 {
+		t.Errorf("Got %v; want no updates", got)
+	}
+}
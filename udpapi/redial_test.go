@@ -0,0 +1,152 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"go.felesatra.moe/anidb/udpapi/udptest"
+)
+
+func newRedialTestServer(t *testing.T) *udptest.Server {
+	t.Helper()
+	s, err := udptest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	s.AddUser("ionasal", "pass")
+	return s
+}
+
+func TestClient_RedialAfter(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, 5*time.Second)
+	s1 := newRedialTestServer(t)
+	s2 := newRedialTestServer(t)
+	c, err := Dial(s1.Addr, nullLogger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	c.SetLimiter(&limiter{
+		short: rate.NewLimiter(rate.Inf, 1),
+		long:  rate.NewLimiter(rate.Inf, 1),
+	})
+	c.SetTimeout(100 * time.Millisecond)
+	c.RedialAfter = 1
+	c.FailoverAddrs = []string{s2.Addr}
+	if _, err := c.Auth(ctx, UserInfo{UserName: "ionasal", UserPassword: "pass"}); err != nil {
+		t.Fatal(err)
+	}
+	s1.Close()
+	if err := c.PingSimple(ctx); err == nil {
+		t.Fatal("Expected error for closed server")
+	}
+	if got, want := c.ServerAddr().String(), s2.Addr; got != want {
+		t.Errorf("Got server %s; want %s", got, want)
+	}
+	if err := c.PingSimple(ctx); err != nil {
+		t.Fatal(err)
+	}
+	var auth bool
+	for _, r := range s2.Requests() {
+		if r.Cmd == "AUTH" {
+			auth = true
+		}
+	}
+	if !auth {
+		t.Errorf("Got no AUTH after failover; want session re-established")
+	}
+}
+
+func TestClient_Redial(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, 5*time.Second)
+	s := newRedialTestServer(t)
+	c, err := Dial(s.Addr, nullLogger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	c.SetLimiter(&limiter{
+		short: rate.NewLimiter(rate.Inf, 1),
+		long:  rate.NewLimiter(rate.Inf, 1),
+	})
+	port := c.LocalPort()
+	if err := c.Redial(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if c.LocalPort() == port {
+		t.Errorf("Got same local port %s after redial", port)
+	}
+	if err := c.PingSimple(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClient_Redial_fixedPortFailure(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, 5*time.Second)
+	s1 := newRedialTestServer(t)
+	s2 := newRedialTestServer(t)
+	pc, err := net.ListenPacket("udp", "127.0.0.1:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	laddr := pc.LocalAddr().(*net.UDPAddr)
+	pc.Close()
+	var failRestore atomic.Bool
+	cfg := DialConfig{
+		LocalAddr: laddr,
+		DialUDP: func(ctx context.Context, network string, laddr, raddr *net.UDPAddr) (*net.UDPConn, error) {
+			if raddr.String() == s2.Addr || failRestore.Load() {
+				return nil, errors.New("network unreachable")
+			}
+			return net.DialUDP(network, laddr, raddr)
+		},
+	}
+	c, err := cfg.Dial(ctx, s1.Addr, nullLogger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	c.SetLimiter(&limiter{
+		short: rate.NewLimiter(rate.Inf, 1),
+		long:  rate.NewLimiter(rate.Inf, 1),
+	})
+	c.FailoverAddrs = []string{s2.Addr}
+	err = c.Redial(ctx)
+	if err == nil || errors.Is(err, ErrConnLost) {
+		t.Fatalf("Got error %v; want error not wrapping ErrConnLost", err)
+	}
+	if got, want := c.ServerAddr().String(), s1.Addr; got != want {
+		t.Errorf("Got server %s; want %s", got, want)
+	}
+	if err := c.PingSimple(ctx); err != nil {
+		t.Fatalf("Connection not restored: %s", err)
+	}
+	failRestore.Store(true)
+	if err := c.Redial(ctx); !errors.Is(err, ErrConnLost) {
+		t.Errorf("Got error %v; want ErrConnLost", err)
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"go.felesatra.moe/anidb/udpapi/codes"
+)
+
+// A RetryPolicy configures automatic retries for [Client] requests.
+// The zero value does not retry.
+//
+// Retries are rate limited like any other request.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts for a request,
+	// including the first.
+	// If less than 2, requests are not retried.
+	MaxAttempts int
+	// Base is the delay before the first retry.
+	// Later retries back off exponentially.
+	Base time.Duration
+	// Cap is the maximum delay between retries.
+	// If zero, there is no maximum.
+	Cap time.Duration
+	// Codes are the return codes to retry.
+	Codes []codes.ReturnCode
+	// Timeout enables retrying requests that time out, which
+	// usually means a UDP packet was lost.
+	// As the server may have received the request and only the
+	// response was lost, only requests for commands that are safe
+	// to send twice are retried (see [Idempotent]), plus any in
+	// TimeoutCommands.
+	Timeout bool
+	// TimeoutCommands are additional commands to retry on timeout
+	// if Timeout is set.
+	// Only add commands where sending twice is acceptable; for
+	// example, a retried AUTH creates a second session.
+	TimeoutCommands []string
+}
+
+// DefaultRetryPolicy returns a reasonable RetryPolicy.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		Base:        5 * time.Second,
+		Cap:         30 * time.Second,
+		Codes:       []codes.ReturnCode{codes.SERVER_BUSY, codes.TIMEOUT},
+		Timeout:     true,
+	}
+}
+
+// idempotentCommands are the commands that are safe to send twice.
+var idempotentCommands = map[string]bool{
+	"ANIME":                true,
+	"ANIMEDESC":            true,
+	"BUDDYLIST":            true,
+	"CALENDAR":             true,
+	"CHARACTER":            true,
+	"CREATOR":              true,
+	"ENCODING":             true,
+	"EPISODE":              true,
+	"FILE":                 true,
+	"GROUP":                true,
+	"GROUPSTATUS":          true,
+	"MYLIST":               true,
+	"MYLISTSTATS":          true,
+	"NOTIFY":               true,
+	"NOTIFYGET":            true,
+	"NOTIFYLIST":           true,
+	"PING":                 true,
+	"RANDOMANIME":          true,
+	"RANDOMRECOMMENDATION": true,
+	"RANDOMSIMILAR":        true,
+	"TOP":                  true,
+	"UPDATED":              true,
+	"UPTIME":               true,
+	"USER":                 true,
+	"VERSION":              true,
+}
+
+// Idempotent returns true if the command only reads data, so it is
+// safe to send twice, such as when retrying after a timeout.
+// Commands that change state, like AUTH, ENCRYPT, MYLISTADD,
+// MYLISTDEL and VOTE, are not idempotent.
+func Idempotent(cmd string) bool {
+	return idempotentCommands[cmd]
+}
+
+func (p RetryPolicy) backoff() backoff {
+	return backoff{
+		base:        p.Base,
+		cap:         p.Cap,
+		maxAttempts: p.MaxAttempts - 1,
+		jitter:      0.2,
+	}
+}
+
+// shouldRetry returns true if a request should be retried.
+// ctx is the request context.
+// attempt is the number of attempts so far.
+func (p RetryPolicy) shouldRetry(ctx context.Context, cmd string, attempt int, resp Response, err error) bool {
+	if attempt >= p.MaxAttempts || ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return p.Timeout && errors.Is(err, context.DeadlineExceeded) && p.retryTimeout(cmd)
+	}
+	return slices.Contains(p.Codes, resp.Code)
+}
+
+// retryTimeout returns true if timed out requests for the command
+// may be retried.
+func (p RetryPolicy) retryTimeout(cmd string) bool {
+	return Idempotent(cmd) || slices.Contains(p.TimeoutCommands, cmd)
+}
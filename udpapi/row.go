@@ -0,0 +1,306 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DecodeRow decodes a response row into the struct pointed to by v.
+//
+// Struct fields are mapped to row fields using anidb struct tags
+// containing the index of the row field:
+//
+//	type Episode struct {
+//		EID   int    `anidb:"0"`
+//		EpNo  string `anidb:"5"`
+//		Title string `anidb:"6"`
+//	}
+//
+// Fields without a tag are ignored.
+// The tag may be followed by ",comma" to decode lists separated by
+// commas instead of apostrophes.
+//
+// The following field types are supported:
+//
+//   - string
+//   - signed and unsigned integers; an empty field is decoded as zero
+//   - float32 and float64
+//   - bool; "1" is decoded as true
+//   - [time.Time] from a Unix timestamp; zero is the zero time
+//   - [time.Duration] from a number of seconds
+//   - slices of the above, from lists
+//   - types implementing [encoding.TextUnmarshaler]
+//
+// The row should be taken from [Response.RawRows].
+// Fields are unescaped with [UnescapeField], and lists are split
+// before their items are unescaped, so escaped apostrophes in list
+// items are not mistaken for separators.
+func DecodeRow(row []string, v any) error {
+	fields, err := rowFields(v)
+	if err != nil {
+		return fmt.Errorf("udpapi DecodeRow: %w", err)
+	}
+	for _, f := range fields {
+		i, err := strconv.Atoi(f.key)
+		if err != nil {
+			return fmt.Errorf("udpapi DecodeRow: field %s: invalid index %q", f.name, f.key)
+		}
+		if i < 0 || i >= len(row) {
+			return fmt.Errorf("udpapi DecodeRow: field %s: index %d out of range for %d fields", f.name, i, len(row))
+		}
+		if err := f.set(row[i]); err != nil {
+			return fmt.Errorf("udpapi DecodeRow: field %s: %w", f.name, err)
+		}
+	}
+	return nil
+}
+
+// DecodeFileRow decodes a FILE response row returned for the given
+// masks into the struct pointed to by v.
+//
+// Struct fields are mapped to row fields using anidb struct tags
+// containing the mask field names in [FileFmaskFields] and
+// [FileAmaskFields], or "fid" for the file ID:
+//
+//	type File struct {
+//		FID  int    `anidb:"fid"`
+//		Size int64  `anidb:"size"`
+//		Name string `anidb:"romaji name"`
+//	}
+//
+// Fields for names not selected by the masks are left unchanged.
+// See [DecodeRow] for the supported field types and unescaping.
+func DecodeFileRow(fmask FileFmask, amask FileAmask, row []string, v any) error {
+	if err := decodeFileRow(fmask, amask, row, v); err != nil {
+		return fmt.Errorf("udpapi DecodeFileRow: %w", err)
+	}
+	return nil
+}
+
+func decodeFileRow(fmask FileFmask, amask FileAmask, row []string, v any) error {
+	specs, err := maskSpecs(fmask[:], FileFmaskFields)
+	if err != nil {
+		return err
+	}
+	aspecs, err := maskSpecs(amask[:], FileAmaskFields)
+	if err != nil {
+		return err
+	}
+	names := []string{"fid"}
+	for _, s := range append(specs, aspecs...) {
+		names = append(names, s.name)
+	}
+	return decodeNamedRow(names, row, v)
+}
+
+// DecodeAnimeRow decodes an ANIME response row returned for the
+// given mask into the struct pointed to by v.
+//
+// Struct fields are mapped to row fields using anidb struct tags
+// containing the mask field names in [AnimeAmaskFields].
+// Fields for names not selected by the mask are left unchanged.
+// See [DecodeRow] for the supported field types and unescaping.
+func DecodeAnimeRow(amask AnimeAmask, row []string, v any) error {
+	if err := decodeAnimeRow(amask, row, v); err != nil {
+		return fmt.Errorf("udpapi DecodeAnimeRow: %w", err)
+	}
+	return nil
+}
+
+func decodeAnimeRow(amask AnimeAmask, row []string, v any) error {
+	specs, err := maskSpecs(amask[:], AnimeAmaskFields)
+	if err != nil {
+		return err
+	}
+	names := make([]string, len(specs))
+	for i, s := range specs {
+		names[i] = s.name
+	}
+	return decodeNamedRow(names, row, v)
+}
+
+// decodeNamedRow decodes a row whose fields have the given names.
+func decodeNamedRow(names []string, row []string, v any) error {
+	if got, want := len(row), len(names); got != want {
+		return fmt.Errorf("got %d fields, want %d", got, want)
+	}
+	fields, err := rowFields(v)
+	if err != nil {
+		return err
+	}
+	idx := make(map[string]int, len(names))
+	for i, n := range names {
+		idx[n] = i
+	}
+	for _, f := range fields {
+		i, ok := idx[f.key]
+		if !ok {
+			continue
+		}
+		if err := f.set(row[i]); err != nil {
+			return fmt.Errorf("field %s: %w", f.name, err)
+		}
+	}
+	return nil
+}
+
+// A rowField is a struct field to decode a row field into.
+type rowField struct {
+	// name is the struct field name.
+	name string
+	// key is the tag value identifying the row field.
+	key   string
+	sep   string
+	value reflect.Value
+}
+
+func (f rowField) set(s string) error {
+	return setRowValue(f.value, s, f.sep)
+}
+
+// rowFields returns the tagged fields of the struct pointed to by v.
+func rowFields(v any) ([]rowField, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("got %T, want non-nil pointer to struct", v)
+	}
+	rv = rv.Elem()
+	t := rv.Type()
+	var fields []rowField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, ok := sf.Tag.Lookup("anidb")
+		if !ok || !sf.IsExported() {
+			continue
+		}
+		key, opt, _ := strings.Cut(tag, ",")
+		f := rowField{
+			name:  sf.Name,
+			key:   key,
+			sep:   "'",
+			value: rv.Field(i),
+		}
+		switch opt {
+		case "":
+		case "comma":
+			f.sep = ","
+		default:
+			return nil, fmt.Errorf("field %s: unknown tag option %q", sf.Name, opt)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+	textType     = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// setRowValue decodes a raw row field into v.
+// sep is the separator for lists.
+func setRowValue(v reflect.Value, s, sep string) error {
+	if v.Kind() == reflect.Slice && !isTextUnmarshaler(v) {
+		// Split before unescaping, as escaped apostrophes would
+		// otherwise be mistaken for separators.
+		if s == "" {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		parts := strings.Split(s, sep)
+		l := reflect.MakeSlice(v.Type(), len(parts), len(parts))
+		for i, p := range parts {
+			if err := setRowValue(l.Index(i), p, sep); err != nil {
+				return err
+			}
+		}
+		v.Set(l)
+		return nil
+	}
+	return setFieldValue(v, UnescapeField(s))
+}
+
+// setFieldValue decodes an unescaped row field into v.
+func setFieldValue(v reflect.Value, s string) error {
+	switch v.Type() {
+	case timeType:
+		t, err := parseUnixTime(s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	case durationType:
+		d, err := parseSeconds(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	if isTextUnmarshaler(v) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		v.SetBool(s == "1")
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if s == "" {
+			v.SetInt(0)
+			return nil
+		}
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if s == "" {
+			v.SetUint(0)
+			return nil
+		}
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		if s == "" {
+			v.SetFloat(0)
+			return nil
+		}
+		n, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+func isTextUnmarshaler(v reflect.Value) bool {
+	return v.CanAddr() && v.Addr().Type().Implements(textType)
+}
@@ -0,0 +1,205 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeRow(t *testing.T) {
+	t.Parallel()
+	type row struct {
+		ID      int           `anidb:"0"`
+		Name    string        `anidb:"1"`
+		Done    bool          `anidb:"2"`
+		Date    time.Time     `anidb:"3"`
+		Length  time.Duration `anidb:"4"`
+		Tags    []string      `anidb:"5"`
+		IDs     []int         `anidb:"6,comma"`
+		Quality Quality       `anidb:"7"`
+		Rating  float64       `anidb:"8"`
+		Count   uint          `anidb:"9"`
+		Ignored string
+	}
+	in := []string{"12", "foo|bar", "1", "1600000000", "90", "a'b", "1,2,3", "high", "8.5", ""}
+	var got row
+	if err := DecodeRow(in, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := row{
+		ID:      12,
+		Name:    "foo|bar",
+		Done:    true,
+		Date:    time.Unix(1600000000, 0).UTC(),
+		Length:  90 * time.Second,
+		Tags:    []string{"a", "b"},
+		IDs:     []int{1, 2, 3},
+		Quality: QualityHigh,
+		Rating:  8.5,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %#v; want %#v", got, want)
+	}
+}
+
+func TestDecodeRow_escaped(t *testing.T) {
+	t.Parallel()
+	type row struct {
+		Name   string   `anidb:"0"`
+		Titles []string `anidb:"1"`
+	}
+	in := []string{"Kino`s Journey<br />2017", "Kino`s Journey'Kino no Tabi"}
+	var got row
+	if err := DecodeRow(in, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := row{
+		Name:   "Kino's Journey\n2017",
+		Titles: []string{"Kino's Journey", "Kino no Tabi"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %#v; want %#v", got, want)
+	}
+}
+
+func TestDecodeRow_errors(t *testing.T) {
+	t.Parallel()
+	t.Run("out of range", func(t *testing.T) {
+		t.Parallel()
+		var v struct {
+			A int `anidb:"2"`
+		}
+		if err := DecodeRow([]string{"1"}, &v); err == nil {
+			t.Errorf("Expected error")
+		}
+	})
+	t.Run("bad int", func(t *testing.T) {
+		t.Parallel()
+		var v struct {
+			A int `anidb:"0"`
+		}
+		if err := DecodeRow([]string{"x"}, &v); err == nil {
+			t.Errorf("Expected error")
+		}
+	})
+	t.Run("not pointer", func(t *testing.T) {
+		t.Parallel()
+		var v struct {
+			A int `anidb:"0"`
+		}
+		if err := DecodeRow([]string{"1"}, v); err == nil {
+			t.Errorf("Expected error")
+		}
+	})
+	t.Run("unsupported type", func(t *testing.T) {
+		t.Parallel()
+		var v struct {
+			A map[string]int `anidb:"0"`
+		}
+		if err := DecodeRow([]string{"1"}, &v); err == nil {
+			t.Errorf("Expected error")
+		}
+	})
+}
+
+func TestDecodeFileRow(t *testing.T) {
+	t.Parallel()
+	var fmask FileFmask
+	fmask.Set("aid", "size", "ed2k")
+	var amask FileAmask
+	amask.Set("romaji name")
+	type file struct {
+		FID   int    `anidb:"fid"`
+		AID   int    `anidb:"aid"`
+		Size  int64  `anidb:"size"`
+		Name  string `anidb:"romaji name"`
+		Group string `anidb:"group name"`
+	}
+	got := file{Group: "unchanged"}
+	row := []string{"312498", "4688", "177747474", "70cd93fd3981cc80a8ee6a6f6d1e6ec8", "Shinseiki Evangelion"}
+	if err := DecodeFileRow(fmask, amask, row, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := file{
+		FID:   312498,
+		AID:   4688,
+		Size:  177747474,
+		Name:  "Shinseiki Evangelion",
+		Group: "unchanged",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %#v; want %#v", got, want)
+	}
+	if err := DecodeFileRow(fmask, amask, row[:2], &got); err == nil {
+		t.Errorf("Expected error for short row")
+	}
+}
+
+func TestDecodeAnimeRow(t *testing.T) {
+	t.Parallel()
+	var amask AnimeAmask
+	amask.Set("aid", "year", "romaji name", "synonym list", "is 18+ restricted")
+	type anime struct {
+		AID        int      `anidb:"aid"`
+		Year       string   `anidb:"year"`
+		Name       string   `anidb:"romaji name"`
+		Synonyms   []string `anidb:"synonym list"`
+		Restricted bool     `anidb:"is 18+ restricted"`
+	}
+	var got anime
+	row := []string{"22", "1995-1996", "Shinseiki Evangelion", "EVA'Eva", "0"}
+	if err := DecodeAnimeRow(amask, row, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := anime{
+		AID:      22,
+		Year:     "1995-1996",
+		Name:     "Shinseiki Evangelion",
+		Synonyms: []string{"EVA", "Eva"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %#v; want %#v", got, want)
+	}
+}
+
+func TestRowTags_coverMasks(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		desc   string
+		v      any
+		fields []map[string]bitSpec
+	}{
+		{desc: "FileInfo", v: FileInfo{}, fields: []map[string]bitSpec{FileFmaskFields, FileAmaskFields}},
+		{desc: "Anime", v: Anime{}, fields: []map[string]bitSpec{AnimeAmaskFields}},
+	}
+	for _, c := range cases {
+		tags := make(map[string]bool)
+		typ := reflect.TypeOf(c.v)
+		for i := 0; i < typ.NumField(); i++ {
+			tag, _, _ := strings.Cut(typ.Field(i).Tag.Get("anidb"), ",")
+			tags[tag] = true
+		}
+		for _, m := range c.fields {
+			for name := range m {
+				if !tags[name] {
+					t.Errorf("%s: no field for mask field %q", c.desc, name)
+				}
+			}
+		}
+	}
+}
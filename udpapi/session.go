@@ -0,0 +1,78 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"context"
+	"crypto/aes"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// A SessionState is the exported state of a logged in session, for
+// resuming the session in another process.
+// See [Client.ExportSession] and [Client.ResumeSession].
+//
+// A SessionState contains secrets and should be stored securely.
+type SessionState struct {
+	Key string `json:"key"`
+	// EncryptionKey is the key for ENCRYPT, if used.
+	EncryptionKey []byte `json:"encryption_key,omitempty"`
+	// LastRequest is the time of the last request in the session.
+	LastRequest time.Time `json:"last_request"`
+}
+
+// ExportSession returns the state of the current session.
+func (c *Client) ExportSession() (SessionState, error) {
+	key := c.sessionKey.get()
+	if key == "" {
+		return SessionState{}, errors.New("udpapi ExportSession: no session")
+	}
+	return SessionState{
+		Key:           key,
+		EncryptionKey: c.encryptKey.get(),
+		LastRequest:   c.lastRequest.get(),
+	}, nil
+}
+
+// ResumeSession resumes an exported session, instead of calling AUTH.
+// The session is validated with UPTIME before returning.
+// If the session is not valid, the client is left logged out.
+//
+// Re-authenticating the resumed session, such as for
+// [Client.AutoReauth], requires [Client.Credentials].
+func (c *Client) ResumeSession(ctx context.Context, s SessionState) error {
+	if s.Key == "" {
+		return errors.New("udpapi ResumeSession: empty session key")
+	}
+	if s.EncryptionKey != nil {
+		b, err := aes.NewCipher(s.EncryptionKey)
+		if err != nil {
+			return fmt.Errorf("udpapi ResumeSession: %w", err)
+		}
+		c.m.SetBlock(b)
+		c.encryptKey.set(s.EncryptionKey)
+	}
+	c.sessionKey.set(s.Key)
+	c.lastRequest.set(s.LastRequest)
+	if _, err := c.Uptime(ctx); err != nil {
+		c.m.SetBlock(nil)
+		c.encryptKey.set(nil)
+		c.sessionKey.set("")
+		return fmt.Errorf("udpapi ResumeSession: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestClient_ResumeSession(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		switch cmd {
+		case "AUTH":
+			return "200 key 1234 LOGIN ACCEPTED"
+		case "UPTIME":
+			if args.Get("s") != "key" {
+				return "506 INVALID SESSION"
+			}
+			return "208 UPTIME\n1000"
+		default:
+			return "598 UNKNOWN COMMAND"
+		}
+	})
+	if _, err := s.client.Auth(ctx, UserInfo{UserName: "ionasal", UserPassword: "pass"}); err != nil {
+		t.Fatal(err)
+	}
+	st, err := s.client.ExportSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s2 := newFakeServer(t, s.handler)
+	if err := s2.client.ResumeSession(ctx, st); err != nil {
+		t.Fatal(err)
+	}
+	if got := s2.client.sessionKey.get(); got != "key" {
+		t.Errorf("Got session key %q; want %q", got, "key")
+	}
+}
+
+func TestClient_ResumeSession_invalid(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "506 INVALID SESSION"
+	})
+	if err := s.client.ResumeSession(ctx, SessionState{Key: "old"}); err == nil {
+		t.Errorf("Expected error")
+	}
+	if got := s.client.sessionKey.get(); got != "" {
+		t.Errorf("Got session key %q; want none", got)
+	}
+}
+
+func TestClient_ExportSession_noSession(t *testing.T) {
+	t.Parallel()
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return ""
+	})
+	if _, err := s.client.ExportSession(); err == nil {
+		t.Errorf("Expected error")
+	}
+}
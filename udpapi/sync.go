@@ -33,3 +33,9 @@ func (s *syncVar[T]) set(v T) {
 	s.val = v
 	s.mu.Unlock()
 }
+
+func (s *syncVar[T]) update(f func(*T)) {
+	s.mu.Lock()
+	f(&s.val)
+	s.mu.Unlock()
+}
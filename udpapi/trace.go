@@ -0,0 +1,84 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"context"
+	"time"
+
+	"go.felesatra.moe/anidb/udpapi/codes"
+)
+
+// A Trace holds hooks that are called for each request, for example
+// to collect metrics or debug rate limiting.
+// The hooks are called synchronously from the requesting goroutine,
+// possibly concurrently, so they should be fast and concurrency safe.
+// Nil hooks are skipped.
+type Trace struct {
+	// OnRequest is called before a request is sent.
+	OnRequest func(RequestInfo)
+	// OnResponse is called after a request completes or fails.
+	OnResponse func(ResponseInfo)
+}
+
+// A RequestInfo describes a request for a [Trace].
+// Request arguments are not included, as they may contain
+// credentials.
+type RequestInfo struct {
+	Cmd string
+	Tag string
+	// Attempt is the attempt number of the request, starting at 1.
+	// This is greater than 1 for retries by a [Client].
+	Attempt int
+}
+
+// A ResponseInfo describes the outcome of a request for a [Trace].
+type ResponseInfo struct {
+	RequestInfo
+	// Code is the return code, if a response was received.
+	Code codes.ReturnCode
+	// Duration is the time spent waiting for the response.
+	// This does not include rate limiting.
+	Duration time.Duration
+	Err      error
+}
+
+// SetTrace sets the hooks called for each request.
+// Set to nil to disable.
+func (m *Mux) SetTrace(t *Trace) {
+	m.trace.set(t)
+}
+
+// SetTrace sets the hooks called for each request.
+// See [Mux.SetTrace].
+func (c *Client) SetTrace(t *Trace) {
+	c.m.SetTrace(t)
+}
+
+type attemptKey struct{}
+
+// withAttempt returns a context carrying the attempt number of a
+// request for tracing.
+func withAttempt(ctx context.Context, n int) context.Context {
+	return context.WithValue(ctx, attemptKey{}, n)
+}
+
+// attemptFromContext returns the attempt number of a request.
+func attemptFromContext(ctx context.Context) int {
+	if n, ok := ctx.Value(attemptKey{}).(int); ok {
+		return n
+	}
+	return 1
+}
@@ -0,0 +1,80 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"go.felesatra.moe/anidb/udpapi/codes"
+)
+
+func TestClient_SetTrace(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	var n int
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		n++
+		if n < 2 {
+			return "602 SERVER BUSY"
+		}
+		return "300 PONG"
+	})
+	c := s.client
+	c.RetryPolicy = RetryPolicy{
+		MaxAttempts: 2,
+		Base:        time.Millisecond,
+		Codes:       []codes.ReturnCode{codes.SERVER_BUSY},
+	}
+	var (
+		mu        sync.Mutex
+		requests  []RequestInfo
+		responses []ResponseInfo
+	)
+	c.SetTrace(&Trace{
+		OnRequest: func(r RequestInfo) {
+			mu.Lock()
+			defer mu.Unlock()
+			requests = append(requests, r)
+		},
+		OnResponse: func(r ResponseInfo) {
+			mu.Lock()
+			defer mu.Unlock()
+			responses = append(responses, r)
+		},
+	})
+	if err := c.PingSimple(ctx); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(requests) != 2 || len(responses) != 2 {
+		t.Fatalf("Got %d requests and %d responses; want 2 each", len(requests), len(responses))
+	}
+	reqs := s.requests()
+	for i, r := range requests {
+		if r.Cmd != "PING" || r.Attempt != i+1 || r.Tag != reqs[i].args.Get("tag") {
+			t.Errorf("Got request %#v", r)
+		}
+	}
+	if r := responses[0]; r.Code != codes.SERVER_BUSY || r.Attempt != 1 || r.Err != nil {
+		t.Errorf("Got response %#v", r)
+	}
+	if r := responses[1]; r.Code != codes.PONG || r.Attempt != 2 || r.Duration <= 0 {
+		t.Errorf("Got response %#v", r)
+	}
+}
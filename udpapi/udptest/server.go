@@ -0,0 +1,356 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package udptest provides a fake AniDB UDP API server for testing.
+//
+// The server implements basic semantics for AUTH, LOGOUT, PING,
+// FILE, ANIME and MYLISTADD, and canned responses can be set for any
+// command.
+// Encryption and compression are not supported.
+package udptest
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A Server is a fake AniDB UDP API server listening on a local UDP
+// socket.
+// The methods can be called concurrently.
+type Server struct {
+	// Addr is the address of the server, for udpapi.Dial.
+	Addr string
+
+	pc net.PacketConn
+	wg sync.WaitGroup
+
+	mu          sync.Mutex
+	handlers    map[string]Handler
+	users       map[string]string
+	sessions    map[string]string
+	files       []File
+	anime       map[int][]string
+	mylist      map[int]int
+	nextLID     int
+	latency     time.Duration
+	drop        func(Request) bool
+	minInterval time.Duration
+	lastRequest time.Time
+	requests    []Request
+	violations  []Request
+}
+
+// A Request is a request received by a [Server].
+type Request struct {
+	Cmd string
+	// Args has the UDP API escaping of values undone.
+	Args url.Values
+	// Time is when the request was received.
+	Time time.Time
+}
+
+// A Handler returns the response for a request, without the tag,
+// e.g., "300 PONG".
+// If the response is empty, no response is sent.
+type Handler func(Request) string
+
+// A File is a file known to a [Server].
+type File struct {
+	FID  int
+	Size int64
+	ED2K string
+	// Fields are the fields returned after the fid for FILE
+	// requests.
+	// These should match the masks used by the client under test.
+	Fields []string
+}
+
+// NewServer starts a new Server on a loopback address.
+// You must call Close after use.
+func NewServer() (*Server, error) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("udptest NewServer: %w", err)
+	}
+	s := &Server{
+		Addr:     pc.LocalAddr().String(),
+		pc:       pc,
+		handlers: make(map[string]Handler),
+		users:    make(map[string]string),
+		sessions: make(map[string]string),
+		anime:    make(map[int][]string),
+		mylist:   make(map[int]int),
+		nextLID:  1,
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.serve()
+	}()
+	return s, nil
+}
+
+// Close stops the server.
+func (s *Server) Close() error {
+	err := s.pc.Close()
+	s.wg.Wait()
+	return err
+}
+
+// Handle sets a handler for a command, replacing the built-in
+// behavior.
+func (s *Server) Handle(cmd string, h Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[cmd] = h
+}
+
+// HandleResponse sets a canned response for a command, replacing the
+// built-in behavior.
+func (s *Server) HandleResponse(cmd, resp string) {
+	s.Handle(cmd, func(Request) string { return resp })
+}
+
+// AddUser adds a user that can log in with AUTH.
+func (s *Server) AddUser(name, password string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[name] = password
+}
+
+// AddFile adds a file for FILE and MYLISTADD.
+func (s *Server) AddFile(f File) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files = append(s.files, f)
+}
+
+// AddAnime adds an anime for ANIME by aid.
+// The fields are the returned row, which should match the amask used
+// by the client under test.
+func (s *Server) AddAnime(aid int, fields ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.anime[aid] = fields
+}
+
+// SetLatency sets a delay before each response is sent.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// SetDrop sets a function that decides whether to drop a request
+// without responding, to simulate packet loss.
+// Set to nil to not drop requests.
+func (s *Server) SetDrop(f func(Request) bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.drop = f
+}
+
+// DropRate returns a function for [Server.SetDrop] that drops the
+// given fraction of requests at random.
+func DropRate(p float64) func(Request) bool {
+	return func(Request) bool {
+		return rand.Float64() < p
+	}
+}
+
+// SetMinInterval sets the minimum interval between requests.
+// Requests sent sooner are recorded as violations, which can be
+// checked with [Server.Violations].
+// The requests are still handled normally.
+func (s *Server) SetMinInterval(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.minInterval = d
+}
+
+// Requests returns the requests received so far.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Violations returns the requests that violated the minimum
+// interval set with [Server.SetMinInterval].
+func (s *Server) Violations() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.violations...)
+}
+
+func (s *Server) serve() {
+	buf := make([]byte, 1400)
+	for {
+		n, addr, err := s.pc.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		cmd, rest, _ := strings.Cut(string(buf[:n]), " ")
+		args, err := url.ParseQuery(rest)
+		if err != nil {
+			continue
+		}
+		for _, vs := range args {
+			for i, v := range vs {
+				vs[i] = unescapeValue(v)
+			}
+		}
+		r := Request{Cmd: cmd, Args: args, Time: time.Now()}
+		resp, latency := s.handle(r, addr)
+		if resp == "" {
+			continue
+		}
+		data := []byte(fmt.Sprintf("%s %s", args.Get("tag"), resp))
+		if latency > 0 {
+			time.AfterFunc(latency, func() {
+				_, _ = s.pc.WriteTo(data, addr)
+			})
+			continue
+		}
+		_, _ = s.pc.WriteTo(data, addr)
+	}
+}
+
+// handle records a request and returns its response and latency.
+func (s *Server) handle(r Request, addr net.Addr) (string, time.Duration) {
+	s.mu.Lock()
+	s.requests = append(s.requests, r)
+	if !s.lastRequest.IsZero() && r.Time.Sub(s.lastRequest) < s.minInterval {
+		s.violations = append(s.violations, r)
+	}
+	s.lastRequest = r.Time
+	drop, h, latency := s.drop, s.handlers[r.Cmd], s.latency
+	s.mu.Unlock()
+	// Call user functions without holding the lock.
+	if drop != nil && drop(r) {
+		return "", 0
+	}
+	if h != nil {
+		return h(r), latency
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.builtin(r, addr), latency
+}
+
+// builtin returns the built-in response for a request.
+// s.mu must be held.
+func (s *Server) builtin(r Request, addr net.Addr) string {
+	switch r.Cmd {
+	case "PING":
+		if r.Args.Get("nat") == "1" {
+			_, port, _ := net.SplitHostPort(addr.String())
+			return "300 PONG\n" + port
+		}
+		return "300 PONG"
+	case "AUTH":
+		pass, ok := s.users[r.Args.Get("user")]
+		if !ok || pass != r.Args.Get("pass") {
+			return "500 LOGIN FAILED"
+		}
+		key := fmt.Sprintf("s%d", len(s.requests))
+		s.sessions[key] = r.Args.Get("user")
+		return fmt.Sprintf("200 %s %s LOGIN ACCEPTED", key, addr)
+	case "LOGOUT":
+		if _, ok := s.sessions[r.Args.Get("s")]; !ok {
+			return "403 NOT LOGGED IN"
+		}
+		delete(s.sessions, r.Args.Get("s"))
+		return "203 LOGGED OUT"
+	case "FILE", "ANIME", "MYLISTADD":
+	default:
+		return "598 UNKNOWN COMMAND"
+	}
+	// Commands requiring a session.
+	key := r.Args.Get("s")
+	if key == "" {
+		return "501 LOGIN FIRST"
+	}
+	if _, ok := s.sessions[key]; !ok {
+		return "506 INVALID SESSION"
+	}
+	switch r.Cmd {
+	case "FILE":
+		f, ok := s.findFile(r.Args)
+		if !ok {
+			return "320 NO SUCH FILE"
+		}
+		return "220 FILE\n" + strings.Join(append([]string{strconv.Itoa(f.FID)}, f.Fields...), "|")
+	case "ANIME":
+		aid, _ := strconv.Atoi(r.Args.Get("aid"))
+		row, ok := s.anime[aid]
+		if !ok {
+			return "330 NO SUCH ANIME"
+		}
+		return "230 ANIME\n" + strings.Join(row, "|")
+	case "MYLISTADD":
+		return s.mylistAdd(r.Args)
+	default:
+		panic(r.Cmd)
+	}
+}
+
+// mylistAdd handles MYLISTADD by fid or size+ed2k.
+// s.mu must be held.
+func (s *Server) mylistAdd(args url.Values) string {
+	if args.Get("edit") == "1" {
+		return "311 MYLIST ENTRY EDITED\n1"
+	}
+	f, ok := s.findFile(args)
+	if !ok {
+		return "320 NO SUCH FILE"
+	}
+	if lid, ok := s.mylist[f.FID]; ok {
+		return fmt.Sprintf("310 FILE ALREADY IN MYLIST\n%d|%d|0|0|0|0|0|0||||0", lid, f.FID)
+	}
+	lid := s.nextLID
+	s.nextLID++
+	s.mylist[f.FID] = lid
+	return fmt.Sprintf("210 MYLIST ENTRY ADDED\n%d", lid)
+}
+
+// findFile finds a file by fid or size+ed2k.
+// s.mu must be held.
+func (s *Server) findFile(args url.Values) (File, bool) {
+	for _, f := range s.files {
+		if args.Has("fid") {
+			if strconv.Itoa(f.FID) == args.Get("fid") {
+				return f, true
+			}
+			continue
+		}
+		if strconv.FormatInt(f.Size, 10) == args.Get("size") && strings.EqualFold(f.ED2K, args.Get("ed2k")) {
+			return f, true
+		}
+	}
+	return File{}, false
+}
+
+// unescapeValue undoes the UDP API escaping of request values.
+func unescapeValue(s string) string {
+	s = strings.ReplaceAll(s, "<br />", "\n")
+	s = strings.ReplaceAll(s, "&amp;", "&")
+	return s
+}
@@ -0,0 +1,193 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udptest
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"go.felesatra.moe/anidb/udpapi"
+	"go.felesatra.moe/anidb/udpapi/codes"
+)
+
+// nopLimiter does not limit requests, to keep tests fast.
+type nopLimiter struct{}
+
+func (nopLimiter) Wait(context.Context) error { return nil }
+
+func newTestClient(t *testing.T, s *Server) *udpapi.Client {
+	t.Helper()
+	c, err := udpapi.Dial(s.Addr, slog.New(slog.NewTextHandler(discard{}, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	c.SetLimiter(nopLimiter{})
+	return c
+}
+
+type discard struct{}
+
+func (discard) Write(p []byte) (int, error) { return len(p), nil }
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	s, err := NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func testContext(t *testing.T) context.Context {
+	ctx, cf := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cf)
+	return ctx
+}
+
+func TestServer_session(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t)
+	s := newTestServer(t)
+	s.AddUser("ionasal", "pass")
+	s.AddFile(File{FID: 312498, Size: 1000, ED2K: "abcdef", Fields: []string{"8076"}})
+	c := newTestClient(t, s)
+
+	if _, err := c.Auth(ctx, udpapi.UserInfo{UserName: "ionasal", UserPassword: "wrong"}); !errors.Is(err, codes.LOGIN_FAILED) {
+		t.Errorf("Got error %v; want %v", err, codes.LOGIN_FAILED)
+	}
+	if _, err := c.Auth(ctx, udpapi.UserInfo{UserName: "ionasal", UserPassword: "pass"}); err != nil {
+		t.Fatal(err)
+	}
+	var fm udpapi.FileFmask
+	fm.Set("aid")
+	fi, err := c.FileInfoByHash(ctx, 1000, "abcdef", fm, udpapi.FileAmask{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.FID != 312498 || fi.AID != 8076 {
+		t.Errorf("Got %#v", fi)
+	}
+	r, err := c.MylistAdd(ctx, 312498, udpapi.MylistAddOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.AlreadyAdded {
+		t.Errorf("Got AlreadyAdded for new entry")
+	}
+	r2, err := c.MylistAdd(ctx, 312498, udpapi.MylistAddOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r2.AlreadyAdded || r2.LID != r.LID {
+		t.Errorf("Got %#v; want existing entry %d", r2, r.LID)
+	}
+	if _, err := c.MylistAdd(ctx, 1, udpapi.MylistAddOptions{}); !errors.Is(err, codes.NO_SUCH_FILE) {
+		t.Errorf("Got error %v; want %v", err, codes.NO_SUCH_FILE)
+	}
+	if err := c.Logout(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServer_escaping(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t)
+	s := newTestServer(t)
+	s.AddUser("ionasal", "p&ss")
+	s.AddFile(File{FID: 312498, Size: 1000, ED2K: "abcdef"})
+	c := newTestClient(t, s)
+
+	if _, err := c.Auth(ctx, udpapi.UserInfo{UserName: "ionasal", UserPassword: "p&ss"}); err != nil {
+		t.Fatal(err)
+	}
+	const other = "line 1\nline 2 & more"
+	if _, err := c.MylistAdd(ctx, 312498, udpapi.MylistAddOptions{Other: other}); err != nil {
+		t.Fatal(err)
+	}
+	reqs := s.Requests()
+	if got := reqs[len(reqs)-1].Args.Get("other"); got != other {
+		t.Errorf("Got other %q; want %q", got, other)
+	}
+}
+
+func TestServer_invalidSession(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t)
+	s := newTestServer(t)
+	// Bypass client side session checks.
+	s.HandleResponse("AUTH", "200 bogus 127.0.0.1:1 LOGIN ACCEPTED")
+	c := newTestClient(t, s)
+	if _, err := c.Auth(ctx, udpapi.UserInfo{UserName: "ionasal"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Uptime(ctx); !errors.Is(err, codes.UNKNOWN_COMMAND) {
+		t.Errorf("Got error %v; want %v", err, codes.UNKNOWN_COMMAND)
+	}
+	if _, err := c.AnimeByAID(ctx, 1, udpapi.AnimeAmask{}); !errors.Is(err, codes.INVALID_SESSION) {
+		t.Errorf("Got error %v; want %v", err, codes.INVALID_SESSION)
+	}
+}
+
+func TestServer_drop(t *testing.T) {
+	t.Parallel()
+	ctx, cf := context.WithTimeout(testContext(t), 100*time.Millisecond)
+	defer cf()
+	s := newTestServer(t)
+	s.SetDrop(DropRate(1))
+	c := newTestClient(t, s)
+	if err := c.PingSimple(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Got error %v; want %v", err, context.DeadlineExceeded)
+	}
+	if n := len(s.Requests()); n != 1 {
+		t.Errorf("Got %d requests; want 1", n)
+	}
+}
+
+func TestServer_latency(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t)
+	s := newTestServer(t)
+	const latency = 50 * time.Millisecond
+	s.SetLatency(latency)
+	c := newTestClient(t, s)
+	start := time.Now()
+	if _, err := c.Ping(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d < latency {
+		t.Errorf("Got response after %s; want at least %s", d, latency)
+	}
+}
+
+func TestServer_minInterval(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t)
+	s := newTestServer(t)
+	s.SetMinInterval(time.Hour)
+	c := newTestClient(t, s)
+	for i := 0; i < 2; i++ {
+		if err := c.PingSimple(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := len(s.Violations()); n != 1 {
+		t.Errorf("Got %d violations; want 1", n)
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"go.felesatra.moe/anidb/udpapi/codes"
+)
+
+// A User is an AniDB user, as returned by the USER command.
+type User struct {
+	UID  int
+	Name string
+}
+
+// User calls the USER command to look up a user by name.
+// The returned error wraps a [codes.ReturnCode] if applicable,
+// such as NO_SUCH_USER.
+func (c *Client) User(ctx context.Context, name string) (User, error) {
+	v, err := c.sessionValues(ctx)
+	if err != nil {
+		return User{}, fmt.Errorf("udpapi User: %w", err)
+	}
+	v.Set("user", name)
+	resp, err := c.request(ctx, "USER", v)
+	if err != nil {
+		return User{}, fmt.Errorf("udpapi User: %w", err)
+	}
+	if resp.Code != codes.USER_ID {
+		return User{}, fmt.Errorf("udpapi User: %w", codeError("USER", v, resp))
+	}
+	if len(resp.Rows) != 1 || len(resp.Rows[0]) != 2 {
+		return User{}, fmt.Errorf("udpapi User: unexpected response rows %q", resp.Rows)
+	}
+	uid, err := strconv.Atoi(resp.Rows[0][0])
+	if err != nil {
+		return User{}, fmt.Errorf("udpapi User: %s", err)
+	}
+	return User{UID: uid, Name: resp.Rows[0][1]}, nil
+}
@@ -0,0 +1,55 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"go.felesatra.moe/anidb/udpapi/codes"
+)
+
+func TestClient_User(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "295 USER\n1234|ionasal"
+	})
+	s.client.sessionKey.set("key")
+	got, err := s.client.User(ctx, "ionasal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (User{UID: 1234, Name: "ionasal"}); got != want {
+		t.Errorf("Got %v; want %v", got, want)
+	}
+	if got := s.requests()[0].args.Get("user"); got != "ionasal" {
+		t.Errorf("Got user %q; want %q", got, "ionasal")
+	}
+}
+
+func TestClient_User_noSuchUser(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "394 NO SUCH USER"
+	})
+	s.client.sessionKey.set("key")
+	if _, err := s.client.User(ctx, "nobody"); !errors.Is(err, codes.NO_SUCH_USER) {
+		t.Errorf("Got error %v; want %v", err, codes.NO_SUCH_USER)
+	}
+}
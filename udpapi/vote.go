@@ -0,0 +1,150 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+
+	"go.felesatra.moe/anidb/udpapi/codes"
+)
+
+// A Vote is a vote value in hundredths, from 100 (1.00) to 1000
+// (10.00).
+type Vote int
+
+// VoteFromFloat returns the Vote for a value from 1 to 10.
+func VoteFromFloat(f float64) Vote {
+	return Vote(math.Round(f * 100))
+}
+
+// Float returns the vote as a value from 1 to 10.
+func (v Vote) Float() float64 {
+	return float64(v) / 100
+}
+
+// Valid returns true if the vote is in the valid range.
+func (v Vote) Valid() bool {
+	return v >= 100 && v <= 1000
+}
+
+func (v Vote) String() string {
+	return strconv.FormatFloat(v.Float(), 'f', 2, 64)
+}
+
+// A VoteType is the type of entity voted on.
+type VoteType int
+
+const (
+	VoteTypeAnime     VoteType = 1
+	VoteTypeAnimeTemp VoteType = 2
+	VoteTypeGroup     VoteType = 3
+	VoteTypeEpisode   VoteType = 6
+)
+
+// A VoteResult is the result of a VOTE command.
+type VoteResult struct {
+	// Code is one of VOTED, VOTE_FOUND, VOTE_UPDATED or VOTE_REVOKED.
+	Code codes.ReturnCode
+	// Name is the name of the entity voted on.
+	Name string
+	// Value is the vote value.
+	// For VOTE_UPDATED, this is the old value.
+	// For VOTE_REVOKED, this is the revoked value.
+	Value Vote
+	Type  VoteType
+	ID    int
+}
+
+// Vote calls the VOTE command.
+// If value is zero, the existing vote is returned.
+// If value is -1, the existing vote is revoked.
+// The returned error wraps a [codes.ReturnCode] if applicable,
+// such as NO_SUCH_VOTE or INVALID_VOTE_VALUE.
+func (c *Client) Vote(ctx context.Context, t VoteType, id int, value Vote) (VoteResult, error) {
+	v, err := c.sessionValues(ctx)
+	if err != nil {
+		return VoteResult{}, fmt.Errorf("udpapi Vote: %w", err)
+	}
+	v.Set("type", strconv.Itoa(int(t)))
+	v.Set("id", strconv.Itoa(id))
+	v.Set("value", strconv.Itoa(int(value)))
+	resp, err := c.request(ctx, "VOTE", v)
+	if err != nil {
+		return VoteResult{}, fmt.Errorf("udpapi Vote: %w", err)
+	}
+	switch resp.Code {
+	case codes.VOTED, codes.VOTE_FOUND, codes.VOTE_UPDATED, codes.VOTE_REVOKED:
+	default:
+		return VoteResult{}, fmt.Errorf("udpapi Vote: %w", codeError("VOTE", v, resp))
+	}
+	if len(resp.Rows) != 1 {
+		return VoteResult{}, fmt.Errorf("udpapi Vote: got unexpected number of rows %d", len(resp.Rows))
+	}
+	r, err := decodeVoteResult(resp.Rows[0])
+	if err != nil {
+		return VoteResult{}, fmt.Errorf("udpapi Vote: %w", err)
+	}
+	r.Code = resp.Code
+	return r, nil
+}
+
+// VoteAnime votes on an anime.
+// See [Client.Vote].
+func (c *Client) VoteAnime(ctx context.Context, aid int, value Vote) (VoteResult, error) {
+	return c.Vote(ctx, VoteTypeAnime, aid, value)
+}
+
+// VoteEpisode votes on an episode.
+// See [Client.Vote].
+func (c *Client) VoteEpisode(ctx context.Context, eid int, value Vote) (VoteResult, error) {
+	return c.Vote(ctx, VoteTypeEpisode, eid, value)
+}
+
+// RevokeVote revokes a vote.
+// See [Client.Vote].
+func (c *Client) RevokeVote(ctx context.Context, t VoteType, id int) (VoteResult, error) {
+	return c.Vote(ctx, t, id, -1)
+}
+
+// decodeVoteResult decodes a VOTE response row.
+// The Code is not set.
+func decodeVoteResult(row []string) (VoteResult, error) {
+	var r VoteResult
+	if n := len(row); n != 4 {
+		return r, fmt.Errorf("decode vote: got unexpected number of fields %d", n)
+	}
+	r.Name = row[0]
+	var value, typ int
+	for _, f := range []struct {
+		p *int
+		s string
+	}{
+		{&value, row[1]},
+		{&typ, row[2]},
+		{&r.ID, row[3]},
+	} {
+		var err error
+		*f.p, err = strconv.Atoi(f.s)
+		if err != nil {
+			return r, fmt.Errorf("decode vote: %s", err)
+		}
+	}
+	r.Value = Vote(value)
+	r.Type = VoteType(typ)
+	return r, nil
+}
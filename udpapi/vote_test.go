@@ -0,0 +1,87 @@
+// Copyright (C) 2026 Allen Li
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udpapi
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"go.felesatra.moe/anidb/udpapi/codes"
+)
+
+func TestVote(t *testing.T) {
+	t.Parallel()
+	v := VoteFromFloat(8.5)
+	if v != 850 {
+		t.Errorf("Got %d; want 850", v)
+	}
+	if got := v.Float(); got != 8.5 {
+		t.Errorf("Got %v; want 8.5", got)
+	}
+	if got := v.String(); got != "8.50" {
+		t.Errorf("Got %q; want %q", got, "8.50")
+	}
+	if !v.Valid() {
+		t.Errorf("Got %v invalid", v)
+	}
+	if Vote(50).Valid() || Vote(1001).Valid() {
+		t.Errorf("Got out of range vote valid")
+	}
+}
+
+func TestClient_VoteAnime(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "260 VOTED\nSeitokai Yakuindomo|850|1|8076"
+	})
+	s.client.sessionKey.set("key")
+	got, err := s.client.VoteAnime(ctx, 8076, VoteFromFloat(8.5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := VoteResult{
+		Code:  codes.VOTED,
+		Name:  "Seitokai Yakuindomo",
+		Value: 850,
+		Type:  VoteTypeAnime,
+		ID:    8076,
+	}
+	if got != want {
+		t.Errorf("Got %#v; want %#v", got, want)
+	}
+	args := s.requests()[0].args
+	if args.Get("type") != "1" || args.Get("id") != "8076" || args.Get("value") != "850" {
+		t.Errorf("Got args %v", args)
+	}
+}
+
+func TestClient_RevokeVote_noVote(t *testing.T) {
+	t.Parallel()
+	ctx := testContext(t, time.Second)
+	s := newFakeServer(t, func(cmd string, args url.Values) string {
+		return "360 NO SUCH VOTE"
+	})
+	s.client.sessionKey.set("key")
+	_, err := s.client.RevokeVote(ctx, VoteTypeEpisode, 213341)
+	if !errors.Is(err, codes.NO_SUCH_VOTE) {
+		t.Errorf("Got error %v; want %v", err, codes.NO_SUCH_VOTE)
+	}
+	if got := s.requests()[0].args.Get("value"); got != "-1" {
+		t.Errorf("Got value %q; want -1", got)
+	}
+}